@@ -1,17 +1,31 @@
 package main
 
 import (
+	"crypto/rand"
 	"encoding/json"
+	"net/url"
+	"strings"
 
 	"github.com/Alwanly/go-codebase/config"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
 	"github.com/Alwanly/go-codebase/pkg/database"
 	"github.com/Alwanly/go-codebase/pkg/deps"
+	"github.com/Alwanly/go-codebase/pkg/errorreport"
+	"github.com/Alwanly/go-codebase/pkg/featureflag"
+	"github.com/Alwanly/go-codebase/pkg/httpclient"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/maintenance"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
 	"github.com/Alwanly/go-codebase/pkg/middleware"
 	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+	"github.com/Alwanly/go-codebase/pkg/tracing"
 	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/swagger"
 	"go.uber.org/zap"
 
@@ -22,11 +36,15 @@ import (
 
 type (
 	AppDeps struct {
-		Config *config.GlobalConfig
-		Logger *zap.Logger
-		DB     *database.DBService
-		Redis  *redis.Service
-		Auth   *middleware.AuthMiddleware
+		Config  *config.GlobalConfig
+		Logger  *zap.Logger
+		DB      *database.DBService
+		Redis   *redis.Service
+		Auth    *middleware.AuthMiddleware
+		Metrics *metrics.Service
+		Tracing *tracing.Service
+		Errors  errorreport.ErrorReporter
+		Storage storage.IStorage
 	}
 )
 
@@ -42,7 +60,7 @@ var inst *deps.App
 // @securityDefinitions.apiKey Bearer
 // @in header
 // @name Authorization
-func Bootstrap(d *AppDeps) *deps.App {
+func Bootstrap(d *AppDeps) (*deps.App, error) {
 	// create http server
 	e := fiber.New(fiber.Config{
 		DisableStartupMessage: true,
@@ -52,11 +70,72 @@ func Bootstrap(d *AppDeps) *deps.App {
 	})
 
 	// register middleware
-	e.Use(cors.New())
-	e.Use(recover.New())
+	security, err := middleware.Security(middleware.SecurityOpts{
+		Policy: d.Config.SecurityPolicy(),
+		Routes: []middleware.RouteSecurity{
+			// swagger UI needs inline scripts and styles
+			{Prefix: "/swagger", Override: func(p *middleware.SecurityPolicy) {
+				p.Headers.ContentSecurityPolicy = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:"
+				p.Headers.FrameOptions = "SAMEORIGIN"
+			}},
+			// metrics are scraped server side only
+			{Prefix: "/metrics", Override: func(p *middleware.SecurityPolicy) {
+				p.CORS.AllowOrigins = nil
+			}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	panicRecovery := middleware.PanicRecoveryOpts{Logger: d.Logger, Reporter: d.Errors}
+	// outermost, fasthttp does not recover a panic of the middlewares registered before the inner one
+	e.Use(middleware.PanicRecovery(panicRecovery))
+	e.Use(security)
+	e.Use(middleware.RequestID())
+	e.Use(i18n.Middleware())
+	e.Use(audit.Middleware())
+	e.Use(d.Tracing.Middleware())
+	e.Use(d.Metrics.Middleware())
+	// inside tracing and metrics, so a recovered panic is traced and counted as a 500
+	e.Use(middleware.PanicRecovery(panicRecovery))
+	e.Use(middleware.Timeout(middleware.TimeoutOpts{Timeout: d.Config.RequestTimeout}))
+
+	// guard postgres and redis with circuit breakers and bulkheads
+	dbGuard := dependencyGuard(d, "postgres", d.Config.PostgresMaxConcurrentQueries, database.IsFailure)
+	redisGuard := dependencyGuard(d, "redis", d.Config.RedisMaxConcurrentCommands, redis.IsFailure)
+	var db database.IDBService = d.DB
+	if resilientDB, err := database.NewResilientDB(d.DB, dbGuard); err != nil {
+		d.Logger.Error("Cannot guard database", zap.Error(err))
+	} else {
+		db = resilientDB
+	}
+	rdb := redis.NewResilientRedis(d.Redis, redisGuard)
+
+	// maintenance and read-only switch
+	maintenanceSwitch := maintenance.NewMaintenance(&maintenance.Opts{
+		Logger: d.Logger,
+		Redis:  rdb,
+	})
+	e.Use(maintenanceSwitch.Middleware(maintenance.MiddlewareOpts{
+		SkipPaths:     []string{"/health", "/ready", "/live", "/metrics", "/swagger", "/admin/maintenance"},
+		BypassAPIKeys: d.Config.MaintenanceBypassAPIKeys,
+		BypassRoles:   d.Config.MaintenanceBypassRoles,
+		Identify:      d.Auth.UserFromRequest,
+	}))
 
 	// create validator
-	v, _ := validator.NewValidator()
+	v, err := validator.NewValidator()
+	if err != nil {
+		d.Logger.Error("Cannot create validator", zap.Error(err))
+		return nil, err
+	}
+	if err := v.RegisterRules(validator.DatabaseRules(db)...); err != nil {
+		d.Logger.Error("Cannot register database validation rules", zap.Error(err))
+		return nil, err
+	}
+
+	// sign the file download URLs
+	signer := newSigner(d)
 
 	// add swagger docs if in development mode
 	if d.Config.Environment == "development" {
@@ -64,26 +143,156 @@ func Bootstrap(d *AppDeps) *deps.App {
 		e.Get("/swagger/*", swagger.HandlerDefault)
 	}
 
+	// the flags are read through the cache, the admin endpoints write to Postgres
+	flagStore := featureflag.NewPostgresProvider(db)
+	flagCache := featureflag.NewCachedProvider(d.Logger, flagStore, rdb, d.Config.FeatureFlagCacheTTL)
+
 	// set app instance
 	inst = &deps.App{
 		Config:    d.Config,
 		Logger:    d.Logger,
-		DB:        d.DB,
-		Redis:     d.Redis,
+		DB:        db,
+		Redis:     rdb,
 		Auth:      d.Auth,
+		Metrics:   d.Metrics,
+		Tracing:   d.Tracing,
 		Fiber:     e,
 		Validator: v,
+		Docs: binding.NewDocs(&binding.DocsOpts{
+			Title:   d.Config.ServiceName,
+			Version: d.Config.ServiceVersion,
+		}),
+		Versioning: versioning.NewRegistry(&versioning.Opts{
+			Logger:  d.Logger,
+			Metrics: d.Metrics,
+			Vendor:  d.Config.ServiceName,
+		}),
+		HTTPClient: httpclient.NewHTTPClient(&httpclient.Opts{
+			Logger:     d.Logger,
+			Timeout:    d.Config.HTTPClientTimeout,
+			MaxRetries: d.Config.HTTPClientMaxRetries,
+		}),
+		Flags: featureflag.NewFeatureFlag(&featureflag.Opts{
+			Logger:   d.Logger,
+			Provider: flagCache,
+		}),
+		Audit: audit.NewAudit(&audit.Opts{
+			Logger: d.Logger,
+			DB:     db,
+		}),
+		Storage: d.Storage,
+		Signer:  signer,
+	}
+	// the atlas migrations in db/migration create the tables, AutoMigrate keeps local setups in sync
+	if err := database.MigrateIfNeed(d.DB.Gorm); err != nil {
+		d.Logger.Error("Cannot migrate database", zap.Error(err))
+		return nil, err
 	}
-	database.MigrateIfNeed(inst.DB.Gorm)
+
+	// Register metrics endpoint
+	if err := d.Metrics.RegisterDBStats(d.DB); err != nil {
+		d.Logger.Error("Cannot register database metrics", zap.Error(err))
+	}
+	if err := d.Metrics.RegisterRedisPoolStats(d.Redis); err != nil {
+		d.Logger.Error("Cannot register redis metrics", zap.Error(err))
+	}
+	e.Get("/metrics", d.Metrics.Handler())
+
+	// Register admin endpoints
+	maintenanceHandler := maintenance.NewHandler(&maintenance.HandlerOpts{
+		Logger:    d.Logger,
+		Service:   maintenanceSwitch,
+		Validator: v,
+		Audit:     inst.Audit,
+	})
+	admin := e.Group("/admin", d.Auth.BasicAuth())
+	admin.Get("/maintenance", maintenanceHandler.Get)
+	admin.Put("/maintenance", maintenanceHandler.Set)
+
+	flagHandler := featureflag.NewHandler(&featureflag.HandlerOpts{
+		Logger:    d.Logger,
+		Provider:  flagStore,
+		Validator: v,
+		Audit:     inst.Audit,
+		DB:        db,
+		Cache:     flagCache,
+	})
+	admin.Get("/flags", flagHandler.List)
+	admin.Post("/flags", flagHandler.Create)
+	admin.Get("/flags/:name", flagHandler.Get)
+	admin.Put("/flags/:name", flagHandler.Update)
+	admin.Delete("/flags/:name", flagHandler.Delete)
+
+	auditHandler := audit.NewHandler(&audit.HandlerOpts{
+		Logger:    d.Logger,
+		Service:   inst.Audit,
+		Validator: v,
+	})
+	admin.Get("/audit-logs", auditHandler.List)
 
 	// Register health check endpoints
-	healthHandler := health.NewHandler(d.Config.ServiceName, d.Config.ServiceVersion)
+	healthHandler := health.NewHandler(d.Config.ServiceName, d.Config.ServiceVersion, dbGuard, redisGuard)
 	e.Get("/health", healthHandler.Check)
 	e.Get("/ready", healthHandler.Readiness)
 	e.Get("/live", healthHandler.Liveness)
 
+	// Register signed file downloads
+	storageHandler := storage.NewHandler(&storage.HandlerOpts{
+		Logger:  d.Logger,
+		Storage: d.Storage,
+		Signer:  signer,
+	})
+	e.Get(downloadPath(d.Config.StorageBaseURL)+"/*", storageHandler.Download)
+
 	// Register business handlers
 	book_handler.NewHandler(inst)
 
-	return inst
+	// add the OpenAPI document of the typed handlers if in development mode
+	if d.Config.Environment == "development" {
+		e.Get("/openapi.json", inst.Docs.Handler(e))
+	}
+
+	return inst, nil
+}
+
+// dependencyGuard returns the circuit breaker and bulkhead guarding a dependency.
+func dependencyGuard(d *AppDeps, name string, maxConcurrent int, isFailure func(err error) bool) *resilience.Guard {
+	return resilience.NewGuard(&resilience.Opts{
+		Logger:  d.Logger,
+		Metrics: d.Metrics,
+		Name:    name,
+		Breaker: circuitbreaker.Opts{
+			FailureThreshold: d.Config.DependencyBreakerFailureThreshold,
+			OpenTimeout:      d.Config.DependencyBreakerOpenTimeout,
+		},
+		MaxConcurrent: maxConcurrent,
+		MaxWait:       d.Config.DependencyBulkheadMaxWait,
+		IsFailure:     isFailure,
+	})
+}
+
+// newSigner returns the signer of the file download URLs. Without a signing key a random one is
+// used, so the URLs stop working on restart and on other instances.
+func newSigner(d *AppDeps) *storage.Signer {
+	key := []byte(d.Config.StorageSigningKey)
+	if len(key) == 0 {
+		d.Logger.Warn("STORAGE_SIGNING_KEY is not set, signing download URLs with a random key")
+		key = make([]byte, 32)
+		_, _ = rand.Read(key)
+	}
+	signer, _ := storage.NewSigner(&storage.SignerOpts{
+		Key:     key,
+		BaseURL: d.Config.StorageBaseURL,
+		TTL:     d.Config.StorageURLTTL,
+	})
+	return signer
+}
+
+// downloadPath returns the route path of the storage base URL, which may be absolute.
+func downloadPath(baseURL string) string {
+	path := "/files"
+	if u, err := url.Parse(baseURL); err == nil && u.Path != "" && u.Path != "/" {
+		path = u.Path
+	}
+	return "/" + strings.Trim(path, "/")
 }
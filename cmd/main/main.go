@@ -7,15 +7,23 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/Alwanly/go-codebase/config"
 	"github.com/Alwanly/go-codebase/pkg/authentication"
 	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/errorreport"
 	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
 	"github.com/Alwanly/go-codebase/pkg/middleware"
 	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+	"github.com/Alwanly/go-codebase/pkg/tracing"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	goredis "github.com/go-redis/redis/v9"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
 )
 
 // @title Codebase API Example documentation
@@ -49,6 +57,48 @@ func main() {
 		zap.String("environment", cfg.Environment),
 	)
 
+	// Setup metrics
+	metricsService := metrics.NewMetrics(&metrics.Opts{
+		Logger:    globalLogger,
+		Namespace: cfg.ServiceName,
+	})
+
+	// Setup tracing
+	tracer, err := tracing.NewTracing(&tracing.Opts{
+		Logger:         globalLogger,
+		ServiceName:    cfg.ServiceName,
+		ServiceVersion: cfg.ServiceVersion,
+		Environment:    cfg.Environment,
+		Exporter:       cfg.TraceExporter,
+		OTLPEndpoint:   cfg.TraceOTLPEndpoint,
+		OTLPInsecure:   cfg.TraceOTLPInsecure,
+		FilePath:       cfg.TraceFilePath,
+		SampleRatio:    utils.ToPointer(cfg.TraceSampleRatio),
+	})
+	if err != nil {
+		l.Error("Failed to initialize tracing", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Setup error reporting
+	errorReporter := errorreport.ErrorReporter(errorreport.NewZapReporter(globalLogger))
+	var asyncReporter *errorreport.AsyncReporter
+	if cfg.SentryDSN != "" {
+		sentryReporter, err := errorreport.NewSentryReporter(&errorreport.SentryOpts{
+			Logger:      globalLogger,
+			DSN:         cfg.SentryDSN,
+			Environment: cfg.Environment,
+			Release:     cfg.ServiceVersion,
+		})
+		if err != nil {
+			l.Error("Failed to initialize error reporting", zap.Error(err))
+			os.Exit(1)
+		}
+		// deliveries wait for the Sentry timeout, keep them out of the request
+		asyncReporter = errorreport.NewAsyncReporter(&errorreport.AsyncOpts{Logger: globalLogger, Reporter: sentryReporter})
+		errorReporter = errorreport.Multi(errorReporter, asyncReporter)
+	}
+
 	// Setup database
 	dbConfig := database.DBServiceOpts{
 		Debug:                      cfg.Debug,
@@ -56,6 +106,8 @@ func main() {
 		PostgresURI:                &cfg.PostgresURI,
 		PostgresMaxOpenConnections: cfg.PostgresMaxOpenConnections,
 		PostgresMaxIdleConnections: cfg.PostgresMaxIdleConnections,
+		OnSlowQuery:                metricsService.ObserveSlowQuery,
+		Plugins:                    []gorm.Plugin{tracer.GormPlugin()},
 	}
 
 	db, err := database.NewPostgres(&dbConfig)
@@ -68,6 +120,7 @@ func main() {
 	redisConfig := redis.Opts{
 		Logger:   globalLogger,
 		RedisURI: &cfg.RedisURI,
+		Hooks:    []goredis.Hook{tracer.RedisHook()},
 	}
 	redisClient, err := redis.NewRedis(&redisConfig)
 	if err != nil {
@@ -75,6 +128,17 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup file storage, uploads must not silently go to a storage that loses them
+	fileStorage, err := storage.NewStorage(&storage.Opts{
+		Logger: globalLogger,
+		Driver: cfg.StorageDriver,
+		Root:   cfg.StorageLocalRoot,
+	})
+	if err != nil {
+		l.Error("Failed to initialize file storage", zap.Error(err))
+		os.Exit(1)
+	}
+
 	// Setup middleware
 	jwtConfig := middleware.SetJwtAuth(&authentication.JWTConfig{
 		PrivateKey:     cfg.PrivateKey,
@@ -96,13 +160,21 @@ func main() {
 	}
 
 	// Create app
-	app := Bootstrap(&AppDeps{
-		Config: &cfg,
-		Logger: globalLogger,
-		DB:     db,
-		Redis:  redisClient,
-		Auth:   authMiddleware,
+	app, err := Bootstrap(&AppDeps{
+		Config:  &cfg,
+		Logger:  globalLogger,
+		DB:      db,
+		Redis:   redisClient,
+		Auth:    authMiddleware,
+		Metrics: metricsService,
+		Tracing: tracer,
+		Errors:  errorReporter,
+		Storage: fileStorage,
 	})
+	if err != nil {
+		l.Error("Failed to bootstrap application", zap.Error(err))
+		os.Exit(1)
+	}
 
 	// Register health check
 
@@ -128,6 +200,20 @@ func main() {
 			return err
 		}
 
+		if asyncReporter != nil {
+			l.Info("Flushing error reports")
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := asyncReporter.Close(ctx); err != nil {
+				l.Error("Cannot flush error reports", zap.Error(err))
+			}
+			cancel()
+		}
+
+		l.Info("Flushing traces")
+		if err := app.Tracing.Shutdown(context.Background()); err != nil {
+			l.Error("Cannot flush traces", zap.Error(err))
+		}
+
 		l.Info("Closing database connection")
 		if err := app.DB.Close(); err != nil {
 			l.Error("Cannot close database connection", zap.Error(err))
@@ -6,18 +6,20 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/Alwanly/go-codebase/pkg/middleware"
 	"github.com/spf13/viper"
 )
 
+const (
+	// exampleSigningKey is the placeholder signing key of older copies of .env.example.
+	exampleSigningKey   = "change-me"
+	minSigningKeyLength = 32
+)
+
 func LoadConfig(configName string) (GlobalConfig, error) {
 	// Load default config
 	loadDefaults()
 
-	// Load config from file
-	viper.AddConfigPath(".")
-	viper.SetConfigType("env")
-	viper.SetConfigName(configName)
-
 	// load from environment variables
 	viper.AutomaticEnv()
 
@@ -39,11 +41,9 @@ func LoadConfig(configName string) (GlobalConfig, error) {
 	}
 
 	// read config from sources (config file and environment variables)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			fmt.Println("Error reading config file:", err)
-			return GlobalConfig{}, err
-		}
+	if err := readConfigFile(configName); err != nil {
+		fmt.Println("Error reading config file:", err)
+		return GlobalConfig{}, err
 	}
 
 	// unmarshal config
@@ -52,6 +52,9 @@ func LoadConfig(configName string) (GlobalConfig, error) {
 		return GlobalConfig{}, err
 	}
 
+	// set per environment defaults
+	loadEnvironmentDefaults(&c)
+
 	// validate config
 	if err := c.Validate(); err != nil {
 		return GlobalConfig{}, fmt.Errorf("config validation failed: %w", err)
@@ -60,6 +63,52 @@ func LoadConfig(configName string) (GlobalConfig, error) {
 	return c, nil
 }
 
+// readConfigFile loads the config file, if any, below the environment variables. Empty values, such
+// as KEY= copied from .env.example, are left unset so the defaults apply, like empty environment
+// variables.
+func readConfigFile(configName string) error {
+	file := viper.New()
+	file.AddConfigPath(".")
+	file.SetConfigType("env")
+	file.SetConfigName(configName)
+	if err := file.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+
+	settings := map[string]interface{}{}
+	for key, value := range file.AllSettings() {
+		if value, ok := value.(string); ok && strings.TrimSpace(value) == "" {
+			continue
+		}
+		settings[key] = value
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+// SecurityPolicy returns the CORS and security headers policy of the configuration.
+func (c *GlobalConfig) SecurityPolicy() middleware.SecurityPolicy {
+	return middleware.SecurityPolicy{
+		CORS: middleware.CORSPolicy{
+			AllowOrigins:     c.CORSAllowOrigins,
+			AllowHeaders:     c.CORSAllowHeaders,
+			AllowCredentials: c.CORSAllowCredentials,
+			ExposeHeaders:    c.CORSExposeHeaders,
+			MaxAge:           c.CORSMaxAge,
+		},
+		Headers: middleware.SecurityHeaders{
+			HSTSMaxAge:            c.SecurityHSTSMaxAge,
+			HSTSIncludeSubdomains: true,
+			ContentSecurityPolicy: c.SecurityCSP,
+			ContentTypeNosniff:    true,
+			ReferrerPolicy:        c.SecurityReferrerPolicy,
+			FrameOptions:          c.SecurityFrameOptions,
+		},
+	}
+}
+
 // Validate validates the configuration
 func (c *GlobalConfig) Validate() error {
 	var errs []string
@@ -73,6 +122,10 @@ func (c *GlobalConfig) Validate() error {
 		errs = append(errs, "PORT must be greater than 0")
 	}
 
+	if c.RequestTimeout <= 0 {
+		errs = append(errs, "REQUEST_TIMEOUT must be greater than 0")
+	}
+
 	if c.PostgresURI == "" {
 		errs = append(errs, "POSTGRES_URI is required")
 	}
@@ -94,6 +147,27 @@ func (c *GlobalConfig) Validate() error {
 		errs = append(errs, "JWT_REFRESH_EXPIRATION must be greater than 0")
 	}
 
+	switch c.TraceExporter {
+	case "", "none", "otlp", "stdout", "file":
+	default:
+		errs = append(errs, "TRACE_EXPORTER must be one of none, otlp, stdout or file")
+	}
+
+	if c.TraceSampleRatio < 0 || c.TraceSampleRatio > 1 {
+		errs = append(errs, "TRACE_SAMPLE_RATIO must be between 0 and 1")
+	}
+
+	// the CORS middleware panics on the origins its policy rejects
+	if err := c.SecurityPolicy().CORS.Validate(); err != nil {
+		errs = append(errs, fmt.Sprintf("CORS_ALLOW_ORIGINS and CORS_ALLOW_CREDENTIALS are invalid: %v", err))
+	}
+
+	switch c.StorageDriver {
+	case "", "local", "memory":
+	default:
+		errs = append(errs, "STORAGE_DRIVER must be one of local or memory")
+	}
+
 	// Warn about missing keys in production (but don't fail)
 	if c.Environment == "production" {
 		if c.PrivateKey == "" {
@@ -102,6 +176,12 @@ func (c *GlobalConfig) Validate() error {
 		if c.PublicKey == "" {
 			errs = append(errs, "PUBLIC_KEY should be set in production")
 		}
+		if c.StorageSigningKey == "" {
+			errs = append(errs, "STORAGE_SIGNING_KEY should be set in production")
+		} else if c.StorageSigningKey == exampleSigningKey || len(c.StorageSigningKey) < minSigningKeyLength {
+			// anyone could sign download URLs with a known or short key
+			errs = append(errs, fmt.Sprintf("STORAGE_SIGNING_KEY must be a secret of at least %d bytes in production", minSigningKeyLength))
+		}
 	}
 
 	if len(errs) > 0 {
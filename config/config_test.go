@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chdir runs the test in dir, where LoadConfig looks for the config file.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+		viper.Reset()
+	})
+}
+
+func TestLoadConfig_Example(t *testing.T) {
+	chdir(t, "..")
+
+	c, err := config.LoadConfig(".env.example")
+	require.NoError(t, err)
+	assert.Equal(t, "development", c.Environment)
+	assert.Equal(t, []string{"*"}, c.CORSAllowOrigins)
+	assert.Zero(t, c.SecurityHSTSMaxAge)
+	assert.Equal(t, time.Hour, c.CORSMaxAge)
+}
+
+func TestLoadConfig_EmptyValues(t *testing.T) {
+	dir := t.TempDir()
+	env := "ENV=production\nPRIVATE_KEY=private\nPUBLIC_KEY=public\nSTORAGE_SIGNING_KEY=0123456789abcdef0123456789abcdef\n" +
+		"JWT_ISSUER=codebase\nJWT_AUDIENCE=codebase\nJWT_EXPIRATION=3600\nJWT_REFRESH_EXPIRATION=7200\n" +
+		"CORS_ALLOW_ORIGINS=\nSECURITY_HSTS_MAX_AGE=\nREQUEST_TIMEOUT=\nTRACE_SAMPLE_RATIO=\n"
+	require.NoError(t, os.WriteFile(dir+"/test.env", []byte(env), 0o600))
+	chdir(t, dir)
+
+	// empty values are unset, the defaults apply
+	c, err := config.LoadConfig("test.env")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, c.CORSAllowOrigins)
+	assert.Equal(t, 365*24*time.Hour, c.SecurityHSTSMaxAge)
+	assert.Equal(t, 30*time.Second, c.RequestTimeout)
+	assert.Equal(t, 1.0, c.TraceSampleRatio)
+}
+
+func TestLoadConfig_CORS(t *testing.T) {
+	load := func(t *testing.T, extra string) (config.GlobalConfig, error) {
+		t.Helper()
+
+		viper.Reset()
+		dir := t.TempDir()
+		env := "ENV=development\nJWT_ISSUER=codebase\nJWT_AUDIENCE=codebase\nJWT_EXPIRATION=3600\nJWT_REFRESH_EXPIRATION=7200\n" + extra
+		require.NoError(t, os.WriteFile(dir+"/test.env", []byte(env), 0o600))
+		chdir(t, dir)
+		return config.LoadConfig("test.env")
+	}
+
+	// credentials need the origins listed, the development default allows none instead of any
+	c, err := load(t, "CORS_ALLOW_CREDENTIALS=true\n")
+	require.NoError(t, err)
+	assert.Empty(t, c.CORSAllowOrigins)
+
+	// the origins rejected by the CORS middleware fail the config
+	_, err = load(t, "CORS_ALLOW_CREDENTIALS=true\nCORS_ALLOW_ORIGINS=*\n")
+	assert.ErrorContains(t, err, "CORS credentials cannot be allowed for the origin *")
+	_, err = load(t, "CORS_ALLOW_ORIGINS=https://app.example.com/books\n")
+	assert.ErrorContains(t, err, `invalid CORS origin "https://app.example.com/books"`)
+	_, err = load(t, "CORS_ALLOW_ORIGINS=*,https://app.example.com\n")
+	assert.ErrorContains(t, err, "the CORS origin * cannot be listed with other origins")
+
+	c, err = load(t, "CORS_ALLOW_CREDENTIALS=true\nCORS_ALLOW_ORIGINS=https://app.example.com,https://*.example.org\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com", "https://*.example.org"}, c.CORSAllowOrigins)
+}
+
+func TestLoadConfig_SigningKey(t *testing.T) {
+	load := func(t *testing.T, key string) error {
+		t.Helper()
+
+		viper.Reset()
+		dir := t.TempDir()
+		env := "ENV=production\nPRIVATE_KEY=private\nPUBLIC_KEY=public\nJWT_ISSUER=codebase\nJWT_AUDIENCE=codebase\n" +
+			"JWT_EXPIRATION=3600\nJWT_REFRESH_EXPIRATION=7200\nSTORAGE_SIGNING_KEY=" + key + "\n"
+		require.NoError(t, os.WriteFile(dir+"/test.env", []byte(env), 0o600))
+		chdir(t, dir)
+		_, err := config.LoadConfig("test.env")
+		return err
+	}
+
+	// known and short keys cannot sign download URLs in production
+	assert.ErrorContains(t, load(t, ""), "STORAGE_SIGNING_KEY should be set in production")
+	assert.ErrorContains(t, load(t, "change-me"), "STORAGE_SIGNING_KEY must be a secret of at least 32 bytes in production")
+	assert.ErrorContains(t, load(t, "secret"), "STORAGE_SIGNING_KEY must be a secret of at least 32 bytes in production")
+	assert.NoError(t, load(t, "0123456789abcdef0123456789abcdef"))
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,6 +14,7 @@ func loadDefaults() {
 	viper.SetDefault("PORT", 9000)
 	viper.SetDefault("PORT_GRPC", 9001)
 	viper.SetDefault("LOG_LEVEL", "debug")
+	viper.SetDefault("REQUEST_TIMEOUT", "30s")
 
 	// set service name and version
 	viper.SetDefault("SERVICE_NAME", "go-codebase")
@@ -30,4 +32,55 @@ func loadDefaults() {
 
 	// redis default
 	viper.SetDefault("REDIS_URI", "redis://redis:6379/0")
+
+	// tracing default
+	viper.SetDefault("TRACE_EXPORTER", "none")
+	viper.SetDefault("TRACE_FILE_PATH", "traces.jsonl")
+	viper.SetDefault("TRACE_SAMPLE_RATIO", 1.0)
+
+	// outbound http client default
+	viper.SetDefault("HTTP_CLIENT_TIMEOUT", "10s")
+	viper.SetDefault("HTTP_CLIENT_MAX_RETRIES", 2)
+
+	// dependency circuit breaker and bulkhead default
+	viper.SetDefault("DEPENDENCY_BREAKER_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("DEPENDENCY_BREAKER_OPEN_TIMEOUT", "30s")
+	viper.SetDefault("DEPENDENCY_BULKHEAD_MAX_WAIT", "100ms")
+	viper.SetDefault("POSTGRES_MAX_CONCURRENT_QUERIES", 20)
+	viper.SetDefault("REDIS_MAX_CONCURRENT_COMMANDS", 100)
+
+	// feature flag default
+	viper.SetDefault("FEATURE_FLAG_CACHE_TTL", "30s")
+
+	// file storage default
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_ROOT", "./storage")
+	viper.SetDefault("STORAGE_BASE_URL", "/files")
+	viper.SetDefault("STORAGE_URL_TTL", "15m")
+
+	// cors and security headers default, see loadEnvironmentDefaults for the per environment ones
+	viper.SetDefault("CORS_ALLOW_HEADERS", []string{"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization", "Idempotency-Key", "If-None-Match", "X-Request-ID"})
+	viper.SetDefault("CORS_EXPOSE_HEADERS", []string{"ETag", "X-Cache", "X-Request-ID", "X-Reference-ID", "Idempotent-Replayed", "Retry-After"})
+	viper.SetDefault("CORS_MAX_AGE", "1h")
+	viper.SetDefault("SECURITY_CSP", "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault("SECURITY_REFERRER_POLICY", "no-referrer")
+	viper.SetDefault("SECURITY_FRAME_OPTIONS", "DENY")
+}
+
+// loadEnvironmentDefaults sets the defaults depending on ENV for the values not set explicitly.
+func loadEnvironmentDefaults(c *GlobalConfig) {
+	development := c.Environment == "development"
+
+	if !viper.IsSet("CORS_ALLOW_ORIGINS") {
+		// browsers reject credentials for every origin, so they need the origins listed
+		if development && !c.CORSAllowCredentials {
+			c.CORSAllowOrigins = []string{"*"}
+		} else {
+			c.CORSAllowOrigins = []string{}
+		}
+	}
+
+	if !viper.IsSet("SECURITY_HSTS_MAX_AGE") && !development {
+		c.SecurityHSTSMaxAge = 365 * 24 * time.Hour
+	}
 }
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type GlobalConfig struct {
 	// global config
 	Environment string `mapstructure:"ENV"`
@@ -11,6 +13,9 @@ type GlobalConfig struct {
 	ServiceName    string `mapstructure:"SERVICE_NAME"`
 	ServiceVersion string `mapstructure:"SERVICE_VERSION"`
 
+	// RequestTimeout is the default deadline of every request, for example 30s
+	RequestTimeout time.Duration `mapstructure:"REQUEST_TIMEOUT"`
+
 	// Authentication
 	BasicAuthUsername string `mapstructure:"BASIC_AUTH_USERNAME"`
 	BasicAuthPassword string `mapstructure:"BASIC_AUTH_PASSWORD"`
@@ -30,4 +35,58 @@ type GlobalConfig struct {
 
 	// Redis
 	RedisURI string `mapstructure:"REDIS_URI"`
+
+	// Tracing
+	TraceExporter     string  `mapstructure:"TRACE_EXPORTER"`
+	TraceOTLPEndpoint string  `mapstructure:"TRACE_OTLP_ENDPOINT"`
+	TraceOTLPInsecure bool    `mapstructure:"TRACE_OTLP_INSECURE"`
+	TraceFilePath     string  `mapstructure:"TRACE_FILE_PATH"`
+	TraceSampleRatio  float64 `mapstructure:"TRACE_SAMPLE_RATIO"`
+
+	// CORS, list values are comma separated. Origins support subdomain wildcards such as
+	// https://*.example.com. CORS_ALLOW_ORIGINS defaults to * in development and to none otherwise.
+	CORSAllowOrigins     []string      `mapstructure:"CORS_ALLOW_ORIGINS"`
+	CORSAllowHeaders     []string      `mapstructure:"CORS_ALLOW_HEADERS"`
+	CORSAllowCredentials bool          `mapstructure:"CORS_ALLOW_CREDENTIALS"`
+	CORSExposeHeaders    []string      `mapstructure:"CORS_EXPOSE_HEADERS"`
+	CORSMaxAge           time.Duration `mapstructure:"CORS_MAX_AGE"`
+
+	// Security headers, empty values are not sent. SECURITY_HSTS_MAX_AGE defaults to 0 (disabled)
+	// in development and to one year otherwise.
+	SecurityHSTSMaxAge     time.Duration `mapstructure:"SECURITY_HSTS_MAX_AGE"`
+	SecurityCSP            string        `mapstructure:"SECURITY_CSP"`
+	SecurityReferrerPolicy string        `mapstructure:"SECURITY_REFERRER_POLICY"`
+	SecurityFrameOptions   string        `mapstructure:"SECURITY_FRAME_OPTIONS"`
+
+	// Maintenance, requests with these X-API-Key values or JWT roles bypass the read-only and
+	// maintenance modes
+	MaintenanceBypassAPIKeys []string `mapstructure:"MAINTENANCE_BYPASS_API_KEYS"`
+	MaintenanceBypassRoles   []string `mapstructure:"MAINTENANCE_BYPASS_ROLES"`
+
+	// Outbound HTTP client, the timeout of a single attempt and the number of retries
+	HTTPClientTimeout    time.Duration `mapstructure:"HTTP_CLIENT_TIMEOUT"`
+	HTTPClientMaxRetries int           `mapstructure:"HTTP_CLIENT_MAX_RETRIES"`
+
+	// Dependency circuit breakers and bulkheads around Postgres and Redis, the consecutive failures
+	// opening a breaker, how long it stays open, the concurrent calls allowed per dependency and how
+	// long a call waits for a free slot
+	DependencyBreakerFailureThreshold int           `mapstructure:"DEPENDENCY_BREAKER_FAILURE_THRESHOLD"`
+	DependencyBreakerOpenTimeout      time.Duration `mapstructure:"DEPENDENCY_BREAKER_OPEN_TIMEOUT"`
+	DependencyBulkheadMaxWait         time.Duration `mapstructure:"DEPENDENCY_BULKHEAD_MAX_WAIT"`
+	PostgresMaxConcurrentQueries      int           `mapstructure:"POSTGRES_MAX_CONCURRENT_QUERIES"`
+	RedisMaxConcurrentCommands        int           `mapstructure:"REDIS_MAX_CONCURRENT_COMMANDS"`
+
+	// Feature flags, how long a flag is cached in Redis
+	FeatureFlagCacheTTL time.Duration `mapstructure:"FEATURE_FLAG_CACHE_TTL"`
+
+	// File storage, the driver (local or memory), the directory of the local driver, the key
+	// signing the download URLs, the URL the downloads are served on and how long a URL is valid
+	StorageDriver     string        `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalRoot  string        `mapstructure:"STORAGE_LOCAL_ROOT"`
+	StorageSigningKey string        `mapstructure:"STORAGE_SIGNING_KEY"`
+	StorageBaseURL    string        `mapstructure:"STORAGE_BASE_URL"`
+	StorageURLTTL     time.Duration `mapstructure:"STORAGE_URL_TTL"`
+
+	// Error reporting, crash reports are sent to this Sentry-compatible DSN when set
+	SentryDSN string `mapstructure:"SENTRY_DSN"`
 }
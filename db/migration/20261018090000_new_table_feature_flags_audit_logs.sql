@@ -0,0 +1,12 @@
+-- Create "feature_flags" table
+CREATE TABLE "feature_flags" ("name" character varying(100) NOT NULL, "description" character varying(255) NOT NULL DEFAULT '', "enabled" boolean NOT NULL DEFAULT false, "percentage" bigint NOT NULL DEFAULT 0, "user_ids" jsonb NULL, "roles" jsonb NULL, "tenant_ids" jsonb NULL, "created_at" timestamptz NOT NULL, "updated_at" timestamptz NOT NULL, "updated_by" character varying(255) NOT NULL DEFAULT '', PRIMARY KEY ("name"));
+-- Create "audit_logs" table
+CREATE TABLE "audit_logs" ("id" character varying(36) NOT NULL, "actor_id" character varying(255) NOT NULL DEFAULT '', "actor_tenant_id" character varying(255) NOT NULL DEFAULT '', "action" character varying(50) NOT NULL, "entity_type" character varying(100) NOT NULL, "entity_id" character varying(255) NOT NULL, "changes" jsonb NULL, "request_id" character varying(128) NOT NULL DEFAULT '', "ip" character varying(45) NOT NULL DEFAULT '', "created_at" timestamptz NOT NULL, PRIMARY KEY ("id"));
+-- Create index "idx_audit_logs_actor_id" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+-- Create index "idx_audit_logs_created_at" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+-- Create index "idx_audit_logs_entity" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_entity" ON "audit_logs" ("entity_type", "entity_id");
+-- Create index "idx_audit_logs_request_id" to table: "audit_logs"
+CREATE INDEX "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
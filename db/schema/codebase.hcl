@@ -33,4 +33,118 @@ table "users" {
   primary_key {
     columns = [column.id]
   }
-}
\ No newline at end of file
+}
+
+table "feature_flags" {
+  schema = schema.public
+  column "name" {
+    null = false
+    type = varchar(100)
+  }
+  column "description" {
+    null    = false
+    type    = varchar(255)
+    default = ""
+  }
+  column "enabled" {
+    null    = false
+    type    = boolean
+    default = false
+  }
+  column "percentage" {
+    null    = false
+    type    = bigint
+    default = 0
+  }
+  column "user_ids" {
+    null = true
+    type = jsonb
+  }
+  column "roles" {
+    null = true
+    type = jsonb
+  }
+  column "tenant_ids" {
+    null = true
+    type = jsonb
+  }
+  column "created_at" {
+    null = false
+    type = timestamptz
+  }
+  column "updated_at" {
+    null = false
+    type = timestamptz
+  }
+  column "updated_by" {
+    null    = false
+    type    = varchar(255)
+    default = ""
+  }
+  primary_key {
+    columns = [column.name]
+  }
+}
+
+table "audit_logs" {
+  schema = schema.public
+  column "id" {
+    null = false
+    type = varchar(36)
+  }
+  column "actor_id" {
+    null    = false
+    type    = varchar(255)
+    default = ""
+  }
+  column "actor_tenant_id" {
+    null    = false
+    type    = varchar(255)
+    default = ""
+  }
+  column "action" {
+    null = false
+    type = varchar(50)
+  }
+  column "entity_type" {
+    null = false
+    type = varchar(100)
+  }
+  column "entity_id" {
+    null = false
+    type = varchar(255)
+  }
+  column "changes" {
+    null = true
+    type = jsonb
+  }
+  column "request_id" {
+    null    = false
+    type    = varchar(128)
+    default = ""
+  }
+  column "ip" {
+    null    = false
+    type    = varchar(45)
+    default = ""
+  }
+  column "created_at" {
+    null = false
+    type = timestamptz
+  }
+  primary_key {
+    columns = [column.id]
+  }
+  index "idx_audit_logs_actor_id" {
+    columns = [column.actor_id]
+  }
+  index "idx_audit_logs_entity" {
+    columns = [column.entity_type, column.entity_id]
+  }
+  index "idx_audit_logs_request_id" {
+    columns = [column.request_id]
+  }
+  index "idx_audit_logs_created_at" {
+    columns = [column.created_at]
+  }
+}
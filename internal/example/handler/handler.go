@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/Alwanly/go-codebase/internal/example/repository"
 	"github.com/Alwanly/go-codebase/internal/example/schema"
 	"github.com/Alwanly/go-codebase/internal/example/usecase"
 	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/cache"
 	"github.com/Alwanly/go-codebase/pkg/deps"
-	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
 	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
@@ -27,10 +31,19 @@ func NewHandler(d *deps.App) *Handler {
 		DB:    d.DB,
 		Redis: d.Redis,
 	})
+	responseCache := cache.NewCache(&cache.Opts{
+		Logger: d.Logger,
+		Redis:  d.Redis,
+	})
 	usecase := usecase.NewUseCase(usecase.UseCase{
 		Config:     d.Config,
 		Logger:     d.Logger,
 		Repository: repository,
+		Cache:      responseCache,
+		Metrics:    d.Metrics,
+		Audit:      d.Audit,
+		Storage:    d.Storage,
+		Signer:     d.Signer,
 	})
 	handler := &Handler{
 		Logger:    d.Logger,
@@ -38,126 +51,82 @@ func NewHandler(d *deps.App) *Handler {
 		UseCase:   usecase,
 	}
 
-	e := d.Fiber.Group("/books/v1", d.Auth.JwtAuth())
-	e.Post("/", handler.Create)
-	e.Get("/", handler.List)
-	e.Get("/:id", handler.Get)
-	e.Put("/:id", handler.Update)
-	e.Delete("/:id", handler.Delete)
-	return handler
-}
-
-// Create creates a new book.
-func (h *Handler) Create(c *fiber.Ctx) error {
-	l := logger.WithID(h.Logger, ContextName, "Create")
-
-	// bind model
-	model := &schema.RequestBookCreate{}
-	if err := binding.BindModel(l, c, model, binding.BindFromBody()); err != nil {
-		perr := err.(*binding.ModelBindingError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// validate model
-	if err := validator.ValidateModel(l, h.Validator, model); err != nil {
-		perr := err.(*validator.ModelValidationError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// create a new book
-	response := h.UseCase.Create(c.UserContext(), model)
-	return c.Status(response.Code).JSON(response)
-}
-
-// List returns a list of books.
-func (h *Handler) List(c *fiber.Ctx) error {
-	l := logger.WithID(h.Logger, ContextName, "List")
-
-	// bind model
-	model := &schema.RequestBookList{
-		Page:      1,
-		PageSize:  10,
-		SortBy:    "title",
-		SortOrder: "desc",
-	}
-	if err := binding.BindModel(l, c, model, binding.BindFromQuery()); err != nil {
-		perr := err.(*binding.ModelBindingError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// validate model
-	if err := validator.ValidateModel(l, h.Validator, model); err != nil {
-		perr := err.(*validator.ModelValidationError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// get list of books
-	response := h.UseCase.List(c.UserContext(), model)
-	return c.Status(response.Code).JSON(response)
-}
-
-// Get returns a book by ID.
-func (h *Handler) Get(c *fiber.Ctx) error {
-	l := logger.WithID(h.Logger, ContextName, "Get")
-
-	// bind model
-	model := &schema.RequestBookGet{}
-	if err := binding.BindModel(l, c, model, binding.BindFromParams()); err != nil {
-		perr := err.(*binding.ModelBindingError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// validate model
-	if err := validator.ValidateModel(l, h.Validator, model); err != nil {
-		perr := err.(*validator.ModelValidationError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// get book by ID
-	response := h.UseCase.Get(c.UserContext(), model)
-	return c.Status(response.Code).JSON(response)
-}
-
-// Update updates a book by ID.
-func (h *Handler) Update(c *fiber.Ctx) error {
-	l := logger.WithID(h.Logger, ContextName, "Update")
-
-	// bind model
-	model := &schema.RequestBookUpdate{}
-	if err := binding.BindModel(l, c, model, binding.BindFromParams(), binding.BindFromBody()); err != nil {
-		perr := err.(*binding.ModelBindingError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// validate model
-	if err := validator.ValidateModel(l, h.Validator, model); err != nil {
-		perr := err.(*validator.ModelValidationError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// update book by ID
-	response := h.UseCase.Update(c.UserContext(), model)
-	return c.Status(response.Code).JSON(response)
-}
-
-// Delete deletes a book by ID.
-func (h *Handler) Delete(c *fiber.Ctx) error {
-	l := logger.WithID(h.Logger, ContextName, "Delete")
-
-	// bind model
-	model := &schema.RequestBookDelete{}
-	if err := binding.BindModel(l, c, model, binding.BindFromParams()); err != nil {
-		perr := err.(*binding.ModelBindingError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
-	}
-
-	// validate model
-	if err := validator.ValidateModel(l, h.Validator, model); err != nil {
-		perr := err.(*validator.ModelValidationError)
-		return c.Status(perr.Code).JSON(perr.ResponseBody)
+	idempotency := middleware.Idempotency(middleware.IdempotencyOpts{
+		Logger: d.Logger,
+		Redis:  d.Redis,
+	})
+	opts := &binding.HandleOpts{
+		Logger:    d.Logger,
+		Validator: d.Validator,
+		Docs:      d.Docs,
 	}
-
-	// delete book by ID
-	response := h.UseCase.Delete(c.UserContext(), model)
-	return c.Status(response.Code).JSON(response)
+	// books are small, reject larger or unexpected bodies
+	bookBody := binding.BindFromStrictJSON(&binding.StrictJSONOpts{MaxBytes: 16 << 10, MaxDepth: 4})
+
+	books := d.Versioning.Resource(d.Fiber, "/books", d.Auth.JwtAuth())
+	books.Version(versioning.Version{Name: "v1"}, func(e fiber.Router) {
+		e.Post("/", idempotency, binding.Handle(opts, binding.Sources(bookBody), usecase.Create, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.create",
+			Summary:   "Create a book",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusCreated: {Body: schema.ResponseBookCreate{}}},
+		}))).Name("books.v1.create")
+		e.Get("/", middleware.Cache(middleware.CacheOpts{
+			Logger: d.Logger,
+			Cache:  responseCache,
+			Tags: func(c *fiber.Ctx) []string {
+				return []string{schema.BookListCacheTag}
+			},
+		}), binding.Handle(opts, binding.Sources(binding.BindFromQuery(), binding.BindFromQueryDSL()), usecase.List, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.list",
+			Summary:   "List books",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusOK: {Body: []schema.ResponseBookGet{}}},
+		}))).Name("books.v1.list")
+		e.Get("/:id", middleware.Cache(middleware.CacheOpts{
+			Logger: d.Logger,
+			Cache:  responseCache,
+			Tags: func(c *fiber.Ctx) []string {
+				return []string{schema.BookCacheTag(c.Params("id"))}
+			},
+		}), binding.Handle(opts, binding.Sources(binding.BindFromParams()), usecase.Get, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.get",
+			Summary:   "Get a book",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusOK: {Body: schema.ResponseBookGet{}}, http.StatusNotFound: {}},
+		}))).Name("books.v1.get")
+		e.Put("/:id", binding.Handle(opts, binding.Sources(binding.BindFromParams(), bookBody), usecase.Update, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.update",
+			Summary:   "Update a book",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusOK: {Body: schema.ResponseBookUpdate{}}, http.StatusNotFound: {}},
+		}))).Name("books.v1.update")
+		e.Delete("/:id", binding.Handle(opts, binding.Sources(binding.BindFromParams()), usecase.Delete, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.delete",
+			Summary:   "Delete a book",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusNoContent: {}, http.StatusNotFound: {}},
+		}))).Name("books.v1.delete")
+		e.Put("/:id/cover", binding.Handle(opts, binding.Sources(binding.BindFromParams(), binding.BindFromMultipart()), usecase.UpdateCover, binding.WithOperation(binding.Operation{
+			ID:        "books.v1.cover.update",
+			Summary:   "Upload the cover of a book",
+			Tags:      []string{"books"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusOK: {Body: schema.ResponseBookCover{}}, http.StatusNotFound: {}},
+		}))).Name("books.v1.cover.update")
+		e.Get("/:id/cover", binding.Handle(opts, binding.Sources(binding.BindFromParams()), usecase.GetCover, binding.WithOperation(binding.Operation{
+			ID:          "books.v1.cover.get",
+			Summary:     "Get the cover of a book",
+			Description: "Returns a signed download URL of the cover.",
+			Tags:        []string{"books"},
+			Security:    []string{"BearerAuth"},
+			Responses:   map[int]binding.Response{http.StatusOK: {Body: schema.ResponseBookCover{}}, http.StatusNotFound: {}},
+		}))).Name("books.v1.cover.get")
+	})
+	return handler
 }
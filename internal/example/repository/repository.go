@@ -2,13 +2,14 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/Alwanly/go-codebase/internal/example/schema"
 	"github.com/Alwanly/go-codebase/model"
 	"github.com/Alwanly/go-codebase/pkg/database"
 	"github.com/Alwanly/go-codebase/pkg/redis"
 	"github.com/Alwanly/go-codebase/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const ContextName = "Internal.User.Repository"
@@ -21,10 +22,12 @@ type (
 
 	IRepository interface {
 		Create(context.Context, *model.Book) error
-		Get(context.Context, string) *model.Book
-		List(context.Context, schema.RequestBookList) ([]model.Book, int64)
+		Get(context.Context, string) (*model.Book, error)
+		GetForUpdate(context.Context, string) (*model.Book, error)
+		List(context.Context, schema.RequestBookList) ([]model.Book, int64, error)
 		Update(context.Context, *model.Book) error
 		Delete(context.Context, string) error
+		Transaction(context.Context, func(context.Context) error) error
 	}
 )
 
@@ -39,25 +42,44 @@ func (r *Repository) Create(ctx context.Context, book *model.Book) error {
 	return r.DB.GetTransaction(ctx).Create(book).Error
 }
 
-func (r *Repository) Get(ctx context.Context, id string) *model.Book {
+func (r *Repository) Get(ctx context.Context, id string) (*model.Book, error) {
 	var book model.Book
-	r.DB.GetTransaction(ctx).Where("id = ?", id).First(&book)
-	return &book
+	if err := r.DB.GetTransaction(ctx).Where("id = ?", id).First(&book).Error; err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// GetForUpdate returns the book with its row locked until the end of the transaction of the context.
+func (r *Repository) GetForUpdate(ctx context.Context, id string) (*model.Book, error) {
+	return r.Get(r.DB.SetUpdateLockType(ctx), id)
 }
 
-func (r *Repository) List(ctx context.Context, req schema.RequestBookList) ([]model.Book, int64) {
+func (r *Repository) List(ctx context.Context, req schema.RequestBookList) ([]model.Book, int64, error) {
 	var books []model.Book
 	var total int64
-	tx := r.DB.GetTransaction(ctx)
 
-	tx.Model(&model.Book{}).Count(&total)
+	// a new session per statement, the count and the page share the filters
+	tx := req.Query.Scope(r.DB.GetTransaction(ctx).Model(&model.Book{}))
+	if len(req.Query.Sorts) == 0 {
+		// sort_by and sort_order predate the sort parameter
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: req.SortBy}, Desc: req.SortOrder == "desc"})
+	}
+	if !req.Query.HasSort("id") {
+		// the id breaks the ties, for stable pages
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}})
+	}
+	tx = tx.Session(&gorm.Session{})
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
 	offset := utils.CalculatePageSkip(req.Page, req.PageSize)
-	tx.Offset(offset).Limit(req.PageSize)
-	tx.Order(fmt.Sprintf("%s %s", req.SortBy, req.SortOrder))
-	tx.Find(&books)
+	if err := tx.Offset(offset).Limit(req.PageSize).Find(&books).Error; err != nil {
+		return nil, 0, err
+	}
 
-	return books, total
+	return books, total, nil
 }
 
 func (r *Repository) Update(ctx context.Context, book *model.Book) error {
@@ -67,3 +89,9 @@ func (r *Repository) Update(ctx context.Context, book *model.Book) error {
 func (r *Repository) Delete(ctx context.Context, id string) error {
 	return r.DB.GetTransaction(ctx).Where("id = ?", id).Delete(&model.Book{}).Error
 }
+
+// Transaction runs fn in a transaction attached to the context, committed when fn succeeds and
+// rolled back otherwise. fn runs in a savepoint of the transaction already attached to ctx, if any.
+func (r *Repository) Transaction(ctx context.Context, fn func(context.Context) error) error {
+	return r.DB.WithTransaction(ctx, nil, fn)
+}
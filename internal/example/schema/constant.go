@@ -1,13 +1,54 @@
 package schema
 
 import (
+	"mime/multipart"
+
 	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
 	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/query"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+)
+
+const (
+	// BookListCacheTag tags every cached book list response.
+	BookListCacheTag = "books"
+
+	// BookEntityType identifies the books in the audit logs.
+	BookEntityType = "book"
+
+	ErrorBookNotFound      = "Book not found"
+	ErrorBookCoverNotFound = "Book cover not found"
+	ErrorFailedToStoreFile = "Failed to store file"
+)
+
+var (
+	StatusCodeBookNotFound      = contract.CreateStatusCode("0004")
+	StatusCodeBookCoverNotFound = contract.CreateStatusCode("0005")
 )
 
+// BookCacheTag returns the tag attached to every cached response of a single book.
+func BookCacheTag(id string) string {
+	return "book:" + id
+}
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		ErrorBookNotFound:      "Buku tidak ditemukan",
+		ErrorBookCoverNotFound: "Sampul buku tidak ditemukan",
+		ErrorFailedToStoreFile: "Gagal menyimpan file",
+	})
+}
+
+// BookCoverKey returns the storage key of the cover of a book.
+func BookCoverKey(id string) string {
+	return "books/" + id + "/cover"
+}
+
 type RequestBookCreate struct {
-	Title  string `json:"title" validate:"required,min=3,max=255"`
-	Author string `json:"author" validate:"required"`
+	Title  string `json:"title" mod:"strip_html,collapse_spaces,unicode_nfc" validate:"required,min=3,max=255"`
+	Author string `json:"author" mod:"collapse_spaces,unicode_nfc" validate:"required"`
 
 	AuthUserData *middleware.AuthUserData
 }
@@ -29,19 +70,49 @@ type ResponseBookGet struct {
 }
 
 type RequestBookList struct {
-	Page      int    `query:"page" validate:"required,min=1"`
-	PageSize  int    `query:"page_size" validate:"required,min=1,max=100"`
+	Page     int `query:"page" validate:"required,min=1"`
+	PageSize int `query:"page_size" validate:"required,min=1,max=100"`
+	// SortBy and SortOrder sort the books when the query has no sort parameter, title desc by default.
 	SortBy    string `query:"sort_by" validate:"required,oneof=title author"`
 	SortOrder string `query:"sort_order" validate:"required,oneof=asc desc"`
+	// Query filters, searches and sorts the books, within BookListQuery.
+	Query query.Query
 
 	AuthUserData *middleware.AuthUserData
 }
 
+// BookListQuery is the whitelist of the query DSL of the book list, e.g.
+// ?filter[author][eq]=Frank Herbert&filter[created_at][gte]=2024-01-01&sort=-created_at,title&q=dune
+var BookListQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"title":      {Column: "title", Operators: []query.Operator{query.OpEq, query.OpNe, query.OpIn, query.OpContains}, Sortable: true},
+		"author":     {Column: "author", Operators: []query.Operator{query.OpEq, query.OpNe, query.OpIn, query.OpContains}, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.TypeDateTime, Operators: []query.Operator{query.OpGt, query.OpGte, query.OpLt, query.OpLte}, Sortable: true},
+		"updated_at": {Column: "updated_at", Type: query.TypeDateTime, Operators: []query.Operator{query.OpGt, query.OpGte, query.OpLt, query.OpLte}, Sortable: true},
+		"id":         {Column: "id", Sortable: true},
+	},
+	Search: []string{"title", "author"},
+	// without sort parameter, the books are sorted by SortBy and SortOrder
+}
+
+// Defaults sets the first page of ten books sorted by descending title.
+func (r *RequestBookList) Defaults() {
+	r.Page = 1
+	r.PageSize = 10
+	r.SortBy = "title"
+	r.SortOrder = "desc"
+}
+
+// QuerySchema returns the whitelist of the query DSL of the book list.
+func (r *RequestBookList) QuerySchema() *query.Schema {
+	return BookListQuery
+}
+
 type RequestBookUpdate struct {
 	ID string `params:"id" validate:"required"`
 
-	Title  string `json:"title" validate:"required,min=3,max=255"`
-	Author string `json:"author" validate:"required"`
+	Title  string `json:"title" mod:"strip_html,collapse_spaces,unicode_nfc" validate:"required,min=3,max=255"`
+	Author string `json:"author" mod:"collapse_spaces,unicode_nfc" validate:"required"`
 
 	AuthUserData *middleware.AuthUserData
 }
@@ -58,6 +129,30 @@ type RequestBookDelete struct {
 
 type ResponseBookDelete struct{}
 
+type RequestBookCoverUpdate struct {
+	ID    string                `params:"id" validate:"required"`
+	Cover *multipart.FileHeader `form:"cover" validate:"required,file_max_size=2MB,file_mime=image/png image/jpeg image/webp"`
+
+	AuthUserData *middleware.AuthUserData
+}
+
+type RequestBookCoverGet struct {
+	ID string `params:"id" validate:"required"`
+
+	AuthUserData *middleware.AuthUserData
+}
+
+type ResponseBookCover struct {
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+}
+
+// BookCoverChange is the audited state of a book cover, nil when the book has none.
+type BookCoverChange struct {
+	Cover *storage.Object `json:"cover"`
+}
+
 func (r *RequestBookList) ToResponse(books []model.Book) []ResponseBookGet {
 	responseBooks := make([]ResponseBookGet, len(books))
 	for i, book := range books {
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
@@ -9,10 +10,19 @@ import (
 	"github.com/Alwanly/go-codebase/internal/example/repository"
 	"github.com/Alwanly/go-codebase/internal/example/schema"
 	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/cache"
 	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+	"github.com/Alwanly/go-codebase/pkg/validator"
 	"github.com/Alwanly/go-codebase/pkg/wrapper"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 const ContextName = "Internal.Book.Usecase"
@@ -22,6 +32,13 @@ type (
 		Config     *config.GlobalConfig
 		Logger     *zap.Logger
 		Repository repository.IRepository
+		Cache      cache.ICacheService
+		Metrics    metrics.IMetricsService
+		Audit      audit.IAuditService
+		Storage    storage.IStorage
+		Signer     *storage.Signer
+
+		mutations *prometheus.CounterVec
 	}
 
 	IUseCase interface {
@@ -30,6 +47,8 @@ type (
 		List(context.Context, *schema.RequestBookList) wrapper.JSONResult
 		Update(context.Context, *schema.RequestBookUpdate) wrapper.JSONResult
 		Delete(context.Context, *schema.RequestBookDelete) wrapper.JSONResult
+		UpdateCover(context.Context, *schema.RequestBookCoverUpdate) wrapper.JSONResult
+		GetCover(context.Context, *schema.RequestBookCoverGet) wrapper.JSONResult
 	}
 )
 
@@ -38,11 +57,18 @@ func NewUseCase(uc UseCase) IUseCase {
 		Config:     uc.Config,
 		Logger:     uc.Logger,
 		Repository: uc.Repository,
+		Cache:      uc.Cache,
+		Metrics:    uc.Metrics,
+		Audit:      uc.Audit,
+		Storage:    uc.Storage,
+		Signer:     uc.Signer,
+
+		mutations: uc.Metrics.Counter("book_mutations_total", "Total number of book mutations.", "action"),
 	}
 }
 
 func (u *UseCase) Create(ctx context.Context, req *schema.RequestBookCreate) wrapper.JSONResult {
-	l := u.Logger.With(zap.String("usecase", "Create"))
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "Create"))
 
 	// Create a new book
 	now := time.Now()
@@ -55,24 +81,30 @@ func (u *UseCase) Create(ctx context.Context, req *schema.RequestBookCreate) wra
 		UpdatedAt: now,
 	}
 
-	if err := u.Repository.Create(ctx, book); err != nil {
+	err := u.Repository.Transaction(ctx, func(ctx context.Context) error {
+		if err := u.Repository.Create(ctx, book); err != nil {
+			return err
+		}
+		return u.Audit.Record(ctx, audit.Entry{Action: audit.ActionCreate, EntityType: schema.BookEntityType, EntityID: book.ID, After: book})
+	})
+	if err != nil {
 		l.Error("failed to create a book", zap.Error(err))
-		return wrapper.ResponseFailed(500, contract.StatusCodeInternalServerError, "Failed to create a book", nil)
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToInsertRecord).Wrap(err))
 	}
 
 	l.Debug("book created", zap.String("id", book.ID))
+	u.mutations.WithLabelValues("create").Inc()
+	u.invalidateCache(ctx, l, schema.BookListCacheTag)
 
 	return wrapper.ResponseSuccess(http.StatusCreated, schema.ResponseBookCreate{ID: book.ID})
 }
 
 func (u *UseCase) Get(ctx context.Context, req *schema.RequestBookGet) wrapper.JSONResult {
-	l := u.Logger.With(zap.String("usecase", "Get"))
-
-	book := u.Repository.Get(ctx, req.ID)
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "Get"))
 
-	if book == nil {
-		l.Error("book not found", zap.String("id", req.ID))
-		return wrapper.ResponseFailed(http.StatusNotFound, contract.CreateStatusCode("0004"), "Book not found", nil)
+	book, err := u.findBook(ctx, l, req.ID)
+	if err != nil {
+		return wrapper.ResponseFromError(err)
 	}
 
 	return wrapper.ResponseSuccess(http.StatusOK, schema.ResponseBookGet{
@@ -83,17 +115,13 @@ func (u *UseCase) Get(ctx context.Context, req *schema.RequestBookGet) wrapper.J
 }
 
 func (u *UseCase) List(ctx context.Context, req *schema.RequestBookList) wrapper.JSONResult {
-	l := u.Logger.With(zap.String("usecase", "List"))
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "List"))
 
-	filter := schema.RequestBookList{
-		Page:      req.Page,
-		PageSize:  req.PageSize,
-		SortBy:    req.SortBy,
-		SortOrder: req.SortOrder,
-
-		AuthUserData: req.AuthUserData,
+	books, total, err := u.Repository.List(ctx, *req)
+	if err != nil {
+		l.Error("failed to list books", zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err))
 	}
-	books, total := u.Repository.List(ctx, filter)
 
 	response := req.ToResponse(books)
 	l.Debug("books listed", zap.Int64("total", total))
@@ -101,43 +129,195 @@ func (u *UseCase) List(ctx context.Context, req *schema.RequestBookList) wrapper
 }
 
 func (u *UseCase) Update(ctx context.Context, req *schema.RequestBookUpdate) wrapper.JSONResult {
-	l := u.Logger.With(zap.String("usecase", "Update"))
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "Update"))
 
-	book := u.Repository.Get(ctx, req.ID)
-	if book == nil {
-		l.Error("book not found", zap.String("id", req.ID))
-		return wrapper.ResponseFailed(http.StatusNotFound, contract.CreateStatusCode("0004"), "Book not found", nil)
-	}
+	// the row is locked until the end of the transaction, so the audit log diffs the replaced book
+	var book *model.Book
+	err := u.Repository.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if book, err = u.lockBook(ctx, l, req.ID); err != nil {
+			return err
+		}
 
-	book.Title = req.Title
-	book.Author = req.Author
-	book.UpdatedAt = time.Now()
+		before := *book
+		book.Title = req.Title
+		book.Author = req.Author
+		book.UpdatedAt = time.Now()
 
-	if err := u.Repository.Update(ctx, book); err != nil {
+		if err := u.Repository.Update(ctx, book); err != nil {
+			return err
+		}
+		return u.Audit.Record(ctx, audit.Entry{Action: audit.ActionUpdate, EntityType: schema.BookEntityType, EntityID: book.ID, Before: before, After: book})
+	})
+	if appErr, ok := apperror.AsKind(err, apperror.KindNotFound); ok {
+		return wrapper.ResponseFromError(appErr)
+	}
+	if err != nil {
 		l.Error("failed to update a book", zap.Error(err))
-		return wrapper.ResponseFailed(500, contract.StatusCodeInternalServerError, "Failed to update a book", nil)
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToUpdateRecord).Wrap(err))
 	}
 
 	l.Debug("book updated", zap.String("id", book.ID))
+	u.mutations.WithLabelValues("update").Inc()
+	u.invalidateCache(ctx, l, schema.BookCacheTag(book.ID), schema.BookListCacheTag)
 
 	return wrapper.ResponseSuccess(http.StatusOK, schema.ResponseBookUpdate{ID: book.ID})
 }
 
 func (u *UseCase) Delete(ctx context.Context, req *schema.RequestBookDelete) wrapper.JSONResult {
-	l := u.Logger.With(zap.String("usecase", "Delete"))
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "Delete"))
 
-	book := u.Repository.Get(ctx, req.ID)
-	if book == nil {
-		l.Error("book not found", zap.String("id", req.ID))
-		return wrapper.ResponseFailed(http.StatusNotFound, contract.CreateStatusCode("0004"), "Book not found", nil)
-	}
+	// the row is locked until the end of the transaction, so the audit log keeps the deleted book
+	var book *model.Book
+	err := u.Repository.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		if book, err = u.lockBook(ctx, l, req.ID); err != nil {
+			return err
+		}
 
-	if err := u.Repository.Delete(ctx, book.ID); err != nil {
+		if err := u.Repository.Delete(ctx, book.ID); err != nil {
+			return err
+		}
+		return u.Audit.Record(ctx, audit.Entry{Action: audit.ActionDelete, EntityType: schema.BookEntityType, EntityID: book.ID, Before: book})
+	})
+	if appErr, ok := apperror.AsKind(err, apperror.KindNotFound); ok {
+		return wrapper.ResponseFromError(appErr)
+	}
+	if err != nil {
 		l.Error("failed to delete a book", zap.Error(err))
-		return wrapper.ResponseFailed(500, contract.StatusCodeInternalServerError, "Failed to delete a book", nil)
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToDeleteRecord).Wrap(err))
+	}
+
+	// an orphaned cover is only wasted space, so a failure does not fail the deletion. The book is
+	// gone already, the cover must be removed even when the request deadline has passed.
+	if err := u.Storage.Delete(context.WithoutCancel(ctx), schema.BookCoverKey(book.ID)); err != nil {
+		l.Warn("failed to delete the book cover", zap.String("id", book.ID), zap.Error(err))
 	}
 
 	l.Debug("book deleted", zap.String("id", book.ID))
+	u.mutations.WithLabelValues("delete").Inc()
+	u.invalidateCache(ctx, l, schema.BookCacheTag(book.ID), schema.BookListCacheTag)
 
 	return wrapper.ResponseSuccess(http.StatusNoContent, schema.ResponseBookDelete{})
 }
+
+func (u *UseCase) UpdateCover(ctx context.Context, req *schema.RequestBookCoverUpdate) wrapper.JSONResult {
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "UpdateCover"))
+
+	book, err := u.findBook(ctx, l, req.ID)
+	if err != nil {
+		return wrapper.ResponseFromError(err)
+	}
+
+	// serve the sniffed type, the one sent by the client is not trusted
+	contentType, err := validator.DetectFileType(req.Cover)
+	if err != nil {
+		l.Error("failed to read the book cover", zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, schema.ErrorFailedToStoreFile).Wrap(err))
+	}
+	file, err := req.Cover.Open()
+	if err != nil {
+		l.Error("failed to open the book cover", zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, schema.ErrorFailedToStoreFile).Wrap(err))
+	}
+	defer file.Close()
+
+	// the replaced cover, if any, for the audit log
+	var before *storage.Object
+	if previous, err := u.Storage.Stat(ctx, schema.BookCoverKey(book.ID)); err == nil {
+		before = &previous
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		l.Error("failed to read the book cover", zap.String("id", book.ID), zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, schema.ErrorFailedToStoreFile).Wrap(err))
+	}
+
+	object, err := u.Storage.Put(ctx, schema.BookCoverKey(book.ID), file, storage.PutOptions{ContentType: contentType})
+	if err != nil {
+		l.Error("failed to store the book cover", zap.String("id", book.ID), zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, schema.ErrorFailedToStoreFile).Wrap(err))
+	}
+
+	// the storage is not transactional, the cover is replaced already when the log is stored
+	entry := audit.Entry{
+		Action:     audit.ActionUpdate,
+		EntityType: schema.BookEntityType,
+		EntityID:   book.ID,
+		Before:     schema.BookCoverChange{Cover: before},
+		After:      schema.BookCoverChange{Cover: &object},
+	}
+	if err := u.Audit.Record(ctx, entry); err != nil {
+		l.Error("failed to record the book cover change", zap.String("id", book.ID), zap.Error(err))
+	}
+
+	l.Debug("book cover updated", zap.String("id", book.ID), zap.Int64("size", object.Size))
+	u.mutations.WithLabelValues("update_cover").Inc()
+	u.invalidateCache(ctx, l, schema.BookCacheTag(book.ID), schema.BookListCacheTag)
+
+	return wrapper.ResponseSuccess(http.StatusOK, schema.ResponseBookCover{
+		URL:         u.Signer.URL(object.Key),
+		ContentType: object.ContentType,
+		Size:        object.Size,
+	})
+}
+
+func (u *UseCase) GetCover(ctx context.Context, req *schema.RequestBookCoverGet) wrapper.JSONResult {
+	l := logger.WithContext(ctx, u.Logger).With(zap.String("usecase", "GetCover"))
+
+	book, err := u.findBook(ctx, l, req.ID)
+	if err != nil {
+		return wrapper.ResponseFromError(err)
+	}
+
+	object, err := u.Storage.Stat(ctx, schema.BookCoverKey(book.ID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return wrapper.ResponseFromError(apperror.NotFound(schema.StatusCodeBookCoverNotFound, schema.ErrorBookCoverNotFound))
+	}
+	if err != nil {
+		l.Error("failed to find the book cover", zap.String("id", book.ID), zap.Error(err))
+		return wrapper.ResponseFromError(apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err))
+	}
+
+	return wrapper.ResponseSuccess(http.StatusOK, schema.ResponseBookCover{
+		URL:         u.Signer.URL(object.Key),
+		ContentType: object.ContentType,
+		Size:        object.Size,
+	})
+}
+
+// findBook returns the book with the given ID, or a domain error when it cannot be found.
+func (u *UseCase) findBook(ctx context.Context, l *zap.Logger, id string) (*model.Book, error) {
+	book, err := u.Repository.Get(ctx, id)
+	return book, bookError(l, id, err)
+}
+
+// lockBook returns the book with its row locked until the end of the transaction of the context.
+func (u *UseCase) lockBook(ctx context.Context, l *zap.Logger, id string) (*model.Book, error) {
+	book, err := u.Repository.GetForUpdate(ctx, id)
+	return book, bookError(l, id, err)
+}
+
+// bookError returns the error answered when a book cannot be read, if any.
+func bookError(l *zap.Logger, id string, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		l.Debug("book not found", zap.String("id", id))
+		return apperror.NotFound(schema.StatusCodeBookNotFound, schema.ErrorBookNotFound)
+	}
+	if err != nil {
+		l.Error("failed to find a book", zap.String("id", id), zap.Error(err))
+		return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+	return nil
+}
+
+// invalidateCache drops cached responses of the given tags. A failure only leaves stale
+// responses until they expire, so it is logged instead of failing the mutation. The change is
+// committed already, the responses must be dropped even when the request deadline has passed.
+func (u *UseCase) invalidateCache(ctx context.Context, l *zap.Logger, tags ...string) {
+	if u.Cache == nil {
+		return
+	}
+
+	if err := u.Cache.InvalidateTags(context.WithoutCancel(ctx), tags...); err != nil {
+		l.Warn("failed to invalidate cache", zap.Strings("tags", tags), zap.Error(err))
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// AuditLog model
+type AuditLog struct {
+	ID            string `gorm:"primaryKey;column:id;type:varchar(36);not null" json:"id"`
+	ActorID       string `gorm:"column:actor_id;type:varchar(255);not null;default:'';index" json:"actorId"`
+	ActorTenantID string `gorm:"column:actor_tenant_id;type:varchar(255);not null;default:''" json:"actorTenantId,omitempty"`
+	Action        string `gorm:"column:action;type:varchar(50);not null" json:"action"`
+	EntityType    string `gorm:"column:entity_type;type:varchar(100);not null;index:idx_audit_logs_entity" json:"entityType"`
+	EntityID      string `gorm:"column:entity_id;type:varchar(255);not null;index:idx_audit_logs_entity" json:"entityId"`
+	// Changes maps every changed field to its value before and after the mutation.
+	Changes   map[string]AuditChange `gorm:"column:changes;type:jsonb;serializer:json" json:"changes"`
+	RequestID string                 `gorm:"column:request_id;type:varchar(128);not null;default:'';index" json:"requestId,omitempty"`
+	IP        string                 `gorm:"column:ip;type:varchar(45);not null;default:''" json:"ip,omitempty"`
+	CreatedAt time.Time              `gorm:"column:created_at;type:timestamptz;not null;index" json:"createdAt"`
+}
+
+// AuditChange is the value of a field before and after a mutation, nil when the field did not exist.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// TableName for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogs model
+type AuditLogs []AuditLog
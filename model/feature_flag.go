@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// FeatureFlag model
+type FeatureFlag struct {
+	Name        string `gorm:"primaryKey;column:name;type:varchar(100);not null" json:"name"`
+	Description string `gorm:"column:description;type:varchar(255);not null;default:''" json:"description"`
+	Enabled     bool   `gorm:"column:enabled;not null;default:false" json:"enabled"`
+	// Percentage is the share of the users not targeted below the flag is rolled out to, 0 to 100.
+	Percentage int      `gorm:"column:percentage;not null;default:0" json:"percentage"`
+	UserIDs    []string `gorm:"column:user_ids;type:jsonb;serializer:json" json:"userIds"`
+	Roles      []string `gorm:"column:roles;type:jsonb;serializer:json" json:"roles"`
+	TenantIDs  []string `gorm:"column:tenant_ids;type:jsonb;serializer:json" json:"tenantIds"`
+
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamptz;not null" json:"createdAt"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamptz;not null" json:"updatedAt"`
+	UpdatedBy string    `gorm:"column:updated_by;type:varchar(255);not null;default:''" json:"updatedBy"`
+}
+
+// TableName for FeatureFlag model
+func (FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// FeatureFlags model
+type FeatureFlags []FeatureFlag
@@ -0,0 +1,124 @@
+package apperror
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+)
+
+// Kind classifies a domain error and decides its HTTP status.
+type Kind string
+
+const (
+	KindNotFound    Kind = "not_found"
+	KindConflict    Kind = "conflict"
+	KindForbidden   Kind = "forbidden"
+	KindValidation  Kind = "validation"
+	KindUnavailable Kind = "unavailable"
+	KindInternal    Kind = "internal"
+)
+
+// Error is a domain error carrying the contract status code returned to the client.
+type Error struct {
+	Kind    Kind
+	Code    contract.StatusCode
+	Message string
+	Data    interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus returns the HTTP status of the error kind.
+func (e *Error) HTTPStatus() int {
+	switch e.Kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Wrap attaches the underlying cause to the error.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// WithData attaches details returned to the client, for example validation errors.
+func (e *Error) WithData(data interface{}) *Error {
+	e.Data = data
+	return e
+}
+
+func New(kind Kind, code contract.StatusCode, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func NotFound(code contract.StatusCode, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code contract.StatusCode, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Forbidden(code contract.StatusCode, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Validation(code contract.StatusCode, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unavailable(code contract.StatusCode, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+func Internal(code contract.StatusCode, message string) *Error {
+	return New(KindInternal, code, message)
+}
+
+// IsKind reports whether any error in err's chain is a domain error of the given kind.
+func IsKind(err error, kind Kind) bool {
+	_, ok := AsKind(err, kind)
+	return ok
+}
+
+// AsKind returns the first domain error of the given kind in err's chain, including the domain
+// errors wrapped by other domain errors.
+func AsKind(err error, kind Kind) (*Error, bool) {
+	for err != nil {
+		var appErr *Error
+		if !errors.As(err, &appErr) {
+			return nil, false
+		}
+		if appErr.Kind == kind {
+			return appErr, true
+		}
+		err = appErr.Err
+	}
+	return nil, false
+}
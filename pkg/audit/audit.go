@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type clientIPContextKey struct{}
+
+func NewAudit(opts *Opts) *Service {
+	return &Service{
+		logger: opts.Logger,
+		db:     opts.DB,
+	}
+}
+
+// Record stores the audit log of a mutation in the transaction attached to ctx.
+func (s *Service) Record(ctx context.Context, entry Entry) error {
+	l := logger.WithID(s.logger, ContextName, "Record")
+
+	log, err := NewLog(ctx, entry)
+	if err != nil {
+		l.Error("Cannot compute the audit changes", zap.String("entityType", entry.EntityType), zap.Error(err))
+		return err
+	}
+
+	return s.db.GetTransaction(ctx).Create(log).Error
+}
+
+// List returns a page of audit logs matching the filter, most recent first.
+func (s *Service) List(ctx context.Context, filter Filter) ([]model.AuditLog, int64, error) {
+	tx := s.db.GetTransaction(ctx).Model(&model.AuditLog{})
+	if filter.ActorID != "" {
+		tx = tx.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		tx = tx.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		tx = tx.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		tx = tx.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		tx = tx.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		tx = tx.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		tx = tx.Where("created_at < ?", *filter.To)
+	}
+
+	// a new session lets the count and the page query share the conditions
+	tx = tx.Session(&gorm.Session{})
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	logs := []model.AuditLog{}
+	err := tx.Order("created_at desc").Order("id").
+		Offset(utils.CalculatePageSkip(filter.Page, filter.PageSize)).
+		Limit(filter.PageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// NewLog returns the audit log of a mutation, with the actor, request ID and IP read from ctx.
+func NewLog(ctx context.Context, entry Entry) (*model.AuditLog, error) {
+	changes, err := Diff(entry.Before, entry.After)
+	if err != nil {
+		return nil, err
+	}
+
+	log := &model.AuditLog{
+		ID:         uuid.NewString(),
+		Action:     string(entry.Action),
+		EntityType: entry.EntityType,
+		EntityID:   entry.EntityID,
+		Changes:    changes,
+		RequestID:  middleware.RequestIDFromContext(ctx),
+		IP:         ClientIPFromContext(ctx),
+		CreatedAt:  time.Now(),
+	}
+	if user, ok := middleware.UserFromContext(ctx); ok {
+		log.ActorID = user.UserID
+		log.ActorTenantID = user.TenantID
+	}
+	return log, nil
+}
+
+// Diff returns the fields of the JSON representations of before and after that differ. Either
+// value may be nil, for example before a creation.
+func Diff(before interface{}, after interface{}) (map[string]model.AuditChange, error) {
+	beforeFields, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]model.AuditChange{}
+	for name, value := range beforeFields {
+		if afterValue, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, afterValue) {
+			changes[name] = model.AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = model.AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// toFields returns the top level fields of the JSON representation of value.
+func toFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Middleware stores the client IP in the user context for the audit logs.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.SetUserContext(WithClientIP(c.UserContext(), c.IP()))
+		return c.Next()
+	}
+}
+
+// WithClientIP returns a copy of ctx carrying the client IP.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPContextKey{}, ip)
+}
+
+// ClientIPFromContext returns the client IP carried by ctx.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey{}).(string)
+	return ip
+}
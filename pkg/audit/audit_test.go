@@ -0,0 +1,136 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type book struct {
+	Title  string `json:"title"`
+	Author string `json:"author"`
+	Pages  int    `json:"pages,omitempty"`
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := audit.Diff(nil, &book{Title: "Dune", Author: "Herbert"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.AuditChange{
+		"title":  {After: "Dune"},
+		"author": {After: "Herbert"},
+	}, changes)
+
+	changes, err = audit.Diff(book{Title: "Dune", Author: "Herbert"}, &book{Title: "Dune Messiah", Author: "Herbert", Pages: 256})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.AuditChange{
+		"title": {Before: "Dune", After: "Dune Messiah"},
+		"pages": {After: float64(256)},
+	}, changes)
+
+	changes, err = audit.Diff(&book{Title: "Dune", Author: "Herbert"}, (*book)(nil))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]model.AuditChange{
+		"title":  {Before: "Dune"},
+		"author": {Before: "Herbert"},
+	}, changes)
+}
+
+func TestNewLog(t *testing.T) {
+	ctx := middleware.WithUser(context.Background(), &middleware.AuthUserData{UserID: "user-1", TenantID: "tenant-1"})
+	ctx = middleware.WithRequestID(ctx, "request-1")
+	ctx = audit.WithClientIP(ctx, "10.0.0.1")
+
+	log, err := audit.NewLog(ctx, audit.Entry{
+		Action:     audit.ActionUpdate,
+		EntityType: "book",
+		EntityID:   "book-1",
+		Before:     book{Title: "Dune"},
+		After:      book{Title: "Dune Messiah"},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, log.ID)
+	assert.Equal(t, "user-1", log.ActorID)
+	assert.Equal(t, "tenant-1", log.ActorTenantID)
+	assert.Equal(t, "update", log.Action)
+	assert.Equal(t, "book", log.EntityType)
+	assert.Equal(t, "book-1", log.EntityID)
+	assert.Equal(t, "request-1", log.RequestID)
+	assert.Equal(t, "10.0.0.1", log.IP)
+	assert.Equal(t, map[string]model.AuditChange{"title": {Before: "Dune", After: "Dune Messiah"}}, log.Changes)
+}
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(audit.Middleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(audit.ClientIPFromContext(c.UserContext()))
+	})
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	assert.Equal(t, "0.0.0.0", string(body[:n]))
+}
+
+type fakeService struct {
+	filter audit.Filter
+}
+
+func (s *fakeService) Record(context.Context, audit.Entry) error { return nil }
+
+func (s *fakeService) List(_ context.Context, filter audit.Filter) ([]model.AuditLog, int64, error) {
+	s.filter = filter
+	return []model.AuditLog{{ID: "log-1", Action: "delete"}}, 21, nil
+}
+
+func TestHandler_List(t *testing.T) {
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	service := &fakeService{}
+	h := audit.NewHandler(&audit.HandlerOpts{Logger: zap.NewNop(), Service: service, Validator: v})
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/admin/audit-logs", h.List)
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/admin/audit-logs?action=delete&entity_type=book&from=2026-01-01T00:00:00Z&page=2", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Data []model.AuditLog `json:"data"`
+		Meta struct {
+			Page      int `json:"page"`
+			TotalData int `json:"totalData"`
+			TotalPage int `json:"totalPage"`
+		} `json:"meta"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Len(t, body.Data, 1)
+	assert.Equal(t, 2, body.Meta.Page)
+	assert.Equal(t, 21, body.Meta.TotalData)
+	assert.Equal(t, 2, body.Meta.TotalPage)
+
+	assert.Equal(t, "delete", service.filter.Action)
+	assert.Equal(t, "book", service.filter.EntityType)
+	assert.Equal(t, 2, service.filter.Page)
+	assert.Equal(t, 20, service.filter.PageSize)
+	require.NotNil(t, service.filter.From)
+	assert.True(t, service.filter.From.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Nil(t, service.filter.To)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodGet, "/admin/audit-logs?action=read", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+)
+
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:    opts.Logger,
+		service:   opts.Service,
+		validator: opts.Validator,
+	}
+}
+
+// List returns a page of audit logs matching the query filters.
+func (h *Handler) List(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "List")
+
+	// bind request
+	req := &RequestAuditLogList{
+		Page:     1,
+		PageSize: 20,
+	}
+	if err := binding.BindModel(l, c, req, binding.BindFromQuery()); err != nil {
+		return err
+	}
+
+	// validate request
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, req, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	filter := Filter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		EntityType: req.EntityType,
+		EntityID:   req.EntityID,
+		RequestID:  req.RequestID,
+		From:       parseTime(req.From),
+		To:         parseTime(req.To),
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+	}
+	logs, total, err := h.service.List(c.UserContext(), filter)
+	if err != nil {
+		return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+	return wrapper.Send(c, wrapper.ResponsePagination(req.Page, req.PageSize, len(logs), int(total), logs, nil))
+}
+
+// parseTime parses a validated RFC 3339 time.
+func parseTime(value *string) *time.Time {
+	if value == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"go.uber.org/zap"
+)
+
+const ContextName = "Components.Audit"
+
+// Action is the kind of mutation recorded.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Entry describes a mutation. Before is nil for a creation and After is nil for a deletion.
+type Entry struct {
+	Action     Action
+	EntityType string
+	EntityID   string
+	Before     interface{}
+	After      interface{}
+}
+
+// Filter selects the audit logs returned by List. Empty fields do not filter.
+type Filter struct {
+	ActorID    string
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	// From and To bound the creation time, From inclusive and To exclusive.
+	From *time.Time
+	To   *time.Time
+
+	Page     int
+	PageSize int
+}
+
+// Opts represents the options for configuring the audit service.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// DB stores the audit logs in the transaction of the recorded mutation.
+	DB database.IDBService
+}
+
+// IAuditService represents the interface for the audit service.
+type IAuditService interface {
+	// Record stores the audit log of a mutation, in the transaction attached to ctx when there is
+	// one, so the log is only kept when the mutation is committed. The actor, request ID and IP
+	// are read from ctx.
+	//
+	// Parameters:
+	//   - ctx: context, carrying the transaction, the user set by the JWT or Basic Auth middleware and the request
+	//   - entry: the mutation
+	//
+	// Returns:
+	//   - error: error
+	Record(ctx context.Context, entry Entry) error
+
+	// List returns a page of audit logs matching the filter, most recent first.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - filter: filter and page
+	//
+	// Returns:
+	//   - []model.AuditLog: audit logs of the page
+	//   - int64: total number of matching audit logs
+	//   - error: error
+	List(ctx context.Context, filter Filter) ([]model.AuditLog, int64, error)
+}
+
+// Service represents the audit service.
+type Service struct {
+	logger *zap.Logger
+	db     database.IDBService
+}
+
+// HandlerOpts represents the options for configuring the admin handler.
+type HandlerOpts struct {
+	Logger    *zap.Logger
+	Service   IAuditService
+	Validator validator.IValidatorService
+}
+
+// Handler serves the admin endpoint querying the audit logs.
+type Handler struct {
+	logger    *zap.Logger
+	service   IAuditService
+	validator validator.IValidatorService
+}
+
+// RequestAuditLogList is the query of the list endpoint.
+type RequestAuditLogList struct {
+	ActorID    string  `query:"actor_id" validate:"max=255"`
+	Action     string  `query:"action" validate:"omitempty,oneof=create update delete"`
+	EntityType string  `query:"entity_type" validate:"max=100"`
+	EntityID   string  `query:"entity_id" validate:"max=255"`
+	RequestID  string  `query:"request_id" validate:"max=128"`
+	From       *string `query:"from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	To         *string `query:"to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00,after_field=From"`
+	Page       int     `query:"page" validate:"required,min=1"`
+	PageSize   int     `query:"page_size" validate:"required,min=1,max=100"`
+}
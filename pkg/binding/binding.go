@@ -1,12 +1,19 @@
 package binding
 
 import (
+	"encoding/json"
+	"errors"
+	"mime/multipart"
 	"net/http"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
 	"github.com/Alwanly/go-codebase/pkg/logger"
 	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
 	"github.com/Alwanly/go-codebase/pkg/wrapper"
 
 	"github.com/gofiber/fiber/v2"
@@ -15,11 +22,26 @@ import (
 
 const ContextName = "Binding"
 
+var (
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
+)
+
 type (
 	Binder struct {
 		l   *zap.Logger
 		ctx *fiber.Ctx
 		m   interface{}
+
+		// bound are the sources that bound the model, in order
+		bound []boundSource
+	}
+
+	// boundSource is a source that bound the model, with the struct tag naming the fields in it.
+	boundSource struct {
+		tag string
+		// has reports whether the request has a value of the name in the source
+		has func(name string) bool
 	}
 
 	Source func(*Binder) error
@@ -27,6 +49,8 @@ type (
 	ModelBindingError struct {
 		Code         int
 		ResponseBody wrapper.JSONResult
+		// Errors describes the values that could not be bound.
+		Errors BindingErrors
 	}
 )
 
@@ -34,13 +58,24 @@ func (e *ModelBindingError) Error() string {
 	return "Failed to bind request body"
 }
 
+func (e *ModelBindingError) ErrorResponse() wrapper.JSONResult {
+	return e.ResponseBody
+}
+
 func BindFromBody() Source {
 	return func(b *Binder) error {
 		if err := b.ctx.BodyParser(b.m); err != nil {
 			b.l.Debug("Error when binding from body", zap.Error(err))
-			return err
+			return newBodyBindingErrors(b.ctx, err)
 		}
 
+		contentType := strings.ToLower(b.ctx.Get(fiber.HeaderContentType))
+		switch {
+		case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+			b.markBound("json", jsonHas(b.ctx.Body()))
+		case strings.HasPrefix(contentType, fiber.MIMEApplicationForm), strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+			b.markBound("form", formHas(b.ctx))
+		}
 		return nil
 	}
 }
@@ -49,9 +84,12 @@ func BindFromQuery() Source {
 	return func(b *Binder) error {
 		if err := b.ctx.QueryParser(b.m); err != nil {
 			b.l.Debug("Error when binding from query string", zap.Error(err))
-			return err
+			return newBindingErrors(SourceQuery, err, queryValues(b.ctx))
 		}
 
+		b.markBound("query", func(name string) bool {
+			return b.ctx.Context().QueryArgs().Has(name)
+		})
 		return nil
 	}
 }
@@ -60,9 +98,33 @@ func BindFromParams() Source {
 	return func(b *Binder) error {
 		if err := b.ctx.ParamsParser(b.m); err != nil {
 			b.l.Debug("Error when binding from path params", zap.Error(err))
-			return err
+			return newBindingErrors(SourceParams, err, singleValue(b.ctx.Params))
+		}
+
+		b.markBound("params", func(name string) bool {
+			return slices.Contains(b.ctx.Route().Params, name)
+		})
+		return nil
+	}
+}
+
+// BindFromMultipart binds the values of a multipart form like BindFromBody, and its files to the
+// *multipart.FileHeader and []*multipart.FileHeader fields with a form tag.
+func BindFromMultipart() Source {
+	return func(b *Binder) error {
+		form, err := b.ctx.MultipartForm()
+		if err != nil {
+			b.l.Debug("Error when reading multipart form", zap.Error(err))
+			return BindingErrors{BindingError{Source: SourceBody, Expected: fiber.MIMEMultipartForm}.withMessage(MessageInvalidMultipart)}
+		}
+
+		if err := b.ctx.BodyParser(b.m); err != nil {
+			b.l.Debug("Error when binding from multipart form", zap.Error(err))
+			return newBindingErrors(SourceBody, err, formValues(b.ctx))
 		}
 
+		bindFiles(reflect.Indirect(reflect.ValueOf(b.m)), form.File)
+		b.markBound("form", formHas(b.ctx))
 		return nil
 	}
 }
@@ -71,9 +133,12 @@ func BindFromHeaders() Source {
 	return func(b *Binder) error {
 		if err := b.ctx.ReqHeaderParser(b.m); err != nil {
 			b.l.Debug("Error when binding from request headers", zap.Error(err))
-			return err
+			return newBindingErrors(SourceHeaders, err, singleValue(b.ctx.Get))
 		}
 
+		b.markBound("reqHeader", func(name string) bool {
+			return len(b.ctx.Request().Header.Peek(name)) > 0
+		})
 		return nil
 	}
 }
@@ -93,14 +158,29 @@ func BindModel(log *zap.Logger, c *fiber.Ctx, m interface{}, sources ...Source)
 	for _, source := range sources {
 		// execute binding
 		if err := source(binder); err != nil {
-			result := wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeBindingFailed, contract.ErrorValidatePayload, nil)
+			// custom sources may return plain errors
+			var bindingErrs BindingErrors
+			var data interface{}
+			if errors.As(err, &bindingErrs) {
+				bindingErrs = bindingErrs.Localize(i18n.Locale(c)).redact()
+				data = bindingErrs
+			}
+
+			result := wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeBindingFailed, contract.ErrorValidatePayload, data)
 			return &ModelBindingError{
 				Code:         result.Code,
 				ResponseBody: result,
+				Errors:       bindingErrs,
 			}
 		}
 	}
 
+	// normalize the bound values with the mod tags, before they are validated
+	normalize(reflect.ValueOf(m))
+
+	// the validation errors name the fields after the source they were bound from
+	c.SetUserContext(validator.WithFieldNames(c.UserContext(), validator.FieldNames(reflect.TypeOf(m), binder.boundFrom)))
+
 	// check if the target has AuthUserData field and set it
 	if authUser, ok := c.Locals(middleware.LocalTokenKey).(*middleware.AuthUserData); ok {
 		dataField := reflect.Indirect(reflect.ValueOf(m)).FieldByName("AuthUserData")
@@ -111,3 +191,79 @@ func BindModel(log *zap.Logger, c *fiber.Ctx, m interface{}, sources ...Source)
 
 	return nil
 }
+
+// markBound records that the source of tag bound the model.
+func (b *Binder) markBound(tag string, has func(name string) bool) {
+	b.bound = append(b.bound, boundSource{tag: tag, has: has})
+}
+
+// boundFrom returns the tag of the last source that bound a value of the field, given its names by
+// tag, as the later sources overwrite the values of the earlier ones.
+func (b *Binder) boundFrom(names map[string]string) (string, bool) {
+	for i := len(b.bound) - 1; i >= 0; i-- {
+		source := b.bound[i]
+		if name, ok := names[source.tag]; ok && source.has(name) {
+			return source.tag, true
+		}
+	}
+	return "", false
+}
+
+// jsonHas reports whether the JSON object of the body has a key, matched case-insensitively like
+// encoding/json does.
+func jsonHas(body []byte) func(name string) bool {
+	var keys map[string]json.RawMessage
+	parsed := false
+	return func(name string) bool {
+		if !parsed {
+			_ = json.Unmarshal(body, &keys)
+			parsed = true
+		}
+		for key := range keys {
+			if strings.EqualFold(key, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// formHas reports whether the form body, URL encoded or multipart, has a value or file of a name.
+func formHas(c *fiber.Ctx) func(name string) bool {
+	return func(name string) bool {
+		if form, err := c.MultipartForm(); err == nil {
+			return len(form.Value[name]) > 0 || len(form.File[name]) > 0
+		}
+		return c.Context().PostArgs().Has(name)
+	}
+}
+
+// bindFiles sets the file fields of the struct from the uploaded files, by form tag.
+func bindFiles(v reflect.Value, files map[string][]*multipart.FileHeader) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		structField := v.Type().Field(i)
+		if structField.Anonymous && field.Kind() == reflect.Struct {
+			bindFiles(field, files)
+			continue
+		}
+		if !field.CanSet() {
+			continue
+		}
+
+		name := tagName(structField.Tag.Get("form"))
+		if name == "" || len(files[name]) == 0 {
+			continue
+		}
+		switch field.Type() {
+		case fileHeaderType:
+			field.Set(reflect.ValueOf(files[name][0]))
+		case fileHeadersType:
+			field.Set(reflect.ValueOf(files[name]))
+		}
+	}
+}
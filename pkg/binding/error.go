@@ -0,0 +1,302 @@
+package binding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	SourceBody    = "body"
+	SourceQuery   = "query"
+	SourceParams  = "params"
+	SourceHeaders = "headers"
+)
+
+// Messages of the binding errors, translated to the locale of the request by BindModel.
+const (
+	MessageInvalidType      = "{0} must be of type {1}"
+	MessageInvalidValue     = "{0} is invalid"
+	MessageBindFailed       = "Failed to bind the request {0}"
+	MessageInvalidJSON      = "Request body must be valid JSON"
+	MessageInvalidMultipart = "Request body must be a multipart form"
+)
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		MessageInvalidType:      "{0} harus bertipe {1}",
+		MessageInvalidValue:     "{0} tidak valid",
+		MessageBindFailed:       "Gagal membaca {0} permintaan",
+		MessageInvalidJSON:      "Isi permintaan harus berupa JSON yang valid",
+		MessageInvalidMultipart: "Isi permintaan harus berupa multipart form",
+	})
+}
+
+type (
+	// BindingError describes a request value that could not be bound to the model.
+	BindingError struct {
+		Source   string      `json:"source"`
+		Field    string      `json:"field"`
+		Expected string      `json:"expected,omitempty"`
+		Value    interface{} `json:"value"`
+		Message  string      `json:"message"`
+
+		// template and params build the message in another locale
+		template string
+		params   []string
+	}
+
+	// BindingErrors is returned by the sources failing to bind the request.
+	BindingErrors []BindingError
+)
+
+func (e BindingErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize returns the errors with their messages translated to the locale.
+func (e BindingErrors) Localize(locale string) BindingErrors {
+	localized := make(BindingErrors, len(e))
+	for i, err := range e {
+		if err.template != "" {
+			err.Message = i18n.Translate(locale, err.template, err.params...)
+		}
+		localized[i] = err
+	}
+	return localized
+}
+
+// redact returns the errors without the values of the secret fields, such as passwords.
+func (e BindingErrors) redact() BindingErrors {
+	for i := range e {
+		if validator.IsSecretField(e[i].Field) {
+			e[i].Value = nil
+		}
+	}
+	return e
+}
+
+// withMessage sets the message of the error from a template, in the default locale.
+func (e BindingError) withMessage(template string, params ...string) BindingError {
+	e.template = template
+	e.params = params
+	e.Message = i18n.Translate(i18n.DefaultLocale, template, params...)
+	return e
+}
+
+// newBindingErrors returns the structured errors of a form-like source, such as the query string,
+// from the conversion errors of the Fiber decoder. values returns the raw values of a field.
+func newBindingErrors(source string, err error, values func(key string) []string) BindingErrors {
+	result := BindingErrors{}
+	if decodeErrs := reflect.ValueOf(errors.Unwrap(err)); decodeErrs.Kind() == reflect.Map && decodeErrs.Type().Key().Kind() == reflect.String {
+		iter := decodeErrs.MapRange()
+		for iter.Next() {
+			result = append(result, conversionError(source, iter.Key().String(), iter.Value().Interface(), values))
+		}
+	}
+	if len(result) == 0 {
+		result = append(result, BindingError{Source: source}.withMessage(MessageBindFailed, source))
+	}
+
+	// the decoder errors are a map, sort them for stable responses
+	sort.Slice(result, func(i, j int) bool { return result[i].Field < result[j].Field })
+	return result
+}
+
+// conversionError returns the structured error of a field the Fiber decoder could not convert.
+func conversionError(source string, key string, err interface{}, values func(key string) []string) BindingError {
+	bindingErr := BindingError{
+		Source: source,
+		Field:  key,
+	}
+
+	// the conversion error type of the decoder is internal to Fiber, index is -1 for single values
+	index := -1
+	if v := reflect.Indirect(reflect.ValueOf(err)); v.Kind() == reflect.Struct {
+		if t, ok := fieldValue(v, "Type").(reflect.Type); ok && t != nil {
+			bindingErr.Expected = typeName(t)
+		}
+		if i, ok := fieldValue(v, "Index").(int); ok {
+			index = i
+		}
+	}
+
+	raw := values(key)
+	switch {
+	case index >= 0:
+		bindingErr.Field = fmt.Sprintf("%s[%d]", key, index)
+		if index < len(raw) {
+			bindingErr.Value = raw[index]
+		}
+	case len(raw) > 0:
+		bindingErr.Value = raw[0]
+	}
+
+	if bindingErr.Expected != "" {
+		return bindingErr.withMessage(MessageInvalidType, bindingErr.Field, bindingErr.Expected)
+	}
+	return bindingErr.withMessage(MessageInvalidValue, bindingErr.Field)
+}
+
+// fieldValue returns the value of the named struct field, or nil.
+func fieldValue(v reflect.Value, name string) interface{} {
+	field := v.FieldByName(name)
+	if !field.IsValid() || !field.CanInterface() {
+		return nil
+	}
+	return field.Interface()
+}
+
+// queryValues returns the values of a query string parameter.
+func queryValues(c *fiber.Ctx) func(key string) []string {
+	return func(key string) []string {
+		return byteValues(c.Context().QueryArgs().PeekMulti(key))
+	}
+}
+
+// formValues returns the values of a form field, URL encoded or multipart.
+func formValues(c *fiber.Ctx) func(key string) []string {
+	return func(key string) []string {
+		if form, err := c.MultipartForm(); err == nil {
+			return form.Value[key]
+		}
+		return byteValues(c.Context().PostArgs().PeekMulti(key))
+	}
+}
+
+// singleValue returns the value of a single valued source, such as the path params.
+func singleValue(value func(key string, defaultValue ...string) string) func(key string) []string {
+	return func(key string) []string {
+		return []string{value(key)}
+	}
+}
+
+func byteValues(raw [][]byte) []string {
+	values := make([]string, 0, len(raw))
+	for _, value := range raw {
+		values = append(values, string(value))
+	}
+	return values
+}
+
+// newBodyBindingErrors returns the structured errors of the body from the error of the Fiber body parser.
+func newBodyBindingErrors(c *fiber.Ctx, err error) BindingErrors {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := fieldPath(typeErr.Field)
+		if field == "" {
+			field = "$"
+		}
+		expected := typeName(typeErr.Type)
+		return BindingErrors{BindingError{
+			Source:   SourceBody,
+			Field:    field,
+			Expected: expected,
+			Value:    jsonValue(c.Body(), typeErr.Field, typeErr.Value),
+		}.withMessage(MessageInvalidType, field, expected)}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return BindingErrors{BindingError{Source: SourceBody, Expected: "JSON"}.withMessage(MessageInvalidJSON)}
+	}
+
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	if strings.HasPrefix(contentType, fiber.MIMEApplicationForm) || strings.HasPrefix(contentType, fiber.MIMEMultipartForm) {
+		return newBindingErrors(SourceBody, err, formValues(c))
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return BindingErrors{{Source: SourceBody, Message: fiberErr.Message}}
+	}
+	return BindingErrors{BindingError{Source: SourceBody}.withMessage(MessageBindFailed, SourceBody)}
+}
+
+// jsonValue returns the value at the dotted path of a JSON document, such as ratings.1, or fallback.
+func jsonValue(body []byte, path string, fallback interface{}) interface{} {
+	var value interface{}
+	if path == "" || json.Unmarshal(body, &value) != nil {
+		return fallback
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if value, ok = node[key]; !ok {
+				return fallback
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(node) {
+				return fallback
+			}
+			value = node[index]
+		default:
+			return fallback
+		}
+	}
+	return value
+}
+
+// fieldPath returns the path of a field from the dotted path of encoding/json, with the array
+// indexes in brackets, for example ratings[1] for ratings.1.
+func fieldPath(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, key := range strings.Split(path, ".") {
+		switch _, err := strconv.Atoi(key); {
+		case err == nil:
+			b.WriteString("[" + key + "]")
+		case i > 0:
+			b.WriteString("." + key)
+		default:
+			b.WriteString(key)
+		}
+	}
+	return b.String()
+}
+
+// typeName returns the JSON name of a Go type, for example "integer" for int.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "date-time"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array of " + typeName(t.Elem())
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return t.String()
+}
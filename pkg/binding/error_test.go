@@ -0,0 +1,131 @@
+package binding_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type requestSearch struct {
+	Page    int    `query:"page"`
+	Exact   bool   `query:"exact"`
+	ID      int    `params:"id"`
+	Version int    `reqHeader:"X-Version"`
+	Title   string `json:"title"`
+	Ratings []int  `json:"ratings"`
+	PIN     int    `query:"password_pin"`
+	Author  struct {
+		Age int `json:"age"`
+	} `json:"author"`
+}
+
+func newBindingErrorApp(sources ...binding.Source) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/search/:id", func(c *fiber.Ctx) error {
+		if err := binding.BindModel(zap.NewNop(), c, &requestSearch{}, sources...); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+	return app
+}
+
+func TestBindingErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		sources  []binding.Source
+		target   string
+		body     string
+		header   map[string]string
+		expected string
+	}{
+		{
+			name:     "query",
+			sources:  []binding.Source{binding.BindFromQuery()},
+			target:   "/search/1?page=two&exact=maybe",
+			expected: `[{"source":"query","field":"exact","expected":"boolean","value":"maybe","message":"exact must be of type boolean"},{"source":"query","field":"page","expected":"integer","value":"two","message":"page must be of type integer"}]`,
+		},
+		{
+			name:     "secret query",
+			sources:  []binding.Source{binding.BindFromQuery()},
+			target:   "/search/1?password_pin=hunter2",
+			expected: `[{"source":"query","field":"password_pin","expected":"integer","value":null,"message":"password_pin must be of type integer"}]`,
+		},
+		{
+			name:     "params",
+			sources:  []binding.Source{binding.BindFromParams()},
+			target:   "/search/abc",
+			expected: `[{"source":"params","field":"id","expected":"integer","value":"abc","message":"id must be of type integer"}]`,
+		},
+		{
+			name:     "headers",
+			sources:  []binding.Source{binding.BindFromHeaders()},
+			target:   "/search/1",
+			header:   map[string]string{"X-Version": "latest"},
+			expected: `[{"source":"headers","field":"X-Version","expected":"integer","value":"latest","message":"X-Version must be of type integer"}]`,
+		},
+		{
+			name:     "body type",
+			sources:  []binding.Source{binding.BindFromBody()},
+			target:   "/search/1",
+			body:     `{"title": 42}`,
+			header:   map[string]string{"Content-Type": "application/json"},
+			expected: `[{"source":"body","field":"title","expected":"string","value":42,"message":"title must be of type string"}]`,
+		},
+		{
+			name:     "nested body type",
+			sources:  []binding.Source{binding.BindFromBody()},
+			target:   "/search/1",
+			body:     `{"author": {"age": "old"}}`,
+			header:   map[string]string{"Content-Type": "application/json"},
+			expected: `[{"source":"body","field":"author.age","expected":"integer","value":"old","message":"author.age must be of type integer"}]`,
+		},
+		{
+			name:     "body array type",
+			sources:  []binding.Source{binding.BindFromBody()},
+			target:   "/search/1",
+			body:     `{"ratings": [5, "high"]}`,
+			header:   map[string]string{"Content-Type": "application/json"},
+			expected: `[{"source":"body","field":"ratings[1]","expected":"integer","value":"high","message":"ratings[1] must be of type integer"}]`,
+		},
+		{
+			name:     "body syntax",
+			sources:  []binding.Source{binding.BindFromBody()},
+			target:   "/search/1",
+			body:     `{"title":`,
+			header:   map[string]string{"Content-Type": "application/json"},
+			expected: `[{"source":"body","field":"","expected":"JSON","value":null,"message":"Request body must be valid JSON"}]`,
+		},
+		{
+			name:     "form body",
+			sources:  []binding.Source{binding.BindFromBody()},
+			target:   "/search/1",
+			body:     `ratings=5&ratings=high`,
+			header:   map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			expected: `[{"source":"body","field":"ratings[1]","expected":"integer","value":"high","message":"ratings[1] must be of type integer"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newBindingErrorApp(tt.sources...)
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.JSONEq(t, `{"statusCode":"000001","message":"Failed to validate payload","data":`+tt.expected+`}`, string(body))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package binding
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type (
+	// HandleOpts represents the options shared by the handlers created with Handle.
+	HandleOpts struct {
+		// Logger is the logger.
+		Logger *zap.Logger
+		// Validator validates the bound requests.
+		Validator validator.IValidatorService
+		// Docs collects the OpenAPI operations of the handlers. Optional.
+		Docs *Docs
+	}
+
+	// HandleOption configures a handler created with Handle.
+	HandleOption func(*handleConfig)
+
+	// Defaulter is implemented by requests setting their default values before binding.
+	Defaulter interface {
+		Defaults()
+	}
+
+	handleConfig struct {
+		operation *Operation
+	}
+)
+
+// WithOperation documents the handler in the OpenAPI document. The route must be named with the
+// operation ID, for example router.Get("/:id", handler).Name("books.get").
+func WithOperation(op Operation) HandleOption {
+	return func(cfg *handleConfig) {
+		cfg.operation = &op
+	}
+}
+
+// Handle returns a Fiber handler binding a new Req from the sources, validating it with the
+// validation messages in the language of the request, calling fn and sending its result.
+//
+// Binding and validation errors are returned to the Fiber error handler. Handle panics when Req
+// accepts the query DSL without implementing QueryRequest and having a query.Query field, or has
+// an unknown modifier in its mod tags.
+func Handle[Req any](opts *HandleOpts, sources []Source, fn func(ctx context.Context, req *Req) wrapper.JSONResult, options ...HandleOption) fiber.Handler {
+	// fail when the route is registered rather than on every request
+	if err := checkQueryRequest(new(Req)); err != nil {
+		panic(err)
+	}
+	if err := checkModifiers(reflect.TypeOf(new(Req))); err != nil {
+		panic(err)
+	}
+
+	cfg := &handleConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	if cfg.operation != nil && opts.Docs != nil {
+		opts.Docs.register(*cfg.operation, new(Req))
+	}
+
+	return func(c *fiber.Ctx) error {
+		l := logger.WithID(opts.Logger, ContextName, "Handle")
+
+		// bind request
+		req := new(Req)
+		if defaulter, ok := any(req).(Defaulter); ok {
+			defaulter.Defaults()
+		}
+		if err := BindModel(l, c, req, sources...); err != nil {
+			return err
+		}
+
+		// validate request
+		if err := validator.ValidateModelContext(c.UserContext(), l, opts.Validator, req, RequestLocale(c)); err != nil {
+			return err
+		}
+
+		return wrapper.Send(c, fn(c.UserContext(), req))
+	}
+}
+
+// Sources returns its arguments, to shorten the calls to Handle.
+func Sources(sources ...Source) []Source {
+	return sources
+}
+
+// RequestLocale returns the supported locale negotiated from the Accept-Language header of the
+// request, for example "id" for "fr,id-ID;q=0.8", or the default locale.
+func RequestLocale(c *fiber.Ctx) string {
+	return i18n.Locale(c)
+}
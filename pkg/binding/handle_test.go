@@ -0,0 +1,225 @@
+package binding_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type requestItemUpdate struct {
+	ID       string `params:"id" validate:"required"`
+	Name     string `json:"name" validate:"required,min=3"`
+	Page     int    `query:"page" validate:"required,min=1"`
+	SortBy   string `query:"sort_by" validate:"oneof=name date"`
+	Internal string `json:"-"`
+
+	AuthUserData *middleware.AuthUserData
+}
+
+func (r *requestItemUpdate) Defaults() {
+	r.Page = 1
+	r.SortBy = "name"
+}
+
+type responseItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newHandleApp(t *testing.T, docs *binding.Docs) *fiber.App {
+	t.Helper()
+
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	opts := &binding.HandleOpts{Logger: zap.NewNop(), Validator: v, Docs: docs}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Put("/items/:id", binding.Handle(opts, binding.Sources(binding.BindFromParams(), binding.BindFromQuery(), binding.BindFromBody()),
+		func(ctx context.Context, req *requestItemUpdate) wrapper.JSONResult {
+			return wrapper.ResponseSuccess(http.StatusOK, responseItem{ID: req.ID, Name: req.Name + ":" + req.SortBy})
+		},
+		binding.WithOperation(binding.Operation{
+			ID:        "items.update",
+			Summary:   "Update an item",
+			Tags:      []string{"items"},
+			Security:  []string{"BearerAuth"},
+			Responses: map[int]binding.Response{http.StatusOK: {Body: responseItem{}}},
+		}),
+	)).Name("items.update")
+	return app
+}
+
+func TestHandle(t *testing.T) {
+	app := newHandleApp(t, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/items/42", strings.NewReader(`{"name":"book"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"statusCode":"000000","message":"Success","data":{"id":"42","name":"book:name"}}`, string(body))
+
+	// validation errors are sent by the error handler
+	req = httptest.NewRequest(http.MethodPut, "/items/42?sort_by=size", strings.NewReader(`{"name":"b"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	resp, _ = app.Test(req)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"statusCode":"000002"`)
+	assert.Contains(t, string(body), "name must be at least 3 characters in length")
+	assert.Contains(t, string(body), "sort_by must be one of [name date]")
+
+	// binding errors too
+	req = httptest.NewRequest(http.MethodPut, "/items/42", strings.NewReader(`{`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandle_Locale(t *testing.T) {
+	app := newHandleApp(t, nil)
+
+	// validation messages and contract messages follow Accept-Language
+	req := httptest.NewRequest(http.MethodPut, "/items/42", strings.NewReader(`{"name":"b"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "fr, id-ID;q=0.8")
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "Accept-Language, Accept", resp.Header.Get("Vary"))
+	assert.Contains(t, string(body), `"message":"Gagal memvalidasi data"`)
+	assert.Contains(t, string(body), "panjang minimal name adalah 3 karakter")
+
+	// binding errors too
+	req = httptest.NewRequest(http.MethodPut, "/items/42?page=two", strings.NewReader(`{"name":"book"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "id")
+	resp, _ = app.Test(req)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"message":"page harus bertipe integer"`)
+}
+
+type requestLookup struct {
+	Name string `json:"name" query:"q" validate:"required,min=3"`
+}
+
+func TestHandle_FieldNamedAfterSource(t *testing.T) {
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	opts := &binding.HandleOpts{Logger: zap.NewNop(), Validator: v}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/lookup", binding.Handle(opts, binding.Sources(binding.BindFromBody(), binding.BindFromQuery()),
+		func(ctx context.Context, req *requestLookup) wrapper.JSONResult {
+			return wrapper.ResponseSuccess(http.StatusOK, nil)
+		}))
+
+	send := func(target string, body string) string {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, _ := app.Test(req)
+		raw, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		return string(raw)
+	}
+
+	// bound from the query string
+	body := send("/lookup?q=ab", `{}`)
+	assert.Contains(t, body, `"field":"q"`)
+	assert.Contains(t, body, "q must be at least 3 characters in length")
+
+	// bound from the body
+	body = send("/lookup", `{"name":"ab"}`)
+	assert.Contains(t, body, `"field":"name"`)
+	assert.Contains(t, body, "name must be at least 3 characters in length")
+
+	// the query string is bound last and overwrites the body
+	body = send("/lookup?q=ab", `{"name":"abcd"}`)
+	assert.Contains(t, body, `"field":"q"`)
+
+	// not bound at all, the first name by priority
+	body = send("/lookup", `{}`)
+	assert.Contains(t, body, `"field":"name"`)
+	assert.Contains(t, body, "name is a required field")
+}
+
+func TestDocs_Spec(t *testing.T) {
+	docs := binding.NewDocs(&binding.DocsOpts{Title: "codebase", Version: "1.0.0"})
+	app := newHandleApp(t, docs)
+	app.Get("/openapi.json", docs.Handler(app))
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]map[string]struct {
+			OperationID string                `json:"operationId"`
+			Tags        []string              `json:"tags"`
+			Security    []map[string][]string `json:"security"`
+			Parameters  []struct {
+				Name     string                 `json:"name"`
+				In       string                 `json:"in"`
+				Required bool                   `json:"required"`
+				Schema   map[string]interface{} `json:"schema"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]interface{} `json:"properties"`
+						Required   []string               `json:"required"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Responses map[string]interface{} `json:"responses"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+
+	op, ok := spec.Paths["/items/{id}"]["put"]
+	require.True(t, ok)
+	assert.Equal(t, "items.update", op.OperationID)
+	assert.Equal(t, []string{"items"}, op.Tags)
+	assert.Equal(t, []map[string][]string{{"BearerAuth": {}}}, op.Security)
+	require.Len(t, op.Parameters, 3)
+	assert.Equal(t, "id", op.Parameters[0].Name)
+	assert.Equal(t, "path", op.Parameters[0].In)
+	assert.True(t, op.Parameters[0].Required)
+	assert.Equal(t, "integer", op.Parameters[1].Schema["type"])
+	assert.Equal(t, []interface{}{"name", "date"}, op.Parameters[2].Schema["enum"])
+
+	body := op.RequestBody.Content[fiber.MIMEApplicationJSON].Schema
+	assert.Contains(t, body.Properties, "name")
+	assert.NotContains(t, body.Properties, "Internal")
+	assert.Equal(t, []string{"name"}, body.Required)
+	assert.Contains(t, op.Responses, "200")
+}
+
+func TestRequestLocale(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(binding.RequestLocale(c))
+	})
+
+	for accept, locale := range map[string]string{"": "en", "id-ID,en;q=0.8": "id", "EN": "en", " fr;q=0.9, en": "en", "fr, id;q=0.5": "id", "en;q=0.5, id": "id", "fr": "en"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", accept)
+		resp, _ := app.Test(req)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, locale, string(body), accept)
+	}
+}
@@ -0,0 +1,176 @@
+package binding
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ModifierTag is the struct tag listing the modifiers of a field, e.g. mod:"trim,lower".
+const ModifierTag = "mod"
+
+// Modifier normalizes a bound string value, before the validation.
+type Modifier func(value string) string
+
+var (
+	modifiersMu sync.RWMutex
+	modifiers   = map[string]Modifier{
+		"trim":            strings.TrimSpace,
+		"lower":           strings.ToLower,
+		"upper":           strings.ToUpper,
+		"collapse_spaces": collapseSpaces,
+		"strip_html":      stripHTML,
+		"unicode_nfc":     norm.NFC.String,
+	}
+)
+
+// RegisterModifier registers a modifier for the mod tag, replacing the modifier with the same name.
+func RegisterModifier(name string, modifier Modifier) {
+	modifiersMu.Lock()
+	defer modifiersMu.Unlock()
+
+	modifiers[name] = modifier
+}
+
+// modifier returns the registered modifier. Unknown modifiers are programming errors and panic,
+// like unknown validation tags; Handle checks them when the route is registered.
+func modifier(name string) Modifier {
+	modifiersMu.RLock()
+	defer modifiersMu.RUnlock()
+
+	fn, ok := modifiers[name]
+	if !ok {
+		panic(fmt.Sprintf("binding: unknown modifier %q", name))
+	}
+	return fn
+}
+
+// hasModifier reports whether the modifier is registered.
+func hasModifier(name string) bool {
+	modifiersMu.RLock()
+	defer modifiersMu.RUnlock()
+
+	_, ok := modifiers[name]
+	return ok
+}
+
+// checkModifiers returns an error for the first unknown modifier in the mod tags of the struct
+// type, and of its nested structs.
+func checkModifiers(t reflect.Type) error {
+	return checkTypeModifiers(t, map[reflect.Type]bool{})
+}
+
+func checkTypeModifiers(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get(ModifierTag)
+		if tag == "" {
+			if err := checkTypeModifiers(field.Type, visited); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, name := range strings.Split(tag, ",") {
+			if !hasModifier(strings.TrimSpace(name)) {
+				return fmt.Errorf("binding: unknown modifier %q on %s.%s", strings.TrimSpace(name), t, field.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// normalize applies the modifiers of the mod tags to the string, *string and []string fields of
+// the struct, and of its nested structs.
+func normalize(v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalize(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Field(i)
+			structField := v.Type().Field(i)
+			if !structField.IsExported() && !structField.Anonymous {
+				continue
+			}
+			if tag := structField.Tag.Get(ModifierTag); tag != "" && field.CanSet() {
+				modify(field, strings.Split(tag, ","))
+				continue
+			}
+			normalize(field)
+		}
+	}
+}
+
+// modify applies the modifiers in order to a string, *string or []string value.
+func modify(v reflect.Value, names []string) {
+	switch {
+	case v.Kind() == reflect.String:
+		value := v.String()
+		for _, name := range names {
+			value = modifier(strings.TrimSpace(name))(value)
+		}
+		v.SetString(value)
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		modify(v.Elem(), names)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < v.Len(); i++ {
+			modify(v.Index(i), names)
+		}
+	}
+}
+
+// collapseSpaces trims the value and replaces its runs of whitespace with a single space.
+func collapseSpaces(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// stripHTML removes the HTML tags, comments and the content of the script and style elements.
+// The text is kept as sent, its character references are not decoded.
+func stripHTML(value string) string {
+	var b bytes.Buffer
+	tokenizer := html.NewTokenizer(strings.NewReader(value))
+	skip := ""
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			if skip == "" {
+				b.Write(tokenizer.Raw())
+			}
+		case html.StartTagToken:
+			if name, _ := tokenizer.TagName(); skip == "" && (string(name) == "script" || string(name) == "style") {
+				skip = string(name)
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == skip {
+				skip = ""
+			}
+		}
+	}
+}
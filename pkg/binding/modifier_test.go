@@ -0,0 +1,144 @@
+package binding_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type requestAuthor struct {
+	Name string `json:"name" mod:"collapse_spaces"`
+}
+
+type requestNormalized struct {
+	Title    string          `json:"title" mod:"strip_html,collapse_spaces"`
+	Email    string          `json:"email" mod:"trim,lower"`
+	Code     string          `query:"code" mod:"trim,reverse"`
+	Subtitle *string         `json:"subtitle" mod:"trim"`
+	Tags     []string        `json:"tags" mod:"trim,lower"`
+	Name     string          `json:"name" mod:"unicode_nfc"`
+	Authors  []requestAuthor `json:"authors"`
+	Raw      string          `json:"raw"`
+}
+
+func TestBindModel_Modifiers(t *testing.T) {
+	binding.RegisterModifier("reverse", func(value string) string {
+		runes := []rune(value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes)
+	})
+
+	var model requestNormalized
+	app := fiber.New()
+	app.Post("/test", func(c *fiber.Ctx) error {
+		model = requestNormalized{}
+		if err := binding.BindModel(zap.NewNop(), c, &model, binding.BindFromBody(), binding.BindFromQuery()); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	body := `{
+		"title": "  The <b>Go</b>\n  Programming <script>alert(1)</script>Language ",
+		"email": " Reader@Example.COM ",
+		"subtitle": "  2nd edition ",
+		"tags": [" Go ", "PROGRAMMING"],
+		"name": "Cafe\u0301",
+		"authors": [{"name": " Alan  Donovan "}, {"name": "Brian   Kernighan"}],
+		"raw": "  kept  "
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/test?code=%20cba%20", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, "The Go Programming Language", model.Title)
+	assert.Equal(t, "reader@example.com", model.Email)
+	assert.Equal(t, "abc", model.Code)
+	assert.Equal(t, "2nd edition", *model.Subtitle)
+	assert.Equal(t, []string{"go", "programming"}, model.Tags)
+	// the combining accent is composed
+	assert.Equal(t, "Caf\u00e9", model.Name)
+	assert.Equal(t, []requestAuthor{{Name: "Alan Donovan"}, {Name: "Brian Kernighan"}}, model.Authors)
+	assert.Equal(t, "  kept  ", model.Raw)
+}
+
+func TestBindModel_UnknownModifier(t *testing.T) {
+	type requestUnknown struct {
+		Title string `json:"title" mod:"shout"`
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(middleware.PanicRecovery(middleware.PanicRecoveryOpts{Logger: zap.NewNop()}))
+	app.Post("/test", func(c *fiber.Ctx) error {
+		return binding.BindModel(zap.NewNop(), c, &requestUnknown{}, binding.BindFromBody())
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"title":"Dune"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
+
+func TestHandle_UnknownModifierAtRegistration(t *testing.T) {
+	type requestAuthorName struct {
+		Name string `json:"name" mod:"trim"`
+	}
+	type requestUnknown struct {
+		Title   string              `json:"title" mod:"trim"`
+		Authors []requestAuthorName `json:"authors"`
+		Note    string              `json:"note" mod:"trim, shout"`
+	}
+
+	opts := &binding.HandleOpts{Logger: zap.NewNop()}
+	assert.PanicsWithError(t, `binding: unknown modifier "shout" on binding_test.requestUnknown.Note`, func() {
+		binding.Handle[requestUnknown](opts, binding.Sources(binding.BindFromBody()), nil)
+	})
+	assert.NotPanics(t, func() {
+		binding.Handle[requestAuthorName](opts, binding.Sources(binding.BindFromBody()), nil)
+	})
+}
+
+func TestHandle_ModifiersBeforeValidation(t *testing.T) {
+	type requestTitle struct {
+		Title string `json:"title" mod:"trim" validate:"required,min=3"`
+	}
+
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	opts := &binding.HandleOpts{Logger: zap.NewNop(), Validator: v}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/test", binding.Handle(opts, binding.Sources(binding.BindFromBody()), func(ctx context.Context, req *requestTitle) wrapper.JSONResult {
+		return wrapper.ResponseSuccess(http.StatusOK, req.Title)
+	}))
+
+	// the whitespace does not count towards the length
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"title":"  ab   "}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"value":"ab"`)
+
+	req = httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(`{"title":"  Dune "}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ = app.Test(req)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"data":"Dune"`)
+}
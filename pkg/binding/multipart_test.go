@@ -0,0 +1,151 @@
+package binding_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type requestUpload struct {
+	ID          string                  `params:"id" validate:"required"`
+	Title       string                  `form:"title" validate:"required"`
+	Pages       int                     `form:"pages"`
+	Cover       *multipart.FileHeader   `form:"cover" validate:"required,file_max_size=1KB,file_mime=image/png"`
+	Attachments []*multipart.FileHeader `form:"attachments" validate:"max_files=2"`
+}
+
+type responseUpload struct {
+	Title       string `json:"title"`
+	Pages       int    `json:"pages"`
+	Cover       string `json:"cover"`
+	Attachments int    `json:"attachments"`
+}
+
+// newMultipart returns a multipart body with the given values and files, and its content type.
+func newMultipart(t *testing.T, values map[string]string, files map[string][][]byte) (io.Reader, string) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for name, value := range values {
+		require.NoError(t, w.WriteField(name, value))
+	}
+	for name, contents := range files {
+		for _, content := range contents {
+			part, err := w.CreateFormFile(name, name+".png")
+			require.NoError(t, err)
+			_, _ = part.Write(content)
+		}
+	}
+	require.NoError(t, w.Close())
+	return body, w.FormDataContentType()
+}
+
+func newUploadApp(t *testing.T, docs *binding.Docs) *fiber.App {
+	t.Helper()
+
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	opts := &binding.HandleOpts{Logger: zap.NewNop(), Validator: v, Docs: docs}
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Put("/items/:id/cover", binding.Handle(opts, binding.Sources(binding.BindFromParams(), binding.BindFromMultipart()),
+		func(ctx context.Context, req *requestUpload) wrapper.JSONResult {
+			return wrapper.ResponseSuccess(http.StatusOK, responseUpload{
+				Title:       req.Title,
+				Pages:       req.Pages,
+				Cover:       req.Cover.Filename,
+				Attachments: len(req.Attachments),
+			})
+		},
+		binding.WithOperation(binding.Operation{ID: "items.cover"}),
+	)).Name("items.cover")
+	return app
+}
+
+func TestBindFromMultipart(t *testing.T) {
+	app := newUploadApp(t, nil)
+
+	body, contentType := newMultipart(t,
+		map[string]string{"title": "Dune", "pages": "412"},
+		map[string][][]byte{"cover": {pngHeader}, "attachments": {pngHeader, pngHeader}},
+	)
+	req := httptest.NewRequest(http.MethodPut, "/items/42/cover", body)
+	req.Header.Set("Content-Type", contentType)
+	resp, _ := app.Test(req)
+	raw, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"statusCode":"000000","message":"Success","data":{"title":"Dune","pages":412,"cover":"cover.png","attachments":2}}`, string(raw))
+}
+
+func TestBindFromMultipart_Errors(t *testing.T) {
+	app := newUploadApp(t, nil)
+
+	// the declared content type is ignored, the content is sniffed
+	body, contentType := newMultipart(t,
+		map[string]string{"title": "Dune"},
+		map[string][][]byte{"cover": {[]byte("<html>not an image</html>")}, "attachments": {pngHeader, pngHeader, pngHeader}},
+	)
+	req := httptest.NewRequest(http.MethodPut, "/items/42/cover", body)
+	req.Header.Set("Content-Type", contentType)
+	resp, _ := app.Test(req)
+	raw, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(raw), "cover must be a file of type image/png")
+	assert.Contains(t, string(raw), "attachments must contain at most 2 files")
+
+	// values are converted like the other sources
+	body, contentType = newMultipart(t, map[string]string{"title": "Dune", "pages": "many"}, map[string][][]byte{"cover": {pngHeader}})
+	req = httptest.NewRequest(http.MethodPut, "/items/42/cover", body)
+	req.Header.Set("Content-Type", contentType)
+	resp, _ = app.Test(req)
+	raw, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(raw), `"field":"pages"`)
+	assert.Contains(t, string(raw), `"value":"many"`)
+
+	// a body that is not a multipart form
+	req = httptest.NewRequest(http.MethodPut, "/items/42/cover", strings.NewReader(`{"title":"Dune"}`))
+	req.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	resp, _ = app.Test(req)
+	raw, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(raw), `"expected":"multipart/form-data"`)
+}
+
+func TestDocs_Multipart(t *testing.T) {
+	docs := binding.NewDocs(&binding.DocsOpts{Title: "items", Version: "1.0.0"})
+	app := newUploadApp(t, docs)
+
+	spec := map[string]interface{}{}
+	raw, err := json.Marshal(docs.Spec(app))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, &spec))
+
+	operation := spec["paths"].(map[string]interface{})["/items/{id}/cover"].(map[string]interface{})["put"].(map[string]interface{})
+	content := operation["requestBody"].(map[string]interface{})["content"].(map[string]interface{})
+	require.Contains(t, content, fiber.MIMEMultipartForm)
+	schema := content[fiber.MIMEMultipartForm].(map[string]interface{})["schema"].(map[string]interface{})
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "binary"}, properties["cover"])
+	assert.Equal(t, "array", properties["attachments"].(map[string]interface{})["type"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["title"])
+	assert.ElementsMatch(t, []interface{}{"title", "cover"}, schema["required"])
+}
@@ -0,0 +1,337 @@
+package binding
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type (
+	// Operation is the OpenAPI metadata of a handler.
+	Operation struct {
+		// ID identifies the operation, the route must be named with it.
+		ID          string
+		Summary     string
+		Description string
+		Tags        []string
+		// Security lists the security schemes of the operation, for example "BearerAuth".
+		Security []string
+		// Responses maps the HTTP status codes to their response.
+		Responses map[int]Response
+	}
+
+	// Response is an OpenAPI response. Body is an example value of the data of the wrapped response.
+	Response struct {
+		Description string
+		Body        interface{}
+	}
+
+	// DocsOpts represents the options for configuring the OpenAPI document.
+	DocsOpts struct {
+		Title   string
+		Version string
+	}
+
+	// Docs collects the operations registered by Handle and builds the OpenAPI document of the routes.
+	Docs struct {
+		title   string
+		version string
+
+		mu         sync.Mutex
+		operations map[string]documentedOperation
+	}
+
+	documentedOperation struct {
+		Operation
+		request reflect.Type
+	}
+)
+
+var pathParamPattern = regexp.MustCompile(`:([A-Za-z0-9_]+)\??`)
+
+func NewDocs(opts *DocsOpts) *Docs {
+	return &Docs{
+		title:      opts.Title,
+		version:    opts.Version,
+		operations: map[string]documentedOperation{},
+	}
+}
+
+func (d *Docs) register(op Operation, req interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.operations[op.ID] = documentedOperation{Operation: op, request: reflect.TypeOf(req).Elem()}
+}
+
+// Spec returns the OpenAPI 3 document of the named routes of app having a registered operation.
+func (d *Docs) Spec(app *fiber.App) map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	paths := map[string]interface{}{}
+	for _, route := range app.GetRoutes(true) {
+		op, ok := d.operations[route.Name]
+		if !ok || route.Method == fiber.MethodHead {
+			continue
+		}
+
+		path := pathParamPattern.ReplaceAllString(strings.TrimSuffix(route.Path, "/"), "{$1}")
+		if path == "" {
+			path = "/"
+		}
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = map[string]interface{}{}
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = op.spec(route.Method)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   d.title,
+			"version": d.version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"BearerAuth": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+				"BasicAuth":  map[string]interface{}{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+// Handler returns the Fiber handler serving the OpenAPI document of app.
+func (d *Docs) Handler(app *fiber.App) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(d.Spec(app))
+	}
+}
+
+func (op documentedOperation) spec(method string) map[string]interface{} {
+	spec := map[string]interface{}{
+		"operationId": op.ID,
+	}
+	if op.Summary != "" {
+		spec["summary"] = op.Summary
+	}
+	if op.Description != "" {
+		spec["description"] = op.Description
+	}
+	if len(op.Tags) > 0 {
+		spec["tags"] = op.Tags
+	}
+	if len(op.Security) > 0 {
+		security := []map[string][]string{}
+		for _, scheme := range op.Security {
+			security = append(security, map[string][]string{scheme: {}})
+		}
+		spec["security"] = security
+	}
+
+	parameters, body, contentType := requestSpec(op.request)
+	if len(parameters) > 0 {
+		spec["parameters"] = parameters
+	}
+	if body != nil && method != fiber.MethodGet && method != fiber.MethodDelete {
+		spec["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  map[string]interface{}{contentType: map[string]interface{}{"schema": body}},
+		}
+	}
+
+	responses := map[string]interface{}{}
+	codes := make([]int, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		response := op.Responses[code]
+		description := response.Description
+		if description == "" {
+			description = http.StatusText(code)
+		}
+		responses[strconv.Itoa(code)] = map[string]interface{}{
+			"description": description,
+			"content":     map[string]interface{}{fiber.MIMEApplicationJSON: map[string]interface{}{"schema": envelopeSchema(response.Body)}},
+		}
+	}
+	if len(responses) == 0 {
+		responses["default"] = map[string]interface{}{"description": "Response"}
+	}
+	spec["responses"] = responses
+	return spec
+}
+
+// requestSpec returns the parameters and the body schema of a request type, from the params,
+// query, reqHeader and json tags of its fields and the query DSL of a QueryRequest. Requests with
+// files have a multipart body built from the form tags instead.
+func requestSpec(t reflect.Type) (parameters []map[string]interface{}, body map[string]interface{}, contentType string) {
+	properties := map[string]interface{}{}
+	required := []string{}
+	formProperties := map[string]interface{}{}
+	formRequired := []string{}
+	hasFiles := false
+	for _, field := range fields(t) {
+		if field.Name == "AuthUserData" {
+			continue
+		}
+
+		validate := field.Tag.Get("validate")
+		isRequired := hasRule(validate, "required")
+		for _, in := range []struct{ tag, location string }{{"params", "path"}, {"query", "query"}, {"reqHeader", "header"}} {
+			name := tagName(field.Tag.Get(in.tag))
+			if name == "" {
+				continue
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       in.location,
+				"required": isRequired || in.location == "path",
+				"schema":   withRules(schemaOf(field.Type), validate),
+			})
+		}
+
+		if name := tagName(field.Tag.Get("json")); name != "" {
+			properties[name] = withRules(schemaOf(field.Type), validate)
+			if isRequired {
+				required = append(required, name)
+			}
+		}
+
+		if name := tagName(field.Tag.Get("form")); name != "" {
+			formProperties[name] = withRules(schemaOf(field.Type), validate)
+			if isRequired {
+				formRequired = append(formRequired, name)
+			}
+			hasFiles = hasFiles || field.Type == fileHeaderType || field.Type == fileHeadersType
+		}
+	}
+
+	parameters = append(parameters, queryParameters(t)...)
+
+	contentType = fiber.MIMEApplicationJSON
+	if hasFiles {
+		properties, required, contentType = formProperties, formRequired, fiber.MIMEMultipartForm
+	}
+	if len(properties) == 0 {
+		return parameters, nil, contentType
+	}
+	body = map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		body["required"] = required
+	}
+	return parameters, body, contentType
+}
+
+// envelopeSchema returns the schema of the wrapped response carrying body as data.
+func envelopeSchema(body interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"statusCode": map[string]interface{}{"type": "string"},
+		"message":    map[string]interface{}{"type": "string"},
+	}
+	if body != nil {
+		properties["data"] = schemaOf(reflect.TypeOf(body))
+	}
+	return map[string]interface{}{"type": "object", "properties": properties}
+}
+
+// schemaOf returns the JSON schema of a Go type.
+func schemaOf(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case fileHeaderType.Elem():
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for _, field := range fields(t) {
+			if name := tagName(field.Tag.Get("json")); name != "" {
+				properties[name] = schemaOf(field.Type)
+			}
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	}
+	return map[string]interface{}{}
+}
+
+// withRules adds the enum of a oneof validation rule to the schema.
+func withRules(schema map[string]interface{}, validate string) map[string]interface{} {
+	for _, rule := range strings.Split(validate, ",") {
+		if values, ok := strings.CutPrefix(rule, "oneof="); ok {
+			schema["enum"] = strings.Fields(values)
+		}
+	}
+	return schema
+}
+
+// fields returns the exported fields of a struct type, including the ones of embedded structs.
+func fields(t reflect.Type) []reflect.StructField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	result := []reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			result = append(result, fields(field.Type)...)
+			continue
+		}
+		if field.IsExported() {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// tagName returns the name of a struct tag value, empty when the field is skipped.
+func tagName(tag string) string {
+	name := strings.SplitN(tag, ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func hasRule(validate string, rule string) bool {
+	for _, r := range strings.Split(validate, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/query"
+	"go.uber.org/zap"
+)
+
+var queryType = reflect.TypeOf(query.Query{})
+
+// QueryRequest is implemented by the requests accepting the query DSL, with the whitelist of their
+// fields and operators.
+type QueryRequest interface {
+	QuerySchema() *query.Schema
+}
+
+// BindFromQueryDSL parses the filter, sort and q parameters of the query string with the schema of
+// the request, a QueryRequest, and sets its query.Query field. Parameters outside the schema are
+// answered with a binding error each. Handle checks the request type when the route is registered.
+func BindFromQueryDSL() Source {
+	return func(b *Binder) error {
+		req, ok := b.m.(QueryRequest)
+		field := queryField(reflect.Indirect(reflect.ValueOf(b.m)))
+		if !ok || !field.IsValid() {
+			return fmt.Errorf("binding: %T must implement QueryRequest and have a query.Query field", b.m)
+		}
+
+		values := map[string][]string{}
+		b.ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			values[string(key)] = append(values[string(key)], string(value))
+		})
+
+		q, err := req.QuerySchema().Parse(values)
+		if err != nil {
+			b.l.Debug("Error when parsing the query DSL", zap.Error(err))
+			var queryErrs query.Errors
+			if !errors.As(err, &queryErrs) {
+				return err
+			}
+			bindingErrs := make(BindingErrors, len(queryErrs))
+			for i, queryErr := range queryErrs {
+				bindingErrs[i] = BindingError{
+					Source:   SourceQuery,
+					Field:    queryErr.Param,
+					Expected: queryErr.Expected,
+					Value:    queryErr.Value,
+				}.withMessage(queryErr.Message, queryErr.Params...)
+			}
+			return bindingErrs
+		}
+
+		field.Set(reflect.ValueOf(q))
+		return nil
+	}
+}
+
+// checkQueryRequest returns an error unless the request implements QueryRequest with a valid
+// schema and has a query.Query field, or has neither.
+func checkQueryRequest(m interface{}) error {
+	req, ok := m.(QueryRequest)
+	if ok != queryField(reflect.Indirect(reflect.ValueOf(m))).IsValid() {
+		return fmt.Errorf("binding: %T must implement QueryRequest and have a query.Query field", m)
+	}
+	if ok {
+		if err := req.QuerySchema().Validate(); err != nil {
+			return fmt.Errorf("binding: invalid query schema of %T: %w", m, err)
+		}
+	}
+	return nil
+}
+
+// queryField returns the query.Query field of the struct, or the zero value.
+func queryField(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Type == queryType && v.Field(i).CanSet() {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// queryParameters returns the OpenAPI parameters of the query DSL of a request type, if it is a
+// QueryRequest.
+func queryParameters(t reflect.Type) []map[string]interface{} {
+	req, ok := reflect.New(t).Interface().(QueryRequest)
+	if !ok {
+		return nil
+	}
+	schema := req.QuerySchema()
+
+	names := make([]string, 0, len(schema.Fields))
+	for name := range schema.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := []map[string]interface{}{}
+	sortable := []string{}
+	for _, name := range names {
+		field := schema.Fields[name]
+		if field.Sortable {
+			sortable = append(sortable, name)
+		}
+		for _, op := range field.Operators {
+			valueSchema := querySchema(field.Type)
+			if op == query.OpIn {
+				valueSchema = map[string]interface{}{"type": "string", "description": "Comma separated values"}
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":   fmt.Sprintf("%s[%s][%s]", query.ParamFilter, name, op),
+				"in":     "query",
+				"schema": valueSchema,
+			})
+		}
+	}
+	if len(sortable) > 0 {
+		parameters = append(parameters, map[string]interface{}{
+			"name":        query.ParamSort,
+			"in":          "query",
+			"description": "Comma separated fields, descending when prefixed by -: " + strings.Join(sortable, ", "),
+			"schema":      map[string]interface{}{"type": "string"},
+		})
+	}
+	if len(schema.Search) > 0 {
+		parameters = append(parameters, map[string]interface{}{
+			"name":   query.ParamSearch,
+			"in":     "query",
+			"schema": map[string]interface{}{"type": "string"},
+		})
+	}
+	return parameters
+}
+
+// querySchema returns the JSON schema of the values of a query DSL field type.
+func querySchema(fieldType query.FieldType) map[string]interface{} {
+	switch fieldType {
+	case query.TypeInteger, query.TypeNumber, query.TypeBoolean:
+		return map[string]interface{}{"type": string(fieldType)}
+	case query.TypeDateTime:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	return map[string]interface{}{"type": "string"}
+}
@@ -0,0 +1,141 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/query"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var bookQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"author":     {Column: "author", Operators: []query.Operator{query.OpEq, query.OpIn}, Sortable: true},
+		"created_at": {Column: "created_at", Type: query.TypeDateTime, Operators: []query.Operator{query.OpGte}, Sortable: true},
+	},
+	Search: []string{"title"},
+}
+
+type requestBookSearch struct {
+	Page  int `query:"page"`
+	Query query.Query
+}
+
+func (r *requestBookSearch) QuerySchema() *query.Schema {
+	return bookQuery
+}
+
+func TestBindFromQueryDSL(t *testing.T) {
+	var bound requestBookSearch
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/books", func(c *fiber.Ctx) error {
+		bound = requestBookSearch{}
+		if err := binding.BindModel(zap.NewNop(), c, &bound, binding.BindFromQuery(), binding.BindFromQueryDSL()); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books?page=2&filter[author][in]=Herbert,Austen&sort=-created_at,author&q=dune", nil)
+	resp, _ := app.Test(req)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, bound.Page)
+	require.Len(t, bound.Query.Filters, 1)
+	assert.Equal(t, []interface{}{"Herbert", "Austen"}, bound.Query.Filters[0].Value)
+	assert.Equal(t, "created_at", bound.Query.Sorts[0].Field)
+	assert.True(t, bound.Query.Sorts[0].Desc)
+	assert.Equal(t, "dune", bound.Query.Search)
+
+	// parameters outside the schema are binding errors
+	req = httptest.NewRequest(http.MethodGet, "/books?filter[author][contains]=her&filter[created_at][gte]=yesterday&sort=title", nil)
+	resp, _ = app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.JSONEq(t, `{"statusCode":"000001","message":"Failed to validate payload","data":[
+		{"source":"query","field":"filter[author][contains]","value":"her","message":"filter[author][contains] must use one of the operators eq, in"},
+		{"source":"query","field":"filter[created_at][gte]","expected":"date-time","value":"yesterday","message":"filter[created_at][gte] must be of type date-time"},
+		{"source":"query","field":"sort","value":"title","message":"title is not a sortable field"}
+	]}`, string(body))
+
+	// in the language of the request
+	req = httptest.NewRequest(http.MethodGet, "/books?sort=title", nil)
+	req.Header.Set(fiber.HeaderAcceptLanguage, "id")
+	resp, _ = app.Test(req)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"message":"title bukan field yang dapat diurutkan"`)
+}
+
+func TestDocs_QueryDSL(t *testing.T) {
+	docs := binding.NewDocs(&binding.DocsOpts{Title: "Books", Version: "1.0.0"})
+	opts := &binding.HandleOpts{Logger: zap.NewNop(), Docs: docs}
+
+	app := fiber.New()
+	app.Get("/books", binding.Handle[requestBookSearch](opts, binding.Sources(binding.BindFromQuery(), binding.BindFromQueryDSL()), nil,
+		binding.WithOperation(binding.Operation{ID: "books.search"}),
+	)).Name("books.search")
+
+	raw, err := json.Marshal(docs.Spec(app))
+	require.NoError(t, err)
+	var spec struct {
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name   string                 `json:"name"`
+				In     string                 `json:"in"`
+				Schema map[string]interface{} `json:"schema"`
+			} `json:"parameters"`
+		} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &spec))
+
+	names := []string{}
+	for _, parameter := range spec.Paths["/books"]["get"].Parameters {
+		assert.Equal(t, "query", parameter.In)
+		names = append(names, parameter.Name)
+	}
+	assert.Equal(t, []string{"page", "filter[author][eq]", "filter[author][in]", "filter[created_at][gte]", "sort", "q"}, names)
+}
+
+type requestBookNoSchema struct {
+	Query query.Query
+}
+
+type requestBookBadSort struct {
+	Query query.Query
+}
+
+func (r *requestBookBadSort) QuerySchema() *query.Schema {
+	return &query.Schema{Fields: bookQuery.Fields, DefaultSort: []query.Sort{{Field: "title"}}}
+}
+
+func TestHandle_QueryRequestCheckedAtRegistration(t *testing.T) {
+	opts := &binding.HandleOpts{Logger: zap.NewNop()}
+
+	// a query.Query field without schema fails when the route is registered
+	assert.PanicsWithError(t, "binding: *binding_test.requestBookNoSchema must implement QueryRequest and have a query.Query field", func() {
+		binding.Handle[requestBookNoSchema](opts, binding.Sources(binding.BindFromQueryDSL()), nil)
+	})
+	// so does a default sort outside the schema
+	assert.PanicsWithError(t, `binding: invalid query schema of *binding_test.requestBookBadSort: query: default sort "title" is not a sortable field`, func() {
+		binding.Handle[requestBookBadSort](opts, binding.Sources(binding.BindFromQueryDSL()), nil)
+	})
+	assert.NotPanics(t, func() {
+		binding.Handle[requestBookSearch](opts, binding.Sources(binding.BindFromQueryDSL()), nil)
+	})
+
+	// BindModel answers an error instead of panicking
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/books", func(c *fiber.Ctx) error {
+		return binding.BindModel(zap.NewNop(), c, &requestBookNoSchema{}, binding.BindFromQueryDSL())
+	})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/books?sort=author", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
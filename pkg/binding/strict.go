@@ -0,0 +1,289 @@
+package binding
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxBodyBytes is the size limit of the strict JSON bodies.
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultMaxBodyDepth is the nesting limit of the strict JSON bodies.
+	DefaultMaxBodyDepth = 32
+)
+
+// Messages of the strict JSON binding errors.
+const (
+	MessageUnsupportedBody = "Request body must be of type {0}"
+	MessageBodyTooLarge    = "Request body must not be larger than {0} bytes"
+	MessageBodyTooDeep     = "Request body must not be nested deeper than {0} levels"
+	MessageTrailingData    = "Request body must contain a single JSON value"
+	MessageUnknownField    = "{0} is not an allowed field"
+	MessageDuplicateField  = "{0} must not be repeated"
+)
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		MessageUnsupportedBody: "Isi permintaan harus bertipe {0}",
+		MessageBodyTooLarge:    "Isi permintaan tidak boleh lebih besar dari {0} byte",
+		MessageBodyTooDeep:     "Isi permintaan tidak boleh bersarang lebih dari {0} tingkat",
+		MessageTrailingData:    "Isi permintaan harus berisi satu nilai JSON",
+		MessageUnknownField:    "{0} bukan field yang diizinkan",
+		MessageDuplicateField:  "{0} tidak boleh berulang",
+	})
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	// jsonFieldsCache maps the struct types to the types of their JSON fields.
+	jsonFieldsCache sync.Map
+)
+
+type (
+	// StrictJSONOpts represents the limits of a strict JSON body. Zero values use the defaults.
+	StrictJSONOpts struct {
+		// MaxBytes is the maximum size of the body, DefaultMaxBodyBytes by default.
+		MaxBytes int
+		// MaxDepth is the maximum nesting of objects and arrays, DefaultMaxBodyDepth by default.
+		MaxDepth int
+	}
+
+	// strictDecoder walks the tokens of a JSON body along the type of the model.
+	strictDecoder struct {
+		dec      *json.Decoder
+		maxDepth int
+		errs     BindingErrors
+	}
+)
+
+// BindFromStrictJSON binds the JSON body like BindFromBody, rejecting the bodies that are not
+// application/json, larger or deeper than the limits, with trailing data after the value, with
+// fields unknown to the model or with repeated fields. opts may be nil to use the default limits.
+func BindFromStrictJSON(opts *StrictJSONOpts) Source {
+	maxBytes, maxDepth := DefaultMaxBodyBytes, DefaultMaxBodyDepth
+	if opts != nil && opts.MaxBytes > 0 {
+		maxBytes = opts.MaxBytes
+	}
+	if opts != nil && opts.MaxDepth > 0 {
+		maxDepth = opts.MaxDepth
+	}
+
+	return func(b *Binder) error {
+		contentType := strings.ToLower(b.ctx.Get(fiber.HeaderContentType))
+		if !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+			return BindingErrors{BindingError{Source: SourceBody, Expected: fiber.MIMEApplicationJSON}.withMessage(MessageUnsupportedBody, fiber.MIMEApplicationJSON)}
+		}
+
+		body := b.ctx.Body()
+		if len(body) > maxBytes {
+			return BindingErrors{BindingError{Source: SourceBody}.withMessage(MessageBodyTooLarge, strconv.Itoa(maxBytes))}
+		}
+
+		d := &strictDecoder{dec: json.NewDecoder(bytes.NewReader(body)), maxDepth: maxDepth}
+		if err := d.value(reflect.TypeOf(b.m), "", 0); err != nil {
+			b.l.Debug("Error when reading strict JSON body", zap.Error(err))
+			return err
+		}
+		if _, err := d.dec.Token(); !errors.Is(err, io.EOF) {
+			if err != nil {
+				return invalidJSON()
+			}
+			return BindingErrors{BindingError{Source: SourceBody, Expected: "JSON"}.withMessage(MessageTrailingData)}
+		}
+		if len(d.errs) > 0 {
+			return d.errs
+		}
+
+		if err := json.Unmarshal(body, b.m); err != nil {
+			b.l.Debug("Error when binding from strict JSON body", zap.Error(err))
+			return newBodyBindingErrors(b.ctx, err)
+		}
+		b.markBound("json", jsonHas(body))
+		return nil
+	}
+}
+
+// value reads the value at path, of type t or of any type when t is nil. Unknown and repeated
+// fields are collected, the other errors stop the decoding.
+func (d *strictDecoder) value(t reflect.Type, path string, depth int) error {
+	t = jsonType(t)
+
+	token, err := d.dec.Token()
+	if err != nil {
+		return invalidJSON()
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return nil
+	}
+	if depth+1 > d.maxDepth {
+		return BindingErrors{BindingError{Source: SourceBody, Field: path}.withMessage(MessageBodyTooDeep, strconv.Itoa(d.maxDepth))}
+	}
+
+	if delim == '[' {
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i := 0; d.dec.More(); i++ {
+			if err := d.value(elem, path+"["+strconv.Itoa(i)+"]", depth+1); err != nil {
+				return err
+			}
+		}
+		return d.end()
+	}
+
+	seen := map[string]bool{}
+	for d.dec.More() {
+		token, err := d.dec.Token()
+		if err != nil {
+			return invalidJSON()
+		}
+		key, _ := token.(string)
+		field := joinPath(path, key)
+
+		// the fields of a struct are matched like encoding/json does, case-insensitively
+		name, child, known := key, reflect.Type(nil), true
+		switch {
+		case t != nil && t.Kind() == reflect.Struct:
+			name, child, known = structField(t, key)
+		case t != nil && t.Kind() == reflect.Map:
+			child = t.Elem()
+		}
+
+		switch {
+		case !known:
+			d.errs = append(d.errs, BindingError{Source: SourceBody, Field: field}.withMessage(MessageUnknownField, field))
+		case seen[name]:
+			d.errs = append(d.errs, BindingError{Source: SourceBody, Field: field}.withMessage(MessageDuplicateField, field))
+		}
+		seen[name] = true
+
+		if err := d.value(child, field, depth+1); err != nil {
+			return err
+		}
+	}
+	return d.end()
+}
+
+// end reads the end of an object or array.
+func (d *strictDecoder) end() error {
+	if _, err := d.dec.Token(); err != nil {
+		return invalidJSON()
+	}
+	return nil
+}
+
+func invalidJSON() BindingErrors {
+	return BindingErrors{BindingError{Source: SourceBody, Expected: "JSON"}.withMessage(MessageInvalidJSON)}
+}
+
+// jsonType returns the type decoded from a JSON value into t, or nil for the types decoding any
+// JSON value, such as interfaces and the types unmarshaling themselves.
+func jsonType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() == reflect.Interface {
+		return nil
+	}
+	for _, unmarshaler := range []reflect.Type{jsonUnmarshalerType, textUnmarshalerType} {
+		if t.Implements(unmarshaler) || reflect.PointerTo(t).Implements(unmarshaler) {
+			return nil
+		}
+	}
+	return t
+}
+
+// structField returns the name and type of the field of the struct decoded from the JSON key.
+func structField(t reflect.Type, key string) (string, reflect.Type, bool) {
+	fields := jsonFields(t)
+	if field, ok := fields[key]; ok {
+		return key, field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return name, field, true
+		}
+	}
+	return "", nil, false
+}
+
+// jsonFields returns the types of the JSON fields of a struct by name, including the fields of
+// its embedded structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	if fields, ok := jsonFieldsCache.Load(t); ok {
+		return fields.(map[string]reflect.Type)
+	}
+
+	fields := map[string]reflect.Type{}
+	embedded := []map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			embedded = append(embedded, jsonFields(fieldType))
+			continue
+		}
+		if !field.IsExported() || (name == "" && !bodyField(field)) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+
+	// the fields of the struct hide the fields of the embedded structs
+	for _, embeddedFields := range embedded {
+		for name, fieldType := range embeddedFields {
+			if _, ok := fields[name]; !ok {
+				fields[name] = fieldType
+			}
+		}
+	}
+
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+// bodyField reports whether a field without a json tag may be bound from the body: it is not bound
+// from another source nor set by BindModel, such as AuthUserData.
+func bodyField(field reflect.StructField) bool {
+	for _, tag := range []string{"query", "params", "reqHeader", "form"} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return false
+		}
+	}
+	return field.Name != "AuthUserData"
+}
+
+// joinPath returns the path of a key of the object at path, such as author.age.
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,135 @@
+package binding_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type requestStrict struct {
+	ID       int             `params:"id"`
+	Title    string          `json:"title"`
+	Ratings  []int           `json:"ratings"`
+	Metadata json.RawMessage `json:"metadata"`
+	Author   struct {
+		Name string `json:"name"`
+		Tags map[string]string
+	} `json:"author"`
+}
+
+func TestBindFromStrictJSON(t *testing.T) {
+	var bound requestStrict
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/books/:id", func(c *fiber.Ctx) error {
+		bound = requestStrict{}
+		if err := binding.BindModel(zap.NewNop(), c, &bound, binding.BindFromParams(), binding.BindFromStrictJSON(&binding.StrictJSONOpts{MaxBytes: 128, MaxDepth: 3})); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    string
+	}{
+		{
+			name:     "valid",
+			body:     `{"title":"Dune","ratings":[5,4],"metadata":{"any":{"thing":1}},"Author":{"name":"Frank","tags":{"genre":"sf"}}}`,
+			expected: "",
+		},
+		{
+			name:        "content type",
+			contentType: "text/plain",
+			body:        `{"title":"Dune"}`,
+			expected:    `[{"source":"body","field":"","expected":"application/json","value":null,"message":"Request body must be of type application/json"}]`,
+		},
+		{
+			name:     "too large",
+			body:     `{"title":"` + strings.Repeat("a", 128) + `"}`,
+			expected: `[{"source":"body","field":"","value":null,"message":"Request body must not be larger than 128 bytes"}]`,
+		},
+		{
+			name:     "too deep",
+			body:     `{"author":{"tags":{"genre":"sf"}},"ratings":[[[1]]]}`,
+			expected: `[{"source":"body","field":"ratings[0][0]","value":null,"message":"Request body must not be nested deeper than 3 levels"}]`,
+		},
+		{
+			name:     "unknown and duplicate fields",
+			body:     `{"title":"Dune","Title":"Emma","subtitle":"","author":{"age":3},"id":7}`,
+			expected: `[{"source":"body","field":"Title","value":null,"message":"Title must not be repeated"},{"source":"body","field":"subtitle","value":null,"message":"subtitle is not an allowed field"},{"source":"body","field":"author.age","value":null,"message":"author.age is not an allowed field"},{"source":"body","field":"id","value":null,"message":"id is not an allowed field"}]`,
+		},
+		{
+			name:     "trailing data",
+			body:     `{"title":"Dune"} {"title":"Emma"}`,
+			expected: `[{"source":"body","field":"","expected":"JSON","value":null,"message":"Request body must contain a single JSON value"}]`,
+		},
+		{
+			name:     "syntax",
+			body:     `{"title":"Dune"`,
+			expected: `[{"source":"body","field":"","expected":"JSON","value":null,"message":"Request body must be valid JSON"}]`,
+		},
+		{
+			name:     "type",
+			body:     `{"ratings":[5,"high"]}`,
+			expected: `[{"source":"body","field":"ratings[1]","expected":"integer","value":"high","message":"ratings[1] must be of type integer"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/books/42", strings.NewReader(tt.body))
+			contentType := tt.contentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSONCharsetUTF8
+			}
+			req.Header.Set(fiber.HeaderContentType, contentType)
+
+			resp, _ := app.Test(req)
+			body, _ := io.ReadAll(resp.Body)
+			if tt.expected == "" {
+				assert.Equal(t, http.StatusOK, resp.StatusCode, string(body))
+				return
+			}
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.JSONEq(t, `{"statusCode":"000001","message":"Failed to validate payload","data":`+tt.expected+`}`, string(body))
+		})
+	}
+
+	// the valid body is bound
+	req := httptest.NewRequest(http.MethodPost, "/books/42", strings.NewReader(`{"title":"Dune","author":{"name":"Frank","Tags":{"genre":"sf"}}}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 42, bound.ID)
+	assert.Equal(t, "Dune", bound.Title)
+	assert.Equal(t, map[string]string{"genre": "sf"}, bound.Author.Tags)
+}
+
+func TestBindFromStrictJSON_Locale(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/books", func(c *fiber.Ctx) error {
+		if err := binding.BindModel(zap.NewNop(), c, &requestStrict{}, binding.BindFromStrictJSON(nil)); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{"subtitle":""}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.Header.Set(fiber.HeaderAcceptLanguage, "id")
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"message":"subtitle bukan field yang diizinkan"`)
+}
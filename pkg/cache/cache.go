@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"go.uber.org/zap"
+)
+
+func NewCache(opts *Opts) *Service {
+	return &Service{
+		logger:    opts.Logger,
+		redis:     opts.Redis,
+		keyPrefix: utils.IfThenElse(opts.KeyPrefix != "", opts.KeyPrefix, DefaultKeyPrefix),
+		ttl:       utils.IfThenElse(opts.TTL > 0, opts.TTL, DefaultTTL),
+	}
+}
+
+func (s *Service) Get(ctx context.Context, key string) (*Entry, error) {
+	raw, err := s.redis.Get(ctx, s.entryKey(key))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{}
+	if err := utils.JSONUnMarshal([]byte(raw), entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (s *Service) Set(ctx context.Context, key string, entry *Entry, tags ...string) error {
+	raw, err := utils.JSONMarshal(entry)
+	if err != nil {
+		return err
+	}
+
+	entryKey := s.entryKey(key)
+	if err := s.redis.Set(ctx, entryKey, raw, s.ttl); err != nil {
+		return err
+	}
+
+	// the tag set lives as long as the newest entry attached to it
+	for _, tag := range tags {
+		tagKey := s.tagKey(tag)
+		if err := s.redis.SAdd(ctx, tagKey, entryKey); err != nil {
+			return err
+		}
+		if err := s.redis.Expire(ctx, tagKey, s.ttl); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) TagVersions(ctx context.Context, tags ...string) (Versions, error) {
+	versions := Versions{}
+	for _, tag := range tags {
+		version, err := s.tagVersion(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		versions[tag] = version
+	}
+
+	return versions, nil
+}
+
+func (s *Service) SetVersioned(ctx context.Context, key string, entry *Entry, versions Versions) (bool, error) {
+	tags := make([]string, 0, len(versions))
+	for tag := range versions {
+		tags = append(tags, tag)
+	}
+	if err := s.Set(ctx, key, entry, tags...); err != nil {
+		return false, err
+	}
+
+	// InvalidateTags changes the version before reading the entries of the tag: either the change
+	// is seen here, or the invalidation reads the tag after the entry was attached and drops it
+	for tag, version := range versions {
+		current, err := s.tagVersion(ctx, tag)
+		if err == nil && current == version {
+			continue
+		}
+		if delErr := s.redis.Del(ctx, s.entryKey(key)); delErr != nil {
+			return false, delErr
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *Service) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		// the responses rendered before are not stored anymore, see SetVersioned. The version lives
+		// as long as the entries, longer than a request rendering one.
+		versionKey := s.versionKey(tag)
+		if _, err := s.redis.Incr(ctx, versionKey); err != nil {
+			return err
+		}
+		if err := s.redis.Expire(ctx, versionKey, s.ttl); err != nil {
+			return err
+		}
+
+		tagKey := s.tagKey(tag)
+		keys, err := s.redis.SMembers(ctx, tagKey)
+		if err != nil {
+			return err
+		}
+
+		if err := s.redis.Del(ctx, append(keys, tagKey)...); err != nil {
+			return err
+		}
+
+		s.logger.Debug("Cache tag invalidated", zap.String("tag", tag), zap.Int("entries", len(keys)))
+	}
+
+	return nil
+}
+
+func (s *Service) entryKey(key string) string {
+	return s.keyPrefix + ":entry:" + key
+}
+
+func (s *Service) tagKey(tag string) string {
+	return s.keyPrefix + ":tag:" + tag
+}
+
+func (s *Service) versionKey(tag string) string {
+	return s.keyPrefix + ":version:" + tag
+}
+
+// tagVersion returns the version of the tag, empty when it was never invalidated.
+func (s *Service) tagVersion(ctx context.Context, tag string) (string, error) {
+	version, err := s.redis.Get(ctx, s.versionKey(tag))
+	if errors.Is(err, redis.ErrNil) {
+		return "", nil
+	}
+	return version, err
+}
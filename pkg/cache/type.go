@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName      = "Components.Cache"
+	DefaultKeyPrefix = "cache"
+	DefaultTTL       = 5 * time.Minute
+)
+
+// Opts represents the options for configuring the response cache.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Redis stores the cached responses and their tags.
+	Redis redis.IRedisService
+
+	// KeyPrefix is the Redis key prefix. Default is "cache".
+	KeyPrefix string
+	// TTL is how long a response stays cached. Default is 5 minutes.
+	TTL time.Duration
+}
+
+// Entry represents a cached HTTP response.
+type Entry struct {
+	Code        int    `json:"code"`
+	ETag        string `json:"etag"`
+	ContentType string `json:"contentType"`
+	Body        []byte `json:"body"`
+}
+
+// Versions is the version of each tag, read before a response is rendered. Invalidating a tag
+// changes its version.
+type Versions map[string]string
+
+// Service represents the response cache service.
+type Service struct {
+	logger    *zap.Logger
+	redis     redis.IRedisService
+	keyPrefix string
+	ttl       time.Duration
+}
+
+// ICacheService represents the interface for the response cache service.
+type ICacheService interface {
+	// Get returns the cached response stored under key.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: cache key
+	//
+	// Returns:
+	//   - *Entry: cached response, nil on cache miss
+	//   - error: error
+	Get(ctx context.Context, key string) (*Entry, error)
+
+	// Set stores the response under key and attaches it to the given tags.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: cache key
+	//   - entry: response to cache
+	//   - tags: tags used for invalidation
+	//
+	// Returns:
+	//   - error: error
+	Set(ctx context.Context, key string, entry *Entry, tags ...string) error
+
+	// TagVersions returns the current version of the tags.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - tags: tags
+	//
+	// Returns:
+	//   - Versions: the version of each tag
+	//   - error: error
+	TagVersions(ctx context.Context, tags ...string) (Versions, error)
+
+	// SetVersioned stores the response under key and attaches it to the tags of versions, unless one
+	// of the tags was invalidated since its version was read: the response may predate the change.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: cache key
+	//   - entry: response to cache
+	//   - versions: the tags with their version read before the response was rendered
+	//
+	// Returns:
+	//   - bool: true if the response was stored
+	//   - error: error
+	SetVersioned(ctx context.Context, key string, entry *Entry, versions Versions) (bool, error)
+
+	// InvalidateTags removes every cached response attached to any of the given tags.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - tags: tags to invalidate
+	//
+	// Returns:
+	//   - error: error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
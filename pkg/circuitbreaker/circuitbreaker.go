@@ -0,0 +1,119 @@
+package circuitbreaker
+
+import "time"
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+	defaultHalfOpenRequests = 1
+)
+
+func NewCircuitBreaker(opts Opts) *Breaker {
+	b := &Breaker{
+		name:             opts.Name,
+		failureThreshold: opts.FailureThreshold,
+		openTimeout:      opts.OpenTimeout,
+		halfOpenRequests: opts.HalfOpenRequests,
+		onStateChange:    opts.OnStateChange,
+		state:            StateClosed,
+		now:              time.Now,
+	}
+	if b.failureThreshold <= 0 {
+		b.failureThreshold = defaultFailureThreshold
+	}
+	if b.openTimeout <= 0 {
+		b.openTimeout = defaultOpenTimeout
+	}
+	if b.halfOpenRequests <= 0 {
+		b.halfOpenRequests = defaultHalfOpenRequests
+	}
+	return b
+}
+
+// Name returns the breaker name.
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State returns the current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	return b.state
+}
+
+// Allow reserves a call. It returns ErrOpen when the call is rejected, otherwise done must be
+// called with the outcome of the call.
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return nil, ErrOpen
+	case StateHalfOpen:
+		if b.inFlight >= b.halfOpenRequests {
+			return nil, ErrOpen
+		}
+	}
+
+	b.inFlight++
+	return b.done, nil
+}
+
+// Execute runs fn when the breaker allows it and records its outcome.
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	done(err == nil)
+	return err
+}
+
+func (b *Breaker) done(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inFlight--
+	if success {
+		b.failures = 0
+		if b.state == StateHalfOpen {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.openedAt = b.now()
+		b.setState(StateOpen)
+	}
+}
+
+// refresh moves an open breaker to half open once the open timeout elapsed. b.mu must be held.
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		b.setState(StateHalfOpen)
+	}
+}
+
+// setState changes the state. b.mu must be held.
+func (b *Breaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	from := b.state
+	b.state = state
+	if state != StateOpen {
+		b.failures = 0
+	}
+	if b.onStateChange != nil {
+		b.onStateChange(b.name, from, state)
+	}
+}
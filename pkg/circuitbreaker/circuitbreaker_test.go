@@ -0,0 +1,67 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errCall = errors.New("call failed")
+
+func TestBreaker(t *testing.T) {
+	transitions := []circuitbreaker.State{}
+	b := circuitbreaker.NewCircuitBreaker(circuitbreaker.Opts{
+		Name:             "test",
+		FailureThreshold: 2,
+		OpenTimeout:      20 * time.Millisecond,
+		OnStateChange: func(_ string, _ circuitbreaker.State, to circuitbreaker.State) {
+			transitions = append(transitions, to)
+		},
+	})
+	fail := func() error { return errCall }
+	succeed := func() error { return nil }
+
+	// consecutive failures open the breaker, a success resets the count
+	assert.ErrorIs(t, b.Execute(fail), errCall)
+	assert.NoError(t, b.Execute(succeed))
+	assert.ErrorIs(t, b.Execute(fail), errCall)
+	assert.Equal(t, circuitbreaker.StateClosed, b.State())
+	assert.ErrorIs(t, b.Execute(fail), errCall)
+	assert.Equal(t, circuitbreaker.StateOpen, b.State())
+	assert.ErrorIs(t, b.Execute(succeed), circuitbreaker.ErrOpen)
+
+	// a failed trial opens it again
+	time.Sleep(25 * time.Millisecond)
+	assert.Equal(t, circuitbreaker.StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Execute(fail), errCall)
+	assert.Equal(t, circuitbreaker.StateOpen, b.State())
+
+	// a successful trial closes it
+	time.Sleep(25 * time.Millisecond)
+	assert.NoError(t, b.Execute(succeed))
+	assert.Equal(t, circuitbreaker.StateClosed, b.State())
+
+	assert.Equal(t, []circuitbreaker.State{
+		circuitbreaker.StateOpen, circuitbreaker.StateHalfOpen, circuitbreaker.StateOpen,
+		circuitbreaker.StateHalfOpen, circuitbreaker.StateClosed,
+	}, transitions)
+}
+
+func TestBreaker_HalfOpenLimitsTrials(t *testing.T) {
+	b := circuitbreaker.NewCircuitBreaker(circuitbreaker.Opts{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	_ = b.Execute(func() error { return errCall })
+	time.Sleep(15 * time.Millisecond)
+
+	done, err := b.Allow()
+	require.NoError(t, err)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+
+	done(true)
+	_, err = b.Allow()
+	assert.NoError(t, err)
+}
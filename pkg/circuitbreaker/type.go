@@ -0,0 +1,52 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned when the breaker rejects a call.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a breaker.
+type State string
+
+const (
+	// StateClosed lets every call through.
+	StateClosed State = "closed"
+	// StateOpen rejects every call until OpenTimeout elapsed.
+	StateOpen State = "open"
+	// StateHalfOpen lets HalfOpenRequests trial calls through to decide whether to close again.
+	StateHalfOpen State = "half_open"
+)
+
+// Opts represents the options for configuring a breaker.
+type Opts struct {
+	// Name identifies the breaker in logs and metrics.
+	Name string
+	// FailureThreshold is the number of consecutive failures opening the breaker. Default is 5.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before trying again. Default is 30s.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial calls allowed while half open. Default is 1.
+	HalfOpenRequests int
+	// OnStateChange is called after every state change, with the breaker locked: it must not call the breaker.
+	OnStateChange func(name string, from State, to State)
+}
+
+// Breaker is a consecutive failures circuit breaker.
+type Breaker struct {
+	name             string
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenRequests int
+	onStateChange    func(name string, from State, to State)
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	inFlight int
+	openedAt time.Time
+	now      func() time.Time
+}
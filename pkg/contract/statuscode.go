@@ -6,6 +6,26 @@ const (
 	ErrorMutatePayload         = "Failed to mutate payload"
 	ErrorInsufficientPrivilege = "User does not have privilege to perform this action"
 
+	// Common error messages idempotency
+	ErrorIdempotencyInFlight = "A request with the same idempotency key is still being processed"
+	ErrorIdempotencyMismatch = "Idempotency key was already used with a different payload"
+
+	// Common error messages timeout
+	ErrorRequestTimeout = "Request did not complete within the allowed time"
+
+	// Common error messages domain
+	ErrorNotFound           = "Resource not found"
+	ErrorConflict           = "Resource conflicts with the current state"
+	ErrorServiceUnavailable = "Service is temporarily unavailable"
+	ErrorInternalServer     = "Internal server error"
+
+	// Common error messages maintenance
+	ErrorMaintenanceMode = "Service is under maintenance"
+	ErrorReadOnlyMode    = "Service is in read-only mode"
+
+	// Common error messages versioning
+	ErrorUnsupportedVersion = "Requested API version is not supported"
+
 	// Common error message database
 	ErrorFailedToFindRecord   = "Failed to find record"
 	ErrorFailedToReadCursor   = "Failed to read cursor"
@@ -25,6 +45,18 @@ const (
 	StatusCodeUserOrPasswordInvalid = StatusCode("000012")
 	StatusCodeInternalServerError   = StatusCode("000013")
 	StatusCodeSequenceError         = StatusCode("000014")
+	StatusCodeIdempotencyInFlight   = StatusCode("000015")
+	StatusCodeIdempotencyMismatch   = StatusCode("000016")
+	StatusCodeRequestTimeout        = StatusCode("000017")
+	StatusCodeNotFound              = StatusCode("000018")
+	StatusCodeMethodNotAllowed      = StatusCode("000019")
+	StatusCodeConflict              = StatusCode("000020")
+	StatusCodeForbidden             = StatusCode("000021")
+	StatusCodeServiceUnavailable    = StatusCode("000022")
+	StatusCodeRequestFailed         = StatusCode("000023")
+	StatusCodeUnsupportedVersion    = StatusCode("000024")
+	StatusCodeMaintenance           = StatusCode("000025")
+	StatusCodeReadOnly              = StatusCode("000026")
 )
 
 func CreateStatusCode(code string) StatusCode {
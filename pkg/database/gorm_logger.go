@@ -17,6 +17,9 @@ import (
 type (
 	ContextFn func(ctx context.Context) []zapcore.Field
 
+	// SlowQueryFn is called for every query slower than the slow threshold.
+	SlowQueryFn func(ctx context.Context, sql string, elapsed time.Duration)
+
 	CustomGormLogger struct {
 		LogSQL                    bool
 		ZapLogger                 *zap.Logger
@@ -25,6 +28,7 @@ type (
 		SkipCallerLookup          bool
 		IgnoreRecordNotFoundError bool
 		Context                   ContextFn
+		OnSlowQuery               SlowQueryFn
 	}
 )
 
@@ -57,6 +61,7 @@ func (l CustomGormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interfac
 		SkipCallerLookup:          l.SkipCallerLookup,
 		IgnoreRecordNotFoundError: l.IgnoreRecordNotFoundError,
 		Context:                   l.Context,
+		OnSlowQuery:               l.OnSlowQuery,
 	}
 }
 
@@ -103,6 +108,12 @@ func (l CustomGormLogger) Trace(ctx context.Context, begin time.Time, fc func()
 		logOpts = append(logOpts, zap.String("sql", sql))
 	}
 
+	// count the slow query, regardless of the log level
+	slow := l.SlowThreshold != 0 && elapsed > l.SlowThreshold
+	if slow && l.OnSlowQuery != nil {
+		l.OnSlowQuery(ctx, sql, elapsed)
+	}
+
 	// check if we want to log the error
 	if err != nil && l.LogLevel >= gormlogger.Error && (!l.IgnoreRecordNotFoundError || !errors.Is(err, gorm.ErrRecordNotFound)) {
 		logger.Error("trace", append(logOpts, zap.Error(err))...)
@@ -110,7 +121,7 @@ func (l CustomGormLogger) Trace(ctx context.Context, begin time.Time, fc func()
 	}
 
 	// check if we want to log the slow query
-	if l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= gormlogger.Warn {
+	if slow && l.LogLevel >= gormlogger.Warn {
 		logger.Warn("trace", logOpts...)
 		return
 	}
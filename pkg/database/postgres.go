@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 
@@ -48,11 +49,15 @@ func NewPostgres(opts *DBServiceOpts) (*DBService, error) {
 	}
 
 	queryLogger := logger.WithID(opts.Logger, ContextName, "ExecuteQuery")
+	var gormLogger CustomGormLogger
 	if opts.Debug {
-		gormOpts.Logger = NewGormLogger(queryLogger, gormlogger.Info, true)
+		gormLogger = NewGormLogger(queryLogger, gormlogger.Info, true)
 	} else {
-		gormOpts.Logger = NewGormLogger(queryLogger, gormlogger.Warn, false)
+		gormLogger = NewGormLogger(queryLogger, gormlogger.Warn, false)
 	}
+	gormLogger.OnSlowQuery = opts.OnSlowQuery
+	gormLogger.Context = logger.TraceFields
+	gormOpts.Logger = gormLogger
 
 	dialector := postgres.New(postgres.Config{
 		DSN:                  *opts.PostgresURI,
@@ -66,6 +71,14 @@ func NewPostgres(opts *DBServiceOpts) (*DBService, error) {
 		return nil, err
 	}
 
+	// register plugins
+	for _, plugin := range opts.Plugins {
+		if err := db.Use(plugin); err != nil {
+			l.Error("Cannot register Gorm plugin", zap.String("plugin", plugin.Name()), zap.Error(err))
+			return nil, err
+		}
+	}
+
 	// get connection
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -90,7 +103,8 @@ func NewPostgres(opts *DBServiceOpts) (*DBService, error) {
 
 	l.Info("Database connected")
 	return &DBService{
-		Gorm: db,
+		Gorm:   db,
+		logger: opts.Logger,
 	}, nil
 }
 
@@ -132,35 +146,71 @@ func (db *DBService) GetLockType(ctx context.Context) *string {
 	return utils.ToPointer(lockType.(string))
 }
 
-func (db *DBService) Defer(ctx context.Context) {
-	tx := ctx.Value(TransactionContextKey).(*gorm.DB)
+func (db *DBService) Defer(ctx context.Context, err *error) {
+	tx, ok := ctx.Value(TransactionContextKey).(*gorm.DB)
+	if !ok {
+		return
+	}
 	if p := recover(); p != nil {
 		tx.Rollback()
 		panic(p)
 	}
 
-	tx.Commit()
+	if err != nil && *err != nil {
+		tx.Rollback()
+		return
+	}
+	if commitErr := tx.Commit().Error; commitErr != nil && err != nil {
+		*err = commitErr
+	}
 }
 
 func (db *DBService) GetTransaction(ctx context.Context) *gorm.DB {
-	tx := ctx.Value(TransactionContextKey)
-	if tx == nil {
-		tx = db.Gorm
+	tx, ok := ctx.Value(TransactionContextKey).(*gorm.DB)
+	if !ok {
+		return db.Gorm.WithContext(ctx)
 	}
 
-	return tx.(*gorm.DB).WithContext(ctx)
+	if locking, ok := lockingClause(ctx); ok {
+		// the scopes run when each statement is executed, so the lock is chosen per statement
+		return tx.WithContext(ctx).Scopes(lockRows(locking)).Session(&gorm.Session{})
+	}
+	return tx.WithContext(ctx)
 }
 
 func (db *DBService) RollbackTransaction(ctx context.Context) *gorm.DB {
-	tx := ctx.Value(TransactionContextKey).(*gorm.DB)
+	tx, ok := ctx.Value(TransactionContextKey).(*gorm.DB)
+	if !ok {
+		return noTransaction(db.Gorm)
+	}
 	return tx.Rollback()
 }
 
 func (db *DBService) CommitTransaction(ctx context.Context) *gorm.DB {
-	tx := ctx.Value(TransactionContextKey).(*gorm.DB)
+	tx, ok := ctx.Value(TransactionContextKey).(*gorm.DB)
+	if !ok {
+		return noTransaction(db.Gorm)
+	}
 	return tx.Commit()
 }
 
+// noTransaction returns a session failing with gorm.ErrInvalidTransaction, for a context without
+// transaction.
+func noTransaction(db *gorm.DB) *gorm.DB {
+	tx := db.Session(&gorm.Session{NewDB: true})
+	_ = tx.AddError(gorm.ErrInvalidTransaction)
+	return tx
+}
+
+func (db *DBService) Stats() sql.DBStats {
+	sqlDB, err := db.Gorm.DB()
+	if err != nil {
+		return sql.DBStats{}
+	}
+
+	return sqlDB.Stats()
+}
+
 func (db *DBService) Close() error {
 	sqlDB, err := db.Gorm.DB()
 	if err != nil {
@@ -172,7 +222,7 @@ func (db *DBService) Close() error {
 
 func MigrateIfNeed(db *gorm.DB) error {
 	log.Println("Running database migration if necessary...")
-	err := db.AutoMigrate(&model.Book{})
+	err := db.AutoMigrate(&model.Book{}, &model.FeatureFlag{}, &model.AuditLog{})
 	if err != nil {
 		return err
 	}
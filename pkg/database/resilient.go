@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const (
+	guardSettingKey = "resilience:guard"
+	guardDoneKey    = "resilience:done"
+)
+
+// ResilientDBService decorates a database service with a guard: queries fail fast with a dependency
+// unavailable error while the breaker is open or the bulkhead is full.
+type ResilientDBService struct {
+	*DBService
+	guard resilience.IGuard
+}
+
+// NewResilientDB decorates service with guard. Queries run on the sessions returned by GetTransaction
+// and the transactions begun by WithTransaction are guarded, committing and rolling back a
+// transaction are not.
+func NewResilientDB(service *DBService, guard resilience.IGuard) (*ResilientDBService, error) {
+	if err := service.Gorm.Use(&guardPlugin{}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return nil, err
+	}
+
+	return &ResilientDBService{
+		DBService: service,
+		guard:     guard,
+	}, nil
+}
+
+// IsFailure reports whether a database error counts toward the breaker. Missing records,
+// cancelled callers and errors caused by the query itself, such as constraint violations, do not.
+func IsFailure(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code[:2] {
+		// connection exception, insufficient resources, operator intervention and system error
+		case "08", "53", "57", "58":
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// Guard returns the guard protecting the service.
+func (db *ResilientDBService) Guard() resilience.IGuard {
+	return db.guard
+}
+
+func (db *ResilientDBService) Ping() bool {
+	err := db.guard.Do(context.Background(), func(context.Context) error {
+		if !db.DBService.Ping() {
+			return errors.New("cannot ping postgres")
+		}
+		return nil
+	})
+	return err == nil
+}
+
+func (db *ResilientDBService) WithTransaction(ctx context.Context, opts *TxOpts, fn func(ctx context.Context) error) error {
+	return db.DBService.withTransaction(ctx, opts, fn, func(ctx context.Context, txOpts *sql.TxOptions) (*gorm.DB, error) {
+		var tx *gorm.DB
+		err := db.guard.Do(ctx, func(ctx context.Context) error {
+			var err error
+			tx, err = db.DBService.begin(ctx, txOpts)
+			return err
+		})
+		return tx, err
+	})
+}
+
+func (db *ResilientDBService) GetTransaction(ctx context.Context) *gorm.DB {
+	// a new session keeps the returned DB safe to reuse for several queries
+	return db.DBService.GetTransaction(ctx).Set(guardSettingKey, db.guard).Session(&gorm.Session{})
+}
+
+// guardPlugin runs the queries of the sessions carrying a guard through it.
+type guardPlugin struct{}
+
+func (p *guardPlugin) Name() string {
+	return "resilience"
+}
+
+func (p *guardPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("resilience:before_create", p.before),
+		cb.Create().After("gorm:create").Register("resilience:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("resilience:before_query", p.before),
+		cb.Query().After("gorm:query").Register("resilience:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("resilience:before_update", p.before),
+		cb.Update().After("gorm:update").Register("resilience:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("resilience:before_delete", p.before),
+		cb.Delete().After("gorm:delete").Register("resilience:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("resilience:before_row", p.before),
+		cb.Row().After("gorm:row").Register("resilience:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("resilience:before_raw", p.before),
+		cb.Raw().After("gorm:raw").Register("resilience:after_raw", p.after),
+	)
+}
+
+func (p *guardPlugin) before(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	value, ok := db.Get(guardSettingKey)
+	if !ok {
+		return
+	}
+	guard, ok := value.(resilience.IGuard)
+	if !ok {
+		return
+	}
+
+	// a rejected query is skipped by the GORM callbacks since the statement has an error
+	done, err := guard.Acquire(db.Statement.Context)
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	db.InstanceSet(guardDoneKey, done)
+}
+
+func (p *guardPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(guardDoneKey)
+	if !ok {
+		return
+	}
+	if done, ok := value.(func(error)); ok {
+		done(db.Error)
+	}
+}
@@ -0,0 +1,70 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunDB returns a database service generating SQL without connecting to Postgres.
+func newDryRunDB(t *testing.T) *database.DBService {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return &database.DBService{Gorm: db}
+}
+
+func TestResilientDB_GuardsQueries(t *testing.T) {
+	guard := resilience.NewGuard(&resilience.Opts{
+		Name:          "postgres",
+		Breaker:       circuitbreaker.Opts{FailureThreshold: 1, OpenTimeout: time.Hour},
+		MaxConcurrent: 1,
+		MaxWait:       time.Millisecond,
+	})
+	db, err := database.NewResilientDB(newDryRunDB(t), guard)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// queries release their slot
+	tx := db.GetTransaction(ctx)
+	require.NoError(t, tx.Find(&[]model.Book{}).Error)
+	require.NoError(t, tx.Where("id = ?", "1").Find(&model.Book{}).Error)
+
+	// a busy bulkhead rejects the query
+	done, err := guard.Acquire(ctx)
+	require.NoError(t, err)
+	err = db.GetTransaction(ctx).Find(&[]model.Book{}).Error
+	assert.True(t, apperror.IsKind(err, apperror.KindUnavailable))
+	assert.ErrorIs(t, err, resilience.ErrBulkheadFull)
+	done(errors.New("connection refused"))
+
+	// an open breaker rejects the query
+	assert.Equal(t, circuitbreaker.StateOpen, guard.State())
+	err = db.GetTransaction(ctx).Find(&[]model.Book{}).Error
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+
+	// the undecorated service is not guarded
+	assert.NoError(t, db.DBService.GetTransaction(ctx).Find(&[]model.Book{}).Error)
+}
+
+func TestIsFailure(t *testing.T) {
+	assert.False(t, database.IsFailure(gorm.ErrRecordNotFound))
+	assert.False(t, database.IsFailure(context.Canceled))
+	assert.False(t, database.IsFailure(&pgconn.PgError{Code: "23505"}), "unique violation")
+	assert.True(t, database.IsFailure(&pgconn.PgError{Code: "53300"}), "too many connections")
+	assert.True(t, database.IsFailure(&pgconn.PgError{Code: "57014"}), "statement timeout")
+	assert.True(t, database.IsFailure(context.DeadlineExceeded))
+}
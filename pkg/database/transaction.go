@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	TransactionDepthContextKey ContextTransaction = "postgres:transaction_depth"
+
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// beginFunc begins the transaction of an outermost WithTransaction.
+type beginFunc func(ctx context.Context, opts *sql.TxOptions) (*gorm.DB, error)
+
+func (db *DBService) WithTransaction(ctx context.Context, opts *TxOpts, fn func(ctx context.Context) error) error {
+	return db.withTransaction(ctx, opts, fn, db.begin)
+}
+
+// begin begins a transaction on the database.
+func (db *DBService) begin(ctx context.Context, opts *sql.TxOptions) (*gorm.DB, error) {
+	tx := db.Gorm.WithContext(ctx).Begin(opts)
+	return tx, tx.Error
+}
+
+// withTransaction runs fn in a savepoint of the transaction attached to the context, or in a new
+// transaction begun by begin, attempted again after a serialization failure or a deadlock.
+func (db *DBService) withTransaction(ctx context.Context, opts *TxOpts, fn func(ctx context.Context) error, begin beginFunc) error {
+	if tx, ok := ctx.Value(TransactionContextKey).(*gorm.DB); ok {
+		return runSavepoint(ctx, tx, fn)
+	}
+
+	if opts == nil {
+		opts = &TxOpts{}
+	}
+	maxRetries, backoffBase, backoffMax := opts.MaxRetries, opts.BackoffBase, opts.BackoffMax
+	if maxRetries == 0 {
+		maxRetries = defaultTxMaxRetries
+	}
+	if backoffBase <= 0 {
+		backoffBase = defaultTxBackoffBase
+	}
+	if backoffMax <= 0 {
+		backoffMax = defaultTxBackoffMax
+	}
+	txOpts := &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly}
+
+	l := db.logger
+	if l == nil {
+		l = zap.NewNop()
+	}
+	l = logger.WithContext(ctx, logger.WithID(l, ContextName, "WithTransaction"))
+	for attempt := 0; ; attempt++ {
+		err := runTransaction(ctx, txOpts, fn, begin)
+		if attempt >= maxRetries || !IsRetryableTransaction(err) {
+			return err
+		}
+
+		wait := backoff(backoffBase, backoffMax, attempt)
+		l.Warn("Retrying transaction", zap.Int("attempt", attempt+1), zap.Duration("backoff", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runTransaction runs fn once in a new transaction, committed when fn returns nil and rolled back
+// otherwise.
+func runTransaction(ctx context.Context, txOpts *sql.TxOptions, fn func(ctx context.Context) error, begin beginFunc) error {
+	tx, err := begin(ctx, txOpts)
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, TransactionContextKey, tx)
+	ctx = context.WithValue(ctx, TransactionDepthContextKey, 0)
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// runSavepoint runs fn in a savepoint of tx, released when fn returns nil and rolled back to
+// otherwise, leaving the outer transaction usable.
+func runSavepoint(ctx context.Context, tx *gorm.DB, fn func(ctx context.Context) error) error {
+	depth, _ := ctx.Value(TransactionDepthContextKey).(int)
+	depth++
+	name := fmt.Sprintf("sp_%d", depth)
+	if err := tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, TransactionDepthContextKey, depth)
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.RollbackTo(name)
+			panic(p)
+		}
+	}()
+
+	if err := fn(ctx); err != nil {
+		tx.RollbackTo(name)
+		return err
+	}
+	return tx.Exec("RELEASE SAVEPOINT " + name).Error
+}
+
+// IsRetryableTransaction reports whether a transaction failed with a serialization failure or a
+// deadlock, and succeeds when run again.
+func IsRetryableTransaction(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
+
+// backoff returns the wait before the next attempt: full jitter over an exponential ceiling.
+func backoff(base time.Duration, limit time.Duration, attempt int) time.Duration {
+	ceiling := base << attempt
+	if ceiling <= 0 || ceiling > limit {
+		ceiling = limit
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// lockingClause returns the locking clause of the lock type attached to the context, if any.
+func lockingClause(ctx context.Context) (clause.Locking, bool) {
+	lockType, ok := ctx.Value(TransactionLockTypeContextKey).(string)
+	if !ok || lockType == "" {
+		return clause.Locking{}, false
+	}
+	return clause.Locking{Strength: lockType}, true
+}
+
+// lockRows returns a scope adding the locking clause to the statements selecting rows. Postgres
+// rejects the lock on aggregates, so counts, DISTINCT and GROUP BY are not locked; inserts, updates
+// and deletes ignore the clause.
+func lockRows(locking clause.Locking) func(*gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		if _, isCount := tx.Statement.Dest.(*int64); isCount || tx.Statement.Distinct {
+			return tx
+		}
+		if _, grouped := tx.Statement.Clauses["GROUP BY"]; grouped {
+			return tx
+		}
+		return tx.Clauses(locking)
+	}
+}
@@ -0,0 +1,285 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakePool records the statements and transactions run on it, failing the commits with commitErrs
+// in order.
+type fakePool struct {
+	statements []string
+	isolations []sql.IsolationLevel
+	commitErrs []error
+}
+
+func (p *fakePool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *fakePool) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	p.statements = append(p.statements, query)
+	return driver.RowsAffected(1), nil
+}
+
+func (p *fakePool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *fakePool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return nil
+}
+
+func (p *fakePool) BeginTx(_ context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	p.statements = append(p.statements, "BEGIN")
+	if opts != nil {
+		p.isolations = append(p.isolations, opts.Isolation)
+	}
+	return &fakeTx{fakePool: p}, nil
+}
+
+type fakeTx struct {
+	*fakePool
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.statements = append(tx.statements, "COMMIT")
+	if len(tx.commitErrs) == 0 {
+		return nil
+	}
+	err := tx.commitErrs[0]
+	tx.commitErrs = tx.commitErrs[1:]
+	return err
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.statements = append(tx.statements, "ROLLBACK")
+	return nil
+}
+
+// newFakeDB returns a database service running its statements on pool.
+func newFakeDB(t *testing.T, pool *fakePool) *database.DBService {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return &database.DBService{Gorm: db}
+}
+
+// update runs an update on the transaction of the context.
+func update(db database.IDBService, ctx context.Context, title string) error {
+	return db.GetTransaction(ctx).Exec("UPDATE books SET title = ?", title).Error
+}
+
+func TestWithTransaction(t *testing.T) {
+	pool := &fakePool{}
+	db := newFakeDB(t, pool)
+	ctx := context.Background()
+
+	// committed when fn succeeds
+	err := db.WithTransaction(ctx, &database.TxOpts{Isolation: sql.LevelSerializable}, func(ctx context.Context) error {
+		return update(db, ctx, "Dune")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BEGIN", "UPDATE books SET title = $1", "COMMIT"}, pool.statements)
+	assert.Equal(t, []sql.IsolationLevel{sql.LevelSerializable}, pool.isolations)
+
+	// rolled back when fn fails
+	pool.statements = nil
+	failure := errors.New("out of stock")
+	err = db.WithTransaction(ctx, nil, func(ctx context.Context) error {
+		_ = update(db, ctx, "Dune")
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"BEGIN", "UPDATE books SET title = $1", "ROLLBACK"}, pool.statements)
+
+	// rolled back when fn panics
+	pool.statements = nil
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = db.WithTransaction(ctx, nil, func(context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, pool.statements)
+}
+
+func TestWithTransaction_Savepoints(t *testing.T) {
+	pool := &fakePool{}
+	db := newFakeDB(t, pool)
+
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context) error {
+		// a failed nested transaction rolls back its savepoint only
+		failure := errors.New("out of stock")
+		err := db.WithTransaction(ctx, nil, func(ctx context.Context) error {
+			_ = update(db, ctx, "Emma")
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		return db.WithTransaction(ctx, nil, func(ctx context.Context) error {
+			return db.WithTransaction(ctx, nil, func(ctx context.Context) error {
+				return update(db, ctx, "Dune")
+			})
+		})
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"BEGIN",
+		"SAVEPOINT sp_1", "UPDATE books SET title = $1", "ROLLBACK TO SAVEPOINT sp_1",
+		"SAVEPOINT sp_1", "SAVEPOINT sp_2", "UPDATE books SET title = $1", "RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1",
+		"COMMIT",
+	}, pool.statements)
+}
+
+func TestWithTransaction_Retries(t *testing.T) {
+	pool := &fakePool{commitErrs: []error{&pgconn.PgError{Code: "40001"}}}
+	db := newFakeDB(t, pool)
+	ctx := context.Background()
+	opts := &database.TxOpts{MaxRetries: 1, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond}
+
+	// a serialization failure is attempted again
+	attempts := 0
+	err := db.WithTransaction(ctx, opts, func(ctx context.Context) error {
+		attempts++
+		return update(db, ctx, "Dune")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, []string{"BEGIN", "UPDATE books SET title = $1", "COMMIT", "BEGIN", "UPDATE books SET title = $1", "COMMIT"}, pool.statements)
+
+	// up to MaxRetries times
+	attempts = 0
+	deadlock := &pgconn.PgError{Code: "40P01"}
+	err = db.WithTransaction(ctx, opts, func(context.Context) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 2, attempts)
+
+	// other errors are not attempted again
+	attempts = 0
+	err = db.WithTransaction(ctx, opts, func(context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "23505"}
+	})
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+
+	// negative MaxRetries disables retries
+	attempts = 0
+	err = db.WithTransaction(ctx, &database.TxOpts{MaxRetries: -1}, func(context.Context) error {
+		attempts++
+		return deadlock
+	})
+	assert.ErrorIs(t, err, deadlock)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestGetTransaction_LockType(t *testing.T) {
+	db := newFakeDB(t, &fakePool{})
+
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context) error {
+		locked := db.SetUpdateLockType(ctx)
+		assert.Equal(t, `SELECT * FROM "books" WHERE id = '1' FOR UPDATE`, db.GetTransaction(locked).ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("id = ?", "1").Find(&[]model.Book{})
+		}))
+		assert.Equal(t, `SELECT * FROM "books" FOR SHARE`, db.GetTransaction(db.SetShareLockType(ctx)).ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Find(&[]model.Book{})
+		}))
+		assert.Equal(t, `DELETE FROM "books" WHERE id = '1'`, db.GetTransaction(locked).ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Where("id = ?", "1").Delete(&model.Book{})
+		}))
+		return nil
+	})
+	require.NoError(t, err)
+
+	// outside of a transaction the rows are not locked
+	ctx := db.SetUpdateLockType(context.Background())
+	assert.Equal(t, `SELECT * FROM "books"`, db.GetTransaction(ctx).ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Find(&[]model.Book{})
+	}))
+}
+
+func TestGetTransaction_LockTypeCount(t *testing.T) {
+	db := newFakeDB(t, &fakePool{})
+
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context) error {
+		// a list counts and selects the page on the same session, only the page is locked
+		tx := db.GetTransaction(db.SetUpdateLockType(ctx)).Model(&model.Book{}).Where("author = ?", "Herbert").Session(&gorm.Session{})
+		assert.Equal(t, `SELECT count(*) FROM "books" WHERE author = 'Herbert'`, tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			var total int64
+			return tx.Count(&total)
+		}))
+		assert.Equal(t, `SELECT * FROM "books" WHERE author = 'Herbert' LIMIT 10 FOR UPDATE`, tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Limit(10).Find(&[]model.Book{})
+		}))
+		assert.Equal(t, `SELECT DISTINCT "author" FROM "books" WHERE author = 'Herbert'`, tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Distinct("author").Find(&[]string{})
+		}))
+		assert.Equal(t, `SELECT author, count(*) FROM "books" WHERE author = 'Herbert' GROUP BY "author"`, tx.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Select("author, count(*)").Group("author").Find(&[]map[string]interface{}{})
+		}))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestDefer(t *testing.T) {
+	pool := &fakePool{}
+	db := newFakeDB(t, pool)
+
+	run := func(fnErr error) (err error) {
+		ctx, _ := db.BeginTransaction(context.Background())
+		defer db.Defer(ctx, &err)
+		return fnErr
+	}
+	require.NoError(t, run(nil))
+	assert.Error(t, run(errors.New("out of stock")))
+	assert.Equal(t, []string{"BEGIN", "COMMIT", "BEGIN", "ROLLBACK"}, pool.statements)
+
+	// a context without transaction does not panic
+	ctx := context.Background()
+	assert.NotPanics(t, func() {
+		var err error
+		db.Defer(ctx, &err)
+	})
+	assert.ErrorIs(t, db.CommitTransaction(ctx).Error, gorm.ErrInvalidTransaction)
+	assert.ErrorIs(t, db.RollbackTransaction(ctx).Error, gorm.ErrInvalidTransaction)
+}
+
+func TestResilientDB_GuardsBegin(t *testing.T) {
+	pool := &fakePool{}
+	guard := resilience.NewGuard(&resilience.Opts{
+		Name:    "postgres",
+		Breaker: circuitbreaker.Opts{FailureThreshold: 1, OpenTimeout: time.Hour},
+	})
+	db, err := database.NewResilientDB(newFakeDB(t, pool), guard)
+	require.NoError(t, err)
+
+	done, err := guard.Acquire(context.Background())
+	require.NoError(t, err)
+	done(errors.New("connection refused"))
+
+	err = db.WithTransaction(context.Background(), nil, func(context.Context) error {
+		return nil
+	})
+	assert.True(t, apperror.IsKind(err, apperror.KindUnavailable))
+	assert.Empty(t, pool.statements)
+}
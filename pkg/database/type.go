@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"go.uber.org/zap"
@@ -17,8 +18,30 @@ type (
 const (
 	ContextName = "Components.Database"
 	PingTimeout = 10 * time.Second
+
+	defaultTxMaxRetries  = 3
+	defaultTxBackoffBase = 20 * time.Millisecond
+	defaultTxBackoffMax  = time.Second
 )
 
+// TxOpts represents the options of a transaction run by WithTransaction. Nested transactions run
+// in a savepoint of the outer transaction and ignore them.
+type TxOpts struct {
+	// Isolation is the isolation level, for example sql.LevelSerializable. Default is the level of
+	// the database.
+	Isolation sql.IsolationLevel
+	// ReadOnly starts a read-only transaction.
+	ReadOnly bool
+
+	// MaxRetries is the number of retries of a transaction failing with a serialization failure or a
+	// deadlock. Default is 3, negative disables retries.
+	MaxRetries int
+	// BackoffBase and BackoffMax bound the jittered exponential backoff between attempts.
+	// Defaults are 20ms and 1s.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
 // DBServiceOpts represents the options for configuring the database service.
 type DBServiceOpts struct {
 	// Debug enables debug mode.
@@ -35,11 +58,19 @@ type DBServiceOpts struct {
 
 	// Application Name (for tracing)
 	ApplicationName *string
+
+	// OnSlowQuery is called for every query slower than the slow threshold, for example to count it.
+	OnSlowQuery SlowQueryFn
+
+	// Plugins are registered on the GORM instance, for example to trace queries.
+	Plugins []gorm.Plugin
 }
 
 // DBService represents the database service.
 type DBService struct {
 	Gorm *gorm.DB
+
+	logger *zap.Logger
 }
 
 // IDBService represents the interface for the database service.
@@ -52,6 +83,19 @@ type IDBService interface {
 	//   - bool: true if the database is available, false otherwise.
 	Ping() bool
 
+	// WithTransaction runs fn in a transaction attached to the context, committed when fn returns nil
+	// and rolled back when it returns an error or panics. Nested calls run in a savepoint, rolled back
+	// alone. The transaction is run again after a serialization failure or a deadlock.
+	//
+	// Parameters:
+	//   - c: context
+	//   - opts: transaction options, nil for the defaults
+	//   - fn: function running the queries on GetTransaction of its context
+	//
+	// Returns:
+	//   - error: error of fn, or of beginning or committing the transaction
+	WithTransaction(c context.Context, opts *TxOpts, fn func(c context.Context) error) error
+
 	// BeginTransaction starts a new transaction and returns a new context with the transaction attached.
 	//
 	// Parameters:
@@ -62,7 +106,8 @@ type IDBService interface {
 	//   - *gorm.DB: transaction
 	BeginTransaction(c context.Context) (context.Context, *gorm.DB)
 
-	// GetTransaction returns the transaction attached to the context.
+	// GetTransaction returns the transaction attached to the context, locking the selected rows with
+	// the lock type attached to the context, or the database outside of a transaction.
 	//
 	// Parameters:
 	//   - c: context
@@ -116,11 +161,19 @@ type IDBService interface {
 	//   - *string: lock type
 	GetLockType(c context.Context) *string
 
-	// Defer defers the transaction attached to the context.
+	// Defer ends the transaction attached to the context, to be deferred after BeginTransaction: it
+	// rolls back when the function panics or returns an error, and commits otherwise.
 	//
 	// Parameters:
 	//   - c: context
-	Defer(c context.Context)
+	//   - err: named error result of the function, set to the commit error
+	Defer(c context.Context, err *error)
+
+	// Stats returns the connection pool statistics.
+	//
+	// Returns:
+	//   - sql.DBStats: connection pool statistics
+	Stats() sql.DBStats
 
 	// Close closes the database connection.
 	Close() error
@@ -2,10 +2,18 @@ package deps
 
 import (
 	"github.com/Alwanly/go-codebase/config"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/binding"
 	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/featureflag"
+	"github.com/Alwanly/go-codebase/pkg/httpclient"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
 	"github.com/Alwanly/go-codebase/pkg/middleware"
 	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+	"github.com/Alwanly/go-codebase/pkg/tracing"
 	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
@@ -13,11 +21,32 @@ import (
 type App struct {
 	Config    *config.GlobalConfig
 	Logger    *zap.Logger
-	DB        *database.DBService
-	Redis     *redis.Service
+	DB        database.IDBService
+	Redis     redis.IRedisService
 	Auth      *middleware.AuthMiddleware
+	Metrics   *metrics.Service
+	Tracing   *tracing.Service
 	Validator validator.IValidatorService
 
+	// Docs collects the OpenAPI operations of the handlers
+	Docs *binding.Docs
+
+	// Versioning registers the versions of the APIs
+	Versioning *versioning.Registry
+
+	// HTTPClient calls other APIs
+	HTTPClient *httpclient.Client
+
+	// Flags evaluates the feature flags
+	Flags *featureflag.Service
+
+	// Audit records the mutations
+	Audit *audit.Service
+
+	// Storage stores the uploaded files, served behind the URLs signed by Signer
+	Storage storage.IStorage
+	Signer  *storage.Signer
+
 	// APIs
 	Fiber *fiber.App
 }
@@ -0,0 +1,87 @@
+package errorreport
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const defaultAsyncQueueSize = 100
+
+var (
+	ErrQueueFull = errors.New("error report queue is full")
+	ErrClosed    = errors.New("error reporter is closed")
+)
+
+// AsyncReporter queues the reports and sends them from a background goroutine, so a slow error
+// tracker does not hold the request that panicked.
+type AsyncReporter struct {
+	logger   *zap.Logger
+	reporter ErrorReporter
+	queue    chan *Report
+	done     chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewAsyncReporter(opts *AsyncOpts) *AsyncReporter {
+	size := opts.QueueSize
+	if size <= 0 {
+		size = defaultAsyncQueueSize
+	}
+
+	a := &AsyncReporter{
+		logger:   logger.WithID(opts.Logger, ContextName, "AsyncReporter"),
+		reporter: opts.Reporter,
+		queue:    make(chan *Report, size),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// Report queues the report without waiting for it to be sent.
+func (a *AsyncReporter) Report(_ context.Context, r *Report) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return ErrClosed
+	}
+
+	select {
+	case a.queue <- r:
+		return nil
+	default:
+		a.logger.Error("Dropping error report, the queue is full", zap.String("reference.id", r.ID))
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting reports and waits until the queued ones are sent or ctx is done.
+func (a *AsyncReporter) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncReporter) run() {
+	defer close(a.done)
+	for r := range a.queue {
+		// the reporter logs its own failures
+		_ = a.reporter.Report(context.Background(), r)
+	}
+}
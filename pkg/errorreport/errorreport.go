@@ -0,0 +1,60 @@
+package errorreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// NewID returns a new report ID, usable as a Sentry event ID.
+func NewID() string {
+	return strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// CaptureStack returns the stack of the calling goroutine, skipping the given number of
+// frames above the caller of CaptureStack.
+func CaptureStack(skip int) []Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	stack := []Frame{}
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, Frame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// FormatStack formats the stack like a goroutine trace.
+func FormatStack(stack []Frame) string {
+	b := strings.Builder{}
+	for _, frame := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+	}
+	return b.String()
+}
+
+type multiReporter []ErrorReporter
+
+// Multi returns a reporter sending every report to all the given reporters.
+func Multi(reporters ...ErrorReporter) ErrorReporter {
+	return multiReporter(reporters)
+}
+
+func (m multiReporter) Report(ctx context.Context, r *Report) error {
+	errs := []error{}
+	for _, reporter := range m {
+		if err := reporter.Report(ctx, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,142 @@
+package errorreport_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/errorreport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newReport() *errorreport.Report {
+	return &errorreport.Report{
+		ID:      errorreport.NewID(),
+		Time:    time.Now(),
+		Panic:   "boom",
+		Message: "boom",
+		Stack:   errorreport.CaptureStack(0),
+		Request: &errorreport.Request{Method: http.MethodGet, URL: "/books/1", Route: "/books/:id", IP: "10.0.0.1"},
+		User:    &errorreport.User{ID: "user-1"},
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		path, auth, contentType string
+		lines                   []string
+	)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth, contentType = r.URL.Path, r.Header.Get("X-Sentry-Auth"), r.Header.Get("Content-Type")
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 1<<20), 1<<20)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer stub.Close()
+
+	dsn := strings.Replace(stub.URL, "http://", "http://public@", 1) + "/sentry/42"
+	reporter, err := errorreport.NewSentryReporter(&errorreport.SentryOpts{Logger: zap.NewNop(), DSN: dsn, Environment: "test"})
+	require.NoError(t, err)
+
+	report := newReport()
+	require.NoError(t, reporter.Report(context.Background(), report))
+
+	assert.Equal(t, "/sentry/api/42/envelope/", path)
+	assert.Contains(t, auth, "sentry_key=public")
+	assert.Equal(t, "application/x-sentry-envelope", contentType)
+	require.Len(t, lines, 3)
+
+	header := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &header))
+	assert.Equal(t, report.ID, header["event_id"])
+
+	item := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &item))
+	assert.Equal(t, "event", item["type"])
+	assert.EqualValues(t, len(lines[2]), item["length"])
+
+	event := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+	assert.Equal(t, report.ID, event["event_id"])
+	assert.Equal(t, "test", event["environment"])
+	assert.Equal(t, "user-1", event["user"].(map[string]interface{})["id"])
+	assert.Equal(t, "GET /books/:id", event["transaction"])
+}
+
+func TestSentryReporter_Rejected(t *testing.T) {
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer stub.Close()
+
+	dsn := strings.Replace(stub.URL, "http://", "http://public@", 1) + "/1"
+	reporter, err := errorreport.NewSentryReporter(&errorreport.SentryOpts{Logger: zap.NewNop(), DSN: dsn})
+	require.NoError(t, err)
+	assert.Error(t, reporter.Report(context.Background(), newReport()))
+}
+
+func TestNewSentryReporter_InvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"", "https://sentry.example.com/1", "https://public@sentry.example.com/"} {
+		_, err := errorreport.NewSentryReporter(&errorreport.SentryOpts{Logger: zap.NewNop(), DSN: dsn})
+		assert.ErrorIs(t, err, errorreport.ErrInvalidDSN, dsn)
+	}
+}
+
+func TestZapReporter(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	report := newReport()
+
+	require.NoError(t, errorreport.NewZapReporter(zap.New(core)).Report(context.Background(), report))
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, report.ID, fields["reference.id"])
+	assert.Equal(t, "user-1", fields["user.id"])
+	assert.Contains(t, fields["error.stack_trace"], "TestZapReporter")
+}
+
+// blockingReporter records the reports once released.
+type blockingReporter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	ids     []string
+}
+
+func (b *blockingReporter) Report(_ context.Context, r *errorreport.Report) error {
+	<-b.release
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ids = append(b.ids, r.ID)
+	return nil
+}
+
+func TestAsyncReporter(t *testing.T) {
+	slow := &blockingReporter{release: make(chan struct{})}
+	reporter := errorreport.NewAsyncReporter(&errorreport.AsyncOpts{Logger: zap.NewNop(), Reporter: slow, QueueSize: 1})
+
+	// the reports are queued without waiting for the delivery
+	first, second, third := newReport(), newReport(), newReport()
+	require.NoError(t, reporter.Report(context.Background(), first))
+	require.Eventually(t, func() bool {
+		// the worker took the first report, the queue has room for one more
+		return reporter.Report(context.Background(), second) == nil
+	}, time.Second, time.Millisecond)
+	assert.ErrorIs(t, reporter.Report(context.Background(), third), errorreport.ErrQueueFull)
+
+	// closing sends the queued reports
+	close(slow.release)
+	require.NoError(t, reporter.Close(context.Background()))
+	assert.Equal(t, []string{first.ID, second.ID}, slow.ids)
+	assert.ErrorIs(t, reporter.Report(context.Background(), newReport()), errorreport.ErrClosed)
+}
@@ -0,0 +1,193 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	sentryClient         = "go-codebase/1.0"
+	sentryEnvelopeMIME   = "application/x-sentry-envelope"
+	defaultSentryTimeout = 5 * time.Second
+)
+
+var ErrInvalidDSN = errors.New("invalid sentry DSN")
+
+// SentryReporter sends crash reports as Sentry envelopes over HTTP. It works with Sentry and
+// with any service accepting the same envelope endpoint.
+type SentryReporter struct {
+	logger      *zap.Logger
+	client      *http.Client
+	dsn         string
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	timeout     time.Duration
+}
+
+func NewSentryReporter(opts *SentryOpts) (*SentryReporter, error) {
+	l := logger.WithID(opts.Logger, ContextName, "NewSentryReporter")
+
+	// parse DSN: {scheme}://{public_key}@{host}{/path}/{project_id}
+	dsn, err := url.Parse(opts.DSN)
+	if err != nil || dsn.User == nil || dsn.User.Username() == "" || dsn.Host == "" {
+		l.Error("Cannot parse sentry DSN", zap.Error(ErrInvalidDSN))
+		return nil, ErrInvalidDSN
+	}
+	path := strings.TrimSuffix(dsn.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	projectID := path[idx+1:]
+	if projectID == "" {
+		l.Error("Sentry DSN has no project ID", zap.Error(ErrInvalidDSN))
+		return nil, ErrInvalidDSN
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultSentryTimeout
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: timeout}
+	}
+
+	return &SentryReporter{
+		logger:      logger.WithID(opts.Logger, ContextName, "SentryReporter"),
+		client:      client,
+		dsn:         opts.DSN,
+		endpoint:    fmt.Sprintf("%s://%s%s/api/%s/envelope/", dsn.Scheme, dsn.Host, path[:idx], projectID),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", dsn.User.Username(), sentryClient),
+		environment: opts.Environment,
+		release:     opts.Release,
+		timeout:     timeout,
+	}, nil
+}
+
+func (s *SentryReporter) Report(ctx context.Context, r *Report) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	body, err := s.envelope(r)
+	if err != nil {
+		s.logger.Error("Cannot encode sentry envelope", zap.String("reference.id", r.ID), zap.Error(err))
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", sentryEnvelopeMIME)
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Error("Cannot send sentry envelope", zap.String("reference.id", r.ID), zap.Error(err))
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err := fmt.Errorf("sentry responded with status %d", resp.StatusCode)
+		s.logger.Error("Sentry rejected envelope", zap.String("reference.id", r.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// envelope encodes the report as an envelope holding a single event item.
+func (s *SentryReporter) envelope(r *Report) ([]byte, error) {
+	event, err := json.Marshal(s.event(r))
+	if err != nil {
+		return nil, err
+	}
+	header, err := json.Marshal(map[string]string{
+		"event_id": r.ID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+		"dsn":      s.dsn,
+	})
+	if err != nil {
+		return nil, err
+	}
+	item, err := json.Marshal(map[string]interface{}{"type": "event", "length": len(event)})
+	if err != nil {
+		return nil, err
+	}
+
+	b := bytes.Buffer{}
+	b.Write(header)
+	b.WriteByte('\n')
+	b.Write(item)
+	b.WriteByte('\n')
+	b.Write(event)
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+func (s *SentryReporter) event(r *Report) map[string]interface{} {
+	// sentry expects the outermost frame first
+	frames := make([]map[string]interface{}, 0, len(r.Stack))
+	for i := len(r.Stack) - 1; i >= 0; i-- {
+		frames = append(frames, map[string]interface{}{
+			"function": r.Stack[i].Function,
+			"abs_path": r.Stack[i].File,
+			"lineno":   r.Stack[i].Line,
+		})
+	}
+
+	tags := map[string]string{"reference_id": r.ID}
+	event := map[string]interface{}{
+		"event_id":    r.ID,
+		"timestamp":   r.Time.UTC().Format(time.RFC3339Nano),
+		"level":       "fatal",
+		"platform":    "go",
+		"environment": s.environment,
+		"release":     s.release,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{
+				"type":       fmt.Sprintf("%T", r.Panic),
+				"value":      r.Message,
+				"mechanism":  map[string]interface{}{"type": "panic", "handled": false},
+				"stacktrace": map[string]interface{}{"frames": frames},
+			}},
+		},
+		"tags": tags,
+	}
+	if r.Request != nil {
+		if r.Request.ID != "" {
+			tags["request_id"] = r.Request.ID
+		}
+		event["request"] = map[string]interface{}{
+			"method":  r.Request.Method,
+			"url":     r.Request.URL,
+			"headers": r.Request.Headers,
+		}
+		event["transaction"] = r.Request.Method + " " + r.Request.Route
+	}
+	if r.User != nil {
+		user := map[string]string{"id": r.User.ID}
+		if r.Request != nil {
+			user["ip_address"] = r.Request.IP
+		}
+		event["user"] = user
+	}
+	if r.TraceID != "" {
+		event["contexts"] = map[string]interface{}{
+			"trace": map[string]string{"trace_id": r.TraceID, "span_id": r.SpanID},
+		}
+	}
+	return event
+}
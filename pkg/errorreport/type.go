@@ -0,0 +1,96 @@
+package errorreport
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const ContextName = "Components.ErrorReport"
+
+// ErrorReporter forwards a crash report, for example to the logs or to an error tracker.
+type ErrorReporter interface {
+	// Report sends the crash report.
+	//
+	// Parameters:
+	//   - ctx: the context
+	//   - r: the crash report
+	//
+	// Returns:
+	//   - error: error if the report cannot be sent
+	Report(ctx context.Context, r *Report) error
+}
+
+// Report represents a recovered panic with the request it happened in.
+type Report struct {
+	// ID is the reference ID returned to the client, 32 hex characters.
+	ID   string
+	Time time.Time
+
+	// Panic is the recovered value and Message its formatted form.
+	Panic   interface{}
+	Message string
+	// Stack is the stack of the panicking goroutine, innermost frame first.
+	Stack []Frame
+
+	Request *Request
+	User    *User
+
+	// TraceID and SpanID identify the active span, if any.
+	TraceID string
+	SpanID  string
+}
+
+// Frame is a single stack frame.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Request is the request metadata attached to a report. Sensitive headers are filtered.
+type Request struct {
+	// ID is the X-Request-ID of the request, unrelated to the report ID.
+	ID      string
+	Method  string
+	URL     string
+	Route   string
+	IP      string
+	Headers map[string]string
+}
+
+// User is the authenticated user of the request.
+type User struct {
+	ID string
+}
+
+// SentryOpts represents the options for the Sentry-compatible reporter.
+type SentryOpts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+
+	// DSN is the project DSN, for example https://public@sentry.example.com/42.
+	DSN string
+	// Environment and Release are attached to every event.
+	Environment string
+	Release     string
+
+	// Timeout bounds a single delivery. Default is 5s.
+	Timeout time.Duration
+	// HTTPClient is the client used to deliver envelopes. Default is a client with Timeout.
+	HTTPClient *http.Client
+}
+
+// AsyncOpts represents the options for the asynchronous reporter.
+type AsyncOpts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+
+	// Reporter receives the reports from the background goroutine.
+	Reporter ErrorReporter
+	// QueueSize is the number of reports waiting to be sent, newer reports are dropped when it is
+	// full. Default is 100.
+	QueueSize int
+}
@@ -0,0 +1,43 @@
+package errorreport
+
+import (
+	"context"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ZapReporter writes crash reports to the logs.
+type ZapReporter struct {
+	logger *zap.Logger
+}
+
+func NewZapReporter(l *zap.Logger) *ZapReporter {
+	return &ZapReporter{logger: logger.WithID(l, ContextName, "ZapReporter")}
+}
+
+func (z *ZapReporter) Report(_ context.Context, r *Report) error {
+	fields := []zap.Field{
+		zap.String("reference.id", r.ID),
+		zap.String("error.message", r.Message),
+		zap.String("error.stack_trace", FormatStack(r.Stack)),
+	}
+	if r.Request != nil {
+		fields = append(fields,
+			zap.String("http.request.id", r.Request.ID),
+			zap.String("http.request.method", r.Request.Method),
+			zap.String("url.original", r.Request.URL),
+			zap.String("http.route", r.Request.Route),
+			zap.String("client.ip", r.Request.IP),
+		)
+	}
+	if r.User != nil {
+		fields = append(fields, zap.String("user.id", r.User.ID))
+	}
+	if r.TraceID != "" {
+		fields = append(fields, zap.String("trace.id", r.TraceID), zap.String("span.id", r.SpanID))
+	}
+
+	z.logger.Error("Recovered from panic", fields...)
+	return nil
+}
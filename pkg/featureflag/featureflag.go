@@ -0,0 +1,75 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"go.uber.org/zap"
+)
+
+func NewFeatureFlag(opts *Opts) *Service {
+	return &Service{
+		Provider: opts.Provider,
+		logger:   opts.Logger,
+	}
+}
+
+func (s *Service) Enabled(ctx context.Context, name string) bool {
+	subject := Subject{}
+	if user, ok := middleware.UserFromContext(ctx); ok {
+		subject = Subject{UserID: user.UserID, Roles: user.Roles, TenantID: user.TenantID}
+	}
+	return s.EnabledFor(ctx, name, subject)
+}
+
+func (s *Service) EnabledFor(ctx context.Context, name string, subject Subject) bool {
+	flag, err := s.Provider.Get(ctx, name)
+	if err != nil {
+		if !errors.Is(err, ErrFlagNotFound) {
+			logger.WithContext(ctx, logger.WithID(s.logger, ContextName, "EnabledFor")).
+				Warn("Cannot read feature flag, evaluate as disabled", zap.String("flag", name), zap.Error(err))
+		}
+		return false
+	}
+	return Evaluate(flag, subject)
+}
+
+// Evaluate reports whether the flag is on for the subject. A disabled flag is off for everyone.
+// An enabled flag is on for the targeted users, roles and tenants, and for Percentage percent of
+// the other users, bucketed by user ID so a user keeps the same result.
+func Evaluate(flag *model.FeatureFlag, subject Subject) bool {
+	if flag == nil || !flag.Enabled {
+		return false
+	}
+
+	if subject.UserID != "" && utils.AnyInSlice(flag.UserIDs, subject.UserID) {
+		return true
+	}
+	if utils.AnySliceInSlice(subject.Roles, flag.Roles) {
+		return true
+	}
+	if subject.TenantID != "" && utils.AnyInSlice(flag.TenantIDs, subject.TenantID) {
+		return true
+	}
+
+	switch {
+	case flag.Percentage >= 100:
+		return true
+	case flag.Percentage <= 0 || subject.UserID == "":
+		return false
+	default:
+		return bucket(flag.Name, subject.UserID) < flag.Percentage
+	}
+}
+
+// bucket returns the rollout bucket of the user for the flag, 0 to 99.
+func bucket(name string, userID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + userID))
+	return int(h.Sum32() % 100)
+}
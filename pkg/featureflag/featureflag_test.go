@@ -0,0 +1,368 @@
+package featureflag_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/authentication"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/featureflag"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    model.FeatureFlag
+		subject featureflag.Subject
+		enabled bool
+	}{
+		{"disabled", model.FeatureFlag{Enabled: false, Percentage: 100}, featureflag.Subject{UserID: "u1"}, false},
+		{"boolean on", model.FeatureFlag{Enabled: true, Percentage: 100}, featureflag.Subject{}, true},
+		{"targeted user", model.FeatureFlag{Enabled: true, UserIDs: []string{"u1"}}, featureflag.Subject{UserID: "u1"}, true},
+		{"targeted role", model.FeatureFlag{Enabled: true, Roles: []string{"beta"}}, featureflag.Subject{UserID: "u2", Roles: []string{"beta"}}, true},
+		{"targeted tenant", model.FeatureFlag{Enabled: true, TenantIDs: []string{"t1"}}, featureflag.Subject{TenantID: "t1"}, true},
+		{"not targeted", model.FeatureFlag{Enabled: true, UserIDs: []string{"u1"}}, featureflag.Subject{UserID: "u2"}, false},
+		{"rollout without user", model.FeatureFlag{Enabled: true, Percentage: 50}, featureflag.Subject{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.enabled, featureflag.Evaluate(&tt.flag, tt.subject))
+		})
+	}
+}
+
+func TestEvaluate_Percentage(t *testing.T) {
+	flag := &model.FeatureFlag{Name: "new-search", Enabled: true, Percentage: 30}
+
+	enabled := 0
+	for i := 0; i < 10000; i++ {
+		subject := featureflag.Subject{UserID: fmt.Sprintf("user-%d", i)}
+		result := featureflag.Evaluate(flag, subject)
+		// the result is stable for a user
+		assert.Equal(t, result, featureflag.Evaluate(flag, subject))
+		if result {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 3000, enabled, 300)
+}
+
+func TestService_Enabled(t *testing.T) {
+	flags := featureflag.NewFeatureFlag(&featureflag.Opts{
+		Logger:   zap.NewNop(),
+		Provider: featureflag.NewMemoryProvider(model.FeatureFlag{Name: "beta", Enabled: true, Roles: []string{"beta"}}),
+	})
+
+	ctx := middleware.WithUser(context.Background(), &middleware.AuthUserData{UserID: "u1", Roles: []string{"beta"}})
+	assert.True(t, flags.Enabled(ctx, "beta"))
+	assert.False(t, flags.Enabled(context.Background(), "beta"))
+	assert.False(t, flags.Enabled(ctx, "unknown"))
+}
+
+func TestCachedProvider(t *testing.T) {
+	mr := miniredis.RunT(t)
+	memory := featureflag.NewMemoryProvider(model.FeatureFlag{Name: "beta", Enabled: true})
+	cached := featureflag.NewCachedProvider(zap.NewNop(), memory, &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})}, 0)
+	ctx := context.Background()
+
+	flag, err := cached.Get(ctx, "beta")
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	assert.True(t, mr.Exists("featureflag:beta"))
+
+	// reads are served from the cache
+	require.NoError(t, memory.Save(ctx, &model.FeatureFlag{Name: "beta", Enabled: false}))
+	flag, _ = cached.Get(ctx, "beta")
+	assert.True(t, flag.Enabled)
+
+	// writes through the cache invalidate it
+	require.NoError(t, cached.Save(ctx, &model.FeatureFlag{Name: "beta", Enabled: false}))
+	assert.False(t, mr.Exists("featureflag:beta"))
+	flag, _ = cached.Get(ctx, "beta")
+	assert.False(t, flag.Enabled)
+
+	_, err = cached.Get(ctx, "unknown")
+	assert.ErrorIs(t, err, featureflag.ErrFlagNotFound)
+
+	// misses are cached for a shorter time
+	assert.True(t, mr.Exists("featureflag:unknown"))
+	assert.Equal(t, 5*time.Second, mr.TTL("featureflag:unknown"))
+	require.NoError(t, memory.Save(ctx, &model.FeatureFlag{Name: "unknown", Enabled: true}))
+	_, err = cached.Get(ctx, "unknown")
+	assert.ErrorIs(t, err, featureflag.ErrFlagNotFound)
+
+	// creates through the cache invalidate the miss
+	require.NoError(t, cached.Create(ctx, &model.FeatureFlag{Name: "created", Enabled: true}))
+	flag, err = cached.Get(ctx, "created")
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	assert.ErrorIs(t, cached.Create(ctx, &model.FeatureFlag{Name: "created"}), featureflag.ErrFlagExists)
+}
+
+func TestHandler_CRUD(t *testing.T) {
+	provider := featureflag.NewMemoryProvider()
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	h := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/flags", h.List)
+	app.Post("/flags", h.Create)
+	app.Get("/flags/:name", h.Get)
+	app.Put("/flags/:name", h.Update)
+	app.Delete("/flags/:name", h.Delete)
+
+	send := func(method string, path string, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusCreated, send(http.MethodPost, "/flags", `{"name":"beta","enabled":true}`))
+	assert.Equal(t, http.StatusConflict, send(http.MethodPost, "/flags", `{"name":"beta"}`))
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/flags", `{"name":"rollout","percentage":101}`))
+
+	flag, err := provider.Get(context.Background(), "beta")
+	require.NoError(t, err)
+	assert.Equal(t, 100, flag.Percentage)
+
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/flags/beta", `{"enabled":true,"percentage":10,"roles":["beta"]}`))
+	flag, _ = provider.Get(context.Background(), "beta")
+	assert.Equal(t, 10, flag.Percentage)
+	assert.Equal(t, []string{"beta"}, flag.Roles)
+
+	// an update without percentage keeps the rollout
+	assert.Equal(t, http.StatusOK, send(http.MethodPut, "/flags/beta", `{"enabled":false}`))
+	flag, _ = provider.Get(context.Background(), "beta")
+	assert.Equal(t, 10, flag.Percentage)
+	assert.False(t, flag.Enabled)
+
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/flags/beta", ""))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/flags", ""))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodPut, "/flags/unknown", `{"enabled":true}`))
+	assert.Equal(t, http.StatusOK, send(http.MethodDelete, "/flags/beta", ""))
+	assert.Equal(t, http.StatusNotFound, send(http.MethodGet, "/flags/beta", ""))
+}
+
+func TestHandler_ConcurrentCreate(t *testing.T) {
+	provider := featureflag.NewMemoryProvider()
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	h := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/flags", h.Create)
+
+	var created, conflicts atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/flags", strings.NewReader(`{"name":"beta","enabled":true}`))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := app.Test(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			switch resp.StatusCode {
+			case http.StatusCreated:
+				created.Add(1)
+			case http.StatusConflict:
+				conflicts.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, created.Load())
+	assert.EqualValues(t, 9, conflicts.Load())
+}
+
+// recordingAudit keeps the audit logs instead of storing them.
+type recordingAudit struct {
+	logs []*model.AuditLog
+}
+
+func (a *recordingAudit) Record(ctx context.Context, entry audit.Entry) error {
+	log, err := audit.NewLog(ctx, entry)
+	if err != nil {
+		return err
+	}
+	a.logs = append(a.logs, log)
+	return nil
+}
+
+func (a *recordingAudit) List(context.Context, audit.Filter) ([]model.AuditLog, int64, error) {
+	return nil, 0, nil
+}
+
+func TestHandler_BasicAuthUserAudited(t *testing.T) {
+	provider := featureflag.NewMemoryProvider()
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	recorder := &recordingAudit{}
+	h := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v, Audit: recorder})
+	auth := middleware.NewAuthMiddleware(
+		middleware.SetJwtAuth(&authentication.JWTConfig{}),
+		middleware.SetBasicAuth(&authentication.BasicAuthTConfig{Username: "admin", Password: "secret"}),
+	)
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	admin := app.Group("/flags", auth.BasicAuth())
+	admin.Post("/", h.Create)
+	admin.Put("/:name", h.Update)
+	admin.Delete("/:name", h.Delete)
+
+	send := func(method string, path string, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.SetBasicAuth("admin", "secret")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/flags", `{"name":"beta","enabled":true}`))
+	flag, err := provider.Get(context.Background(), "beta")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", flag.UpdatedBy)
+
+	require.Equal(t, http.StatusOK, send(http.MethodPut, "/flags/beta", `{"enabled":false}`))
+	require.Equal(t, http.StatusOK, send(http.MethodDelete, "/flags/beta", ""))
+	require.Equal(t, http.StatusCreated, send(http.MethodPost, "/flags", `{"name":"beta"}`))
+	// a rejected change is not recorded
+	require.Equal(t, http.StatusConflict, send(http.MethodPost, "/flags", `{"name":"beta"}`))
+
+	require.Len(t, recorder.logs, 4)
+	for i, action := range []audit.Action{audit.ActionCreate, audit.ActionUpdate, audit.ActionDelete, audit.ActionCreate} {
+		assert.Equal(t, string(action), recorder.logs[i].Action)
+		assert.Equal(t, featureflag.AuditEntityType, recorder.logs[i].EntityType)
+		assert.Equal(t, "beta", recorder.logs[i].EntityID)
+		assert.Equal(t, "admin", recorder.logs[i].ActorID)
+	}
+	assert.Equal(t, model.AuditChange{Before: true, After: false}, recorder.logs[1].Changes["enabled"])
+}
+
+// fakeTxDB runs the transactions in memory, counting the committed ones.
+type fakeTxDB struct {
+	database.IDBService
+	inTransaction bool
+	committed     int
+}
+
+func (db *fakeTxDB) WithTransaction(ctx context.Context, _ *database.TxOpts, fn func(ctx context.Context) error) error {
+	db.inTransaction = true
+	defer func() { db.inTransaction = false }()
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	db.committed++
+	return nil
+}
+
+func (db *fakeTxDB) SetUpdateLockType(ctx context.Context) context.Context {
+	return ctx
+}
+
+// recordingCache keeps the invalidated flags and whether a transaction was running.
+type recordingCache struct {
+	db          *fakeTxDB
+	invalidated []string
+}
+
+func (c *recordingCache) Invalidate(_ context.Context, name string) {
+	if !c.db.inTransaction {
+		c.invalidated = append(c.invalidated, name)
+	}
+}
+
+// failingAudit fails every record.
+type failingAudit struct {
+	recordingAudit
+}
+
+func (a *failingAudit) Record(context.Context, audit.Entry) error {
+	return errors.New("connection reset")
+}
+
+func TestHandler_AuditedInTransaction(t *testing.T) {
+	provider := featureflag.NewMemoryProvider(model.FeatureFlag{Name: "beta", Enabled: true})
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	db := &fakeTxDB{}
+	cache := &recordingCache{db: db}
+	recorder := &recordingAudit{}
+	h := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v, Audit: recorder, DB: db, Cache: cache})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Put("/flags/:name", h.Update)
+
+	req := httptest.NewRequest(http.MethodPut, "/flags/beta", strings.NewReader(`{"enabled":false}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, db.committed)
+	require.Len(t, recorder.logs, 1)
+	// the cached flag is dropped after the commit
+	assert.Equal(t, []string{"beta"}, cache.invalidated)
+
+	// a change that cannot be audited is rolled back
+	failing := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v, Audit: &failingAudit{}, DB: db, Cache: cache})
+	app = fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Delete("/flags/:name", failing.Delete)
+
+	resp, err = app.Test(httptest.NewRequest(http.MethodDelete, "/flags/beta", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, 1, db.committed)
+	assert.Equal(t, []string{"beta"}, cache.invalidated)
+}
+
+func TestHandler_CreateTargetedFlag(t *testing.T) {
+	provider := featureflag.NewMemoryProvider()
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	h := featureflag.NewHandler(&featureflag.HandlerOpts{Logger: zap.NewNop(), Provider: provider, Validator: v})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Post("/flags", h.Create)
+
+	req := httptest.NewRequest(http.MethodPost, "/flags", strings.NewReader(`{"name":"dark-launch","enabled":true,"userIds":["u-1"]}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// a targeted flag without percentage is only on for its targets
+	flag, err := provider.Get(context.Background(), "dark-launch")
+	require.NoError(t, err)
+	assert.Equal(t, 0, flag.Percentage)
+	assert.True(t, featureflag.Evaluate(flag, featureflag.Subject{UserID: "u-1"}))
+	for i := 0; i < 20; i++ {
+		assert.False(t, featureflag.Evaluate(flag, featureflag.Subject{UserID: fmt.Sprintf("user-%d", i)}))
+	}
+}
@@ -0,0 +1,268 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorFlagNotFound = "Feature flag not found"
+	ErrorFlagExists   = "Feature flag already exists"
+)
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		ErrorFlagNotFound: "Feature flag tidak ditemukan",
+		ErrorFlagExists:   "Feature flag sudah ada",
+	})
+}
+
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:    opts.Logger,
+		provider:  opts.Provider,
+		validator: opts.Validator,
+		audit:     opts.Audit,
+		db:        opts.DB,
+		cache:     opts.Cache,
+	}
+}
+
+// List returns every flag.
+func (h *Handler) List(c *fiber.Ctx) error {
+	flags, err := h.provider.List(c.UserContext())
+	if err != nil {
+		return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+	return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, flags))
+}
+
+// Get returns a flag by name.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Get")
+
+	// bind request
+	req := &RequestFlagGet{}
+	if err := binding.BindModel(l, c, req, binding.BindFromParams()); err != nil {
+		return err
+	}
+
+	// validate request
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, req, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	flag, err := h.find(c.UserContext(), req.Name)
+	if err != nil {
+		return err
+	}
+	return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, flag))
+}
+
+// Create creates a flag.
+func (h *Handler) Create(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Create")
+
+	// bind request
+	req := &RequestFlagSave{}
+	if err := binding.BindModel(l, c, req, binding.BindFromBody()); err != nil {
+		return err
+	}
+
+	// validate request
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, req, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	// a flag without percentage is a boolean flag, on for everyone when enabled, unless it
+	// targets users, roles or tenants: then it is only on for them
+	percentage := 100
+	if len(req.UserIDs) > 0 || len(req.Roles) > 0 || len(req.TenantIDs) > 0 {
+		percentage = 0
+	}
+	flag := h.newFlag(c, req, percentage)
+
+	err := h.transaction(c, flag.Name, func(ctx context.Context) error {
+		// the insert fails on conflict, two concurrent creates cannot both succeed
+		err := h.provider.Create(ctx, flag)
+		if errors.Is(err, ErrFlagExists) {
+			return apperror.Conflict(contract.StatusCodeConflict, ErrorFlagExists)
+		}
+		if err != nil {
+			return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToInsertRecord).Wrap(err)
+		}
+		return h.record(ctx, l, audit.Entry{Action: audit.ActionCreate, EntityType: AuditEntityType, EntityID: flag.Name, After: flag})
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, flag.Name, http.StatusCreated)
+}
+
+// Update replaces a flag.
+func (h *Handler) Update(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Update")
+
+	// bind request, the name comes from the path
+	req := &RequestFlagSave{}
+	if err := binding.BindModel(l, c, req, binding.BindFromBody(), binding.BindFromParams()); err != nil {
+		return err
+	}
+
+	// validate request
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, req, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	err := h.transaction(c, req.Name, func(ctx context.Context) error {
+		existing, err := h.find(h.lock(ctx), req.Name)
+		if err != nil {
+			return err
+		}
+
+		// a body without percentage keeps the current rollout
+		flag := h.newFlag(c, req, existing.Percentage)
+		if err := h.provider.Save(ctx, flag); err != nil {
+			return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToUpdateRecord).Wrap(err)
+		}
+		return h.record(ctx, l, audit.Entry{Action: audit.ActionUpdate, EntityType: AuditEntityType, EntityID: flag.Name, Before: existing, After: flag})
+	})
+	if err != nil {
+		return err
+	}
+
+	return h.respond(c, req.Name, http.StatusOK)
+}
+
+// Delete deletes a flag.
+func (h *Handler) Delete(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Delete")
+
+	// bind request
+	req := &RequestFlagGet{}
+	if err := binding.BindModel(l, c, req, binding.BindFromParams()); err != nil {
+		return err
+	}
+
+	// validate request
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, req, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	err := h.transaction(c, req.Name, func(ctx context.Context) error {
+		existing, err := h.find(h.lock(ctx), req.Name)
+		if err != nil {
+			return err
+		}
+
+		err = h.provider.Delete(ctx, req.Name)
+		if errors.Is(err, ErrFlagNotFound) {
+			return apperror.NotFound(contract.StatusCodeNotFound, ErrorFlagNotFound)
+		}
+		if err != nil {
+			return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToDeleteRecord).Wrap(err)
+		}
+		return h.record(ctx, l, audit.Entry{Action: audit.ActionDelete, EntityType: AuditEntityType, EntityID: req.Name, Before: existing})
+	})
+	if err != nil {
+		return err
+	}
+
+	return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, nil))
+}
+
+func (h *Handler) find(ctx context.Context, name string) (*model.FeatureFlag, error) {
+	flag, err := h.provider.Get(ctx, name)
+	if errors.Is(err, ErrFlagNotFound) {
+		return nil, apperror.NotFound(contract.StatusCodeNotFound, ErrorFlagNotFound)
+	}
+	if err != nil {
+		return nil, apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+	return flag, nil
+}
+
+// newFlag builds the flag of the request, using percentage when the request has none.
+func (h *Handler) newFlag(c *fiber.Ctx, req *RequestFlagSave, percentage int) *model.FeatureFlag {
+	flag := &model.FeatureFlag{
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Percentage:  utils.GetValue(utils.Either(req.Percentage, &percentage)),
+		UserIDs:     req.UserIDs,
+		Roles:       req.Roles,
+		TenantIDs:   req.TenantIDs,
+		UpdatedAt:   time.Now(),
+	}
+	if user, ok := middleware.UserFromContext(c.UserContext()); ok {
+		flag.UpdatedBy = user.UserID
+	}
+	return flag
+}
+
+func (h *Handler) respond(c *fiber.Ctx, name string, status int) error {
+	saved, err := h.find(c.UserContext(), name)
+	if err != nil {
+		return err
+	}
+	return wrapper.Send(c, wrapper.ResponseSuccess(status, saved))
+}
+
+// transaction runs fn in a transaction of DB, when set, and drops the cached flag once the change
+// is committed, so a concurrent read cannot cache the replaced flag again.
+func (h *Handler) transaction(c *fiber.Ctx, name string, fn func(ctx context.Context) error) error {
+	ctx := c.UserContext()
+	var err error
+	if h.db != nil {
+		err = h.db.WithTransaction(ctx, nil, fn)
+	} else {
+		err = fn(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	// the change is committed already, the flag must be dropped even when the deadline has passed
+	if h.cache != nil {
+		h.cache.Invalidate(context.WithoutCancel(ctx), name)
+	}
+	return nil
+}
+
+// lock returns a context locking the rows read in the transaction until it ends, so the audit log
+// diffs the replaced flag.
+func (h *Handler) lock(ctx context.Context) context.Context {
+	if h.db == nil {
+		return ctx
+	}
+	return h.db.SetUpdateLockType(ctx)
+}
+
+// record stores the audit log of a flag change in the transaction of the change.
+func (h *Handler) record(ctx context.Context, l *zap.Logger, entry audit.Entry) error {
+	if h.audit == nil {
+		return nil
+	}
+	if err := h.audit.Record(ctx, entry); err != nil {
+		l.Error("Cannot record the feature flag change", zap.String("flag", entry.EntityID), zap.Error(err))
+		return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToInsertRecord).Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresProvider stores the flags in the feature_flags table.
+type PostgresProvider struct {
+	db database.IDBService
+}
+
+func NewPostgresProvider(db database.IDBService) *PostgresProvider {
+	return &PostgresProvider{db: db}
+}
+
+func (p *PostgresProvider) Get(ctx context.Context, name string) (*model.FeatureFlag, error) {
+	flag := model.FeatureFlag{}
+	err := p.db.GetTransaction(ctx).Where("name = ?", name).First(&flag).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFlagNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (p *PostgresProvider) List(ctx context.Context) ([]model.FeatureFlag, error) {
+	flags := []model.FeatureFlag{}
+	if err := p.db.GetTransaction(ctx).Order("name").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func (p *PostgresProvider) Create(ctx context.Context, flag *model.FeatureFlag) error {
+	result := p.db.GetTransaction(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(flag)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFlagExists
+	}
+	return nil
+}
+
+func (p *PostgresProvider) Save(ctx context.Context, flag *model.FeatureFlag) error {
+	return p.db.GetTransaction(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "enabled", "percentage", "user_ids", "roles", "tenant_ids", "updated_at", "updated_by"}),
+	}).Create(flag).Error
+}
+
+func (p *PostgresProvider) Delete(ctx context.Context, name string) error {
+	result := p.db.GetTransaction(ctx).Where("name = ?", name).Delete(&model.FeatureFlag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFlagNotFound
+	}
+	return nil
+}
+
+// CachedProvider caches the flags of another provider in Redis. Writes invalidate the cached
+// flag, other instances see the change after TTL at the latest. Unknown flags are cached as
+// well, for a shorter TTL, so checks of a missing flag don't hit the provider every time.
+type CachedProvider struct {
+	provider  Provider
+	redis     redis.IRedisService
+	logger    *zap.Logger
+	keyPrefix string
+	ttl       time.Duration
+	missTTL   time.Duration
+}
+
+// NewCachedProvider wraps the provider with a Redis cache. Zero ttl uses 30s.
+func NewCachedProvider(l *zap.Logger, provider Provider, r redis.IRedisService, ttl time.Duration) *CachedProvider {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &CachedProvider{
+		provider:  provider,
+		redis:     r,
+		logger:    l,
+		keyPrefix: defaultCacheKeyPrefix,
+		ttl:       ttl,
+		missTTL:   min(ttl, defaultMissTTL),
+	}
+}
+
+func (p *CachedProvider) Get(ctx context.Context, name string) (*model.FeatureFlag, error) {
+	key := p.keyPrefix + name
+	if raw, err := p.redis.Get(ctx, key); err == nil {
+		if raw == cachedMiss {
+			return nil, ErrFlagNotFound
+		}
+		flag := model.FeatureFlag{}
+		if err := utils.JSONUnMarshal([]byte(raw), &flag); err == nil {
+			return &flag, nil
+		}
+	} else if !errors.Is(err, redis.ErrNil) {
+		p.logger.Warn("Cannot read cached feature flag", zap.String("flag", name), zap.Error(err))
+	}
+
+	flag, err := p.provider.Get(ctx, name)
+	if errors.Is(err, ErrFlagNotFound) {
+		if err := p.redis.Set(ctx, key, cachedMiss, p.missTTL); err != nil {
+			p.logger.Warn("Cannot cache feature flag", zap.String("flag", name), zap.Error(err))
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raw, err := utils.JSONMarshal(flag); err == nil {
+		if err := p.redis.Set(ctx, key, raw, p.ttl); err != nil {
+			p.logger.Warn("Cannot cache feature flag", zap.String("flag", name), zap.Error(err))
+		}
+	}
+	return flag, nil
+}
+
+func (p *CachedProvider) List(ctx context.Context) ([]model.FeatureFlag, error) {
+	return p.provider.List(ctx)
+}
+
+func (p *CachedProvider) Create(ctx context.Context, flag *model.FeatureFlag) error {
+	if err := p.provider.Create(ctx, flag); err != nil {
+		return err
+	}
+	p.Invalidate(ctx, flag.Name)
+	return nil
+}
+
+func (p *CachedProvider) Save(ctx context.Context, flag *model.FeatureFlag) error {
+	if err := p.provider.Save(ctx, flag); err != nil {
+		return err
+	}
+	p.Invalidate(ctx, flag.Name)
+	return nil
+}
+
+func (p *CachedProvider) Delete(ctx context.Context, name string) error {
+	if err := p.provider.Delete(ctx, name); err != nil {
+		return err
+	}
+	p.Invalidate(ctx, name)
+	return nil
+}
+
+// Invalidate drops the cached flag, so the next read gets it from the wrapped provider.
+func (p *CachedProvider) Invalidate(ctx context.Context, name string) {
+	if err := p.redis.Del(ctx, p.keyPrefix+name); err != nil {
+		p.logger.Warn("Cannot invalidate cached feature flag", zap.String("flag", name), zap.Error(err))
+	}
+}
+
+// MemoryProvider keeps the flags in memory, for tests and local development.
+type MemoryProvider struct {
+	mu    sync.RWMutex
+	flags map[string]model.FeatureFlag
+}
+
+func NewMemoryProvider(flags ...model.FeatureFlag) *MemoryProvider {
+	p := &MemoryProvider{flags: map[string]model.FeatureFlag{}}
+	for _, flag := range flags {
+		p.flags[flag.Name] = flag
+	}
+	return p
+}
+
+func (p *MemoryProvider) Get(_ context.Context, name string) (*model.FeatureFlag, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	flag, ok := p.flags[name]
+	if !ok {
+		return nil, ErrFlagNotFound
+	}
+	return &flag, nil
+}
+
+func (p *MemoryProvider) List(_ context.Context) ([]model.FeatureFlag, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	flags := make([]model.FeatureFlag, 0, len(p.flags))
+	for _, flag := range p.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags, nil
+}
+
+func (p *MemoryProvider) Create(_ context.Context, flag *model.FeatureFlag) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.flags[flag.Name]; ok {
+		return ErrFlagExists
+	}
+	p.flags[flag.Name] = *flag
+	return nil
+}
+
+func (p *MemoryProvider) Save(_ context.Context, flag *model.FeatureFlag) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.flags[flag.Name] = *flag
+	return nil
+}
+
+func (p *MemoryProvider) Delete(_ context.Context, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.flags[name]; !ok {
+		return ErrFlagNotFound
+	}
+	delete(p.flags, name)
+	return nil
+}
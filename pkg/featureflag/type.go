@@ -0,0 +1,162 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.FeatureFlag"
+
+	// AuditEntityType is the entity type of the audit logs of the flag changes.
+	AuditEntityType = "feature_flag"
+
+	defaultCacheKeyPrefix = "featureflag:"
+	defaultCacheTTL       = 30 * time.Second
+	// defaultMissTTL bounds how long an unknown flag is cached, so a flag created on another
+	// instance is picked up quickly.
+	defaultMissTTL = 5 * time.Second
+
+	// cachedMiss is the cached value of a flag that does not exist.
+	cachedMiss = "null"
+)
+
+var (
+	// ErrFlagNotFound is returned when the flag does not exist.
+	ErrFlagNotFound = errors.New("feature flag not found")
+	// ErrFlagExists is returned when a flag is created with the name of an existing flag.
+	ErrFlagExists = errors.New("feature flag already exists")
+)
+
+// Provider stores the flags.
+type Provider interface {
+	// Get returns the flag.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - name: the flag name
+	//
+	// Returns:
+	//   - *model.FeatureFlag: the flag
+	//   - error: ErrFlagNotFound when the flag does not exist
+	Get(ctx context.Context, name string) (*model.FeatureFlag, error)
+
+	// List returns every flag ordered by name.
+	List(ctx context.Context) ([]model.FeatureFlag, error)
+
+	// Create creates the flag, atomically failing when the name is taken.
+	//
+	// Returns:
+	//   - error: ErrFlagExists when a flag with the name exists
+	Create(ctx context.Context, flag *model.FeatureFlag) error
+
+	// Save creates or updates the flag.
+	Save(ctx context.Context, flag *model.FeatureFlag) error
+
+	// Delete deletes the flag.
+	//
+	// Returns:
+	//   - error: ErrFlagNotFound when the flag does not exist
+	Delete(ctx context.Context, name string) error
+}
+
+// Subject is who a flag is evaluated for.
+type Subject struct {
+	UserID   string
+	Roles    []string
+	TenantID string
+}
+
+// Opts represents the options for configuring the feature flag service.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Provider stores the flags, for example the Postgres provider behind the Redis cache.
+	Provider Provider
+}
+
+// IFeatureFlagService represents the interface for the feature flag service.
+type IFeatureFlagService interface {
+	// Enabled reports whether the flag is on for the user carried by ctx. Unknown flags and
+	// provider errors evaluate to false.
+	//
+	// Parameters:
+	//   - ctx: context, carrying the user set by the JWT middleware
+	//   - name: the flag name
+	//
+	// Returns:
+	//   - bool: true if the flag is on
+	Enabled(ctx context.Context, name string) bool
+
+	// EnabledFor reports whether the flag is on for the subject.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - name: the flag name
+	//   - subject: the subject
+	//
+	// Returns:
+	//   - bool: true if the flag is on
+	EnabledFor(ctx context.Context, name string, subject Subject) bool
+}
+
+// Service represents the feature flag service.
+type Service struct {
+	Provider Provider
+
+	logger *zap.Logger
+}
+
+// Invalidator drops cached flags.
+type Invalidator interface {
+	// Invalidate drops the cached flag.
+	Invalidate(ctx context.Context, name string)
+}
+
+// HandlerOpts represents the options for configuring the admin handler.
+type HandlerOpts struct {
+	Logger *zap.Logger
+	// Provider stores the flags, for example the Postgres provider. The changes and their audit
+	// logs are written in the same transaction, so it must not be the cached provider.
+	Provider  Provider
+	Validator validator.IValidatorService
+	// Audit records the flag changes. Optional.
+	Audit audit.IAuditService
+	// DB runs the changes and their audit logs in a transaction. Optional.
+	DB database.IDBService
+	// Cache is invalidated once a change is committed. Optional.
+	Cache Invalidator
+}
+
+// Handler serves the admin CRUD endpoints of the flags.
+type Handler struct {
+	logger    *zap.Logger
+	provider  Provider
+	validator validator.IValidatorService
+	audit     audit.IAuditService
+	db        database.IDBService
+	cache     Invalidator
+}
+
+// RequestFlagGet is the request of the endpoints addressing a single flag.
+type RequestFlagGet struct {
+	Name string `params:"name" validate:"required,max=100"`
+}
+
+// RequestFlagSave is the body of the create and update endpoints.
+type RequestFlagSave struct {
+	Name        string   `json:"name" params:"name" validate:"required,max=100"`
+	Description string   `json:"description" validate:"max=255"`
+	Enabled     bool     `json:"enabled"`
+	Percentage  *int     `json:"percentage" validate:"omitempty,min=0,max=100"`
+	UserIDs     []string `json:"userIds"`
+	Roles       []string `json:"roles"`
+	TenantIDs   []string `json:"tenantIds"`
+}
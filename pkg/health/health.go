@@ -1,64 +1,112 @@
 package health
 
 import (
+	"net/http"
 	"time"
 
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
 	"github.com/gofiber/fiber/v2"
 )
 
 // Response represents the health check response
 type Response struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Service   string    `json:"service"`
-	Version   string    `json:"version"`
+	Status       string            `json:"status"`
+	Timestamp    time.Time         `json:"timestamp"`
+	Service      string            `json:"service"`
+	Version      string            `json:"version"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// Dependency reports the circuit breaker state of a dependency, such as resilience.Guard
+type Dependency interface {
+	Name() string
+	State() circuitbreaker.State
 }
 
 // Handler handles health check requests
 type Handler struct {
 	serviceName    string
 	serviceVersion string
+	dependencies   []Dependency
 }
 
-// NewHandler creates a new health check handler
-func NewHandler(serviceName, serviceVersion string) *Handler {
+// NewHandler creates a new health check handler reporting the state of the given dependencies
+func NewHandler(serviceName, serviceVersion string, dependencies ...Dependency) *Handler {
 	return &Handler{
 		serviceName:    serviceName,
 		serviceVersion: serviceVersion,
+		dependencies:   dependencies,
 	}
 }
 
+// dependencyStates returns the breaker state of every dependency, and whether any breaker is
+// not closed or is open.
+func (h *Handler) dependencyStates() (states map[string]string, degraded bool, unavailable bool) {
+	if len(h.dependencies) == 0 {
+		return nil, false, false
+	}
+
+	states = make(map[string]string, len(h.dependencies))
+	for _, dependency := range h.dependencies {
+		state := dependency.State()
+		states[dependency.Name()] = string(state)
+		degraded = degraded || state != circuitbreaker.StateClosed
+		unavailable = unavailable || state == circuitbreaker.StateOpen
+	}
+	return states, degraded, unavailable
+}
+
 // Check godoc
 // @Summary Health check
-// @Description Check if the service is healthy
+// @Description Check if the service is healthy, the status is degraded while a dependency circuit breaker is not closed
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} Response
 // @Router /health [get]
 func (h *Handler) Check(c *fiber.Ctx) error {
+	states, degraded, _ := h.dependencyStates()
+	status := "ok"
+	if degraded {
+		status = "degraded"
+	}
+
 	return c.JSON(Response{
-		Status:    "ok",
-		Timestamp: time.Now(),
-		Service:   h.serviceName,
-		Version:   h.serviceVersion,
+		Status:       status,
+		Timestamp:    time.Now(),
+		Service:      h.serviceName,
+		Version:      h.serviceVersion,
+		Dependencies: states,
 	})
 }
 
 // Readiness godoc
 // @Summary Readiness check
-// @Description Check if the service is ready to accept requests
+// @Description Check if the service is ready to accept requests, it is not while a dependency circuit breaker is open
 // @Tags health
 // @Accept json
 // @Produce json
 // @Success 200 {object} Response
+// @Failure 503 {object} Response
 // @Router /ready [get]
 func (h *Handler) Readiness(c *fiber.Ctx) error {
+	states, _, unavailable := h.dependencyStates()
+	if unavailable {
+		return c.Status(http.StatusServiceUnavailable).JSON(Response{
+			Status:       "not_ready",
+			Timestamp:    time.Now(),
+			Service:      h.serviceName,
+			Version:      h.serviceVersion,
+			Dependencies: states,
+		})
+	}
+
 	return c.JSON(Response{
-		Status:    "ready",
-		Timestamp: time.Now(),
-		Service:   h.serviceName,
-		Version:   h.serviceVersion,
+		Status:       "ready",
+		Timestamp:    time.Now(),
+		Service:      h.serviceName,
+		Version:      h.serviceVersion,
+		Dependencies: states,
 	})
 }
 
@@ -0,0 +1,60 @@
+package health_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/health"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dependency struct {
+	name  string
+	state circuitbreaker.State
+}
+
+func (d *dependency) Name() string { return d.name }
+
+func (d *dependency) State() circuitbreaker.State { return d.state }
+
+func get(t *testing.T, app *fiber.App, path string) (int, health.Response) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	require.NoError(t, err)
+	var body health.Response
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestHandler_Dependencies(t *testing.T) {
+	postgres := &dependency{name: "postgres", state: circuitbreaker.StateClosed}
+	redis := &dependency{name: "redis", state: circuitbreaker.StateClosed}
+	h := health.NewHandler("codebase", "1.0.0", postgres, redis)
+	app := fiber.New()
+	app.Get("/health", h.Check)
+	app.Get("/ready", h.Readiness)
+
+	code, body := get(t, app, "/health")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body.Status)
+	assert.Equal(t, map[string]string{"postgres": "closed", "redis": "closed"}, body.Dependencies)
+
+	redis.state = circuitbreaker.StateHalfOpen
+	code, body = get(t, app, "/health")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "degraded", body.Status)
+	code, _ = get(t, app, "/ready")
+	assert.Equal(t, http.StatusOK, code)
+
+	redis.state = circuitbreaker.StateOpen
+	code, body = get(t, app, "/ready")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "open", body.Dependencies["redis"])
+}
@@ -0,0 +1,268 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// HeaderIdempotencyKey makes a request with an unsafe method retryable.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+func NewHTTPClient(opts *Opts) *Client {
+	c := &Client{
+		logger:       opts.Logger,
+		tracer:       otel.Tracer(InstrumentationName),
+		timeout:      opts.Timeout,
+		hostTimeouts: opts.HostTimeouts,
+		maxRetries:   opts.MaxRetries,
+		backoffBase:  opts.BackoffBase,
+		backoffMax:   opts.BackoffMax,
+		breakerOpts:  opts.Breaker,
+		breakers:     map[string]*circuitbreaker.Breaker{},
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultTimeout
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = defaultMaxRetries
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.backoffBase <= 0 {
+		c.backoffBase = defaultBackoffBase
+	}
+	if c.backoffMax <= 0 {
+		c.backoffMax = defaultBackoffMax
+	}
+
+	transport := opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c.client = &http.Client{Transport: transport}
+	return c
+}
+
+// Do sends the request. Network errors, 429, 502, 503 and 504 responses are retried with
+// jittered exponential backoff when the request is idempotent: a safe or idempotent method, or
+// an Idempotency-Key header. A request body is replayed through req.GetBody, which
+// http.NewRequest sets for in-memory bodies; a body that cannot be read again fails with
+// ErrBodyReplay. Failures of the host count toward its circuit breaker, which rejects requests
+// with circuitbreaker.ErrOpen while open.
+//
+// Parameters:
+//   - req: the request, its context carries the deadline, trace and request ID
+//
+// Returns:
+//   - *http.Response: the response of the last attempt
+//   - error: error if no response was received
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx, span := c.tracer.Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+			attribute.String("server.address", req.URL.Hostname()),
+		))
+	defer span.End()
+
+	l := logger.WithContext(ctx, logger.WithID(c.logger, ContextName, "Do")).
+		With(zap.String("http.request.method", req.Method), zap.String("url.full", req.URL.Redacted()))
+
+	retryable := isRetryableRequest(req)
+	breaker := c.breaker(req.URL.Host)
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; ; attempt++ {
+		resp, err = c.attempt(ctx, req, breaker, attempt)
+
+		if attempt >= c.maxRetries || !retryable || !shouldRetry(resp, err) {
+			break
+		}
+
+		wait := c.backoff(attempt, resp)
+		l.Warn("Retrying outbound request", zap.Int("attempt", attempt+1), zap.Duration("backoff", wait), zap.Error(err), statusField(resp))
+		if resp != nil {
+			// drain so the connection is reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+
+	if err != nil {
+		l.Error("Outbound request failed", zap.Error(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	l.Debug("Outbound request completed", statusField(resp))
+	return resp, nil
+}
+
+// Get sends a GET request to url.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// attempt sends a single attempt of the request through the host breaker.
+func (c *Client) attempt(ctx context.Context, req *http.Request, breaker *circuitbreaker.Breaker, attempt int) (*http.Response, error) {
+	// the body is read again before reserving a call, failing to read it is not a failure of the host
+	var body io.ReadCloser
+	if attempt > 0 && req.Body != nil && req.GetBody != nil {
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrBodyReplay, err)
+		}
+	}
+
+	done, err := breaker.Allow()
+	if err != nil {
+		if body != nil {
+			body.Close()
+		}
+		return nil, fmt.Errorf("%s: %w", req.URL.Host, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.hostTimeout(req.URL.Hostname()))
+	outReq := req.Clone(ctx)
+	if body != nil {
+		outReq.Body = body
+	}
+
+	// propagate the request ID and the trace
+	if id := middleware.RequestIDFromContext(ctx); id != "" && outReq.Header.Get(middleware.HeaderRequestID) == "" {
+		outReq.Header.Set(middleware.HeaderRequestID, id)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(outReq.Header))
+
+	resp, err := c.client.Do(outReq)
+	done(err == nil && resp.StatusCode < http.StatusInternalServerError)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// the attempt deadline covers reading the body
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func (c *Client) breaker(host string) *circuitbreaker.Breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.breakers[host]
+	if !ok {
+		opts := c.breakerOpts
+		opts.Name = host
+		b = circuitbreaker.NewCircuitBreaker(opts)
+		c.breakers[host] = b
+	}
+	return b
+}
+
+func (c *Client) hostTimeout(host string) time.Duration {
+	if timeout, ok := c.hostTimeouts[host]; ok && timeout > 0 {
+		return timeout
+	}
+	return c.timeout
+}
+
+// backoff returns the wait before the next attempt: full jitter over an exponential ceiling,
+// or the Retry-After of the response when it is shorter than BackoffMax.
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			if wait := time.Duration(seconds) * time.Second; wait <= c.backoffMax {
+				return wait
+			}
+		}
+	}
+
+	ceiling := c.backoffBase << attempt
+	if ceiling <= 0 || ceiling > c.backoffMax {
+		ceiling = c.backoffMax
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func isRetryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		// the body cannot be sent again
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(HeaderIdempotencyKey) != ""
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// an open breaker, a cancelled caller or a body that cannot be read will not recover by retrying
+		return !errors.Is(err, circuitbreaker.ErrOpen) && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrBodyReplay)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func statusField(resp *http.Response) zap.Field {
+	if resp == nil {
+		return zap.Skip()
+	}
+	return zap.Int("http.response.status_code", resp.StatusCode)
+}
+
+// cancelBody releases the attempt context once the body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
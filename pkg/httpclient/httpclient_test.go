@@ -0,0 +1,177 @@
+package httpclient_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/httpclient"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func newClient(opts httpclient.Opts) *httpclient.Client {
+	opts.Logger = zap.NewNop()
+	if opts.BackoffBase == 0 {
+		opts.BackoffBase = time.Millisecond
+		opts.BackoffMax = 5 * time.Millisecond
+	}
+	return httpclient.NewHTTPClient(&opts)
+}
+
+func TestClient_RetriesAndPropagates(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var calls int32
+	var requestID, traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID, traceparent = r.Header.Get(middleware.HeaderRequestID), r.Header.Get("traceparent")
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = middleware.WithRequestID(ctx, "req-1")
+
+	resp, err := newClient(httpclient.Opts{}).Get(ctx, server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.EqualValues(t, 3, calls)
+	assert.Equal(t, "req-1", requestID)
+	assert.Contains(t, traceparent, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestClient_IdempotencyAwareRetries(t *testing.T) {
+	var calls int32
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	client := newClient(httpclient.Opts{})
+
+	// unsafe method without key: not retried
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"title":"Dune"}`))
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.EqualValues(t, 1, calls)
+
+	// with an idempotency key: retried with the same body
+	req, _ = http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"title":"Dune"}`))
+	req.Header.Set(httpclient.HeaderIdempotencyKey, "key-1")
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.EqualValues(t, 3, calls)
+	assert.Equal(t, []string{`{"title":"Dune"}`, `{"title":"Dune"}`, `{"title":"Dune"}`}, bodies)
+}
+
+func TestClient_HostTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	host, _ := url.Parse(server.URL)
+	client := newClient(httpclient.Opts{
+		MaxRetries:   -1,
+		HostTimeouts: map[string]time.Duration{host.Hostname(): 20 * time.Millisecond},
+	})
+
+	start := time.Now()
+	_, err := client.Get(context.Background(), server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newClient(httpclient.Opts{
+		MaxRetries: -1,
+		Breaker:    circuitbreaker.Opts{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(context.Background(), server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	_, err := client.Get(context.Background(), server.URL)
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+	assert.EqualValues(t, 2, calls)
+}
+
+func TestClient_BodyReplayFailureIsNotHostFailure(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := newClient(httpclient.Opts{
+		MaxRetries: 2,
+		Breaker:    circuitbreaker.Opts{FailureThreshold: 2, OpenTimeout: time.Minute},
+	})
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, server.URL, strings.NewReader("{}"))
+	require.NoError(t, err)
+	req.GetBody = func() (io.ReadCloser, error) {
+		return nil, errors.New("body already consumed")
+	}
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, httpclient.ErrBodyReplay)
+	// the failed replay is not retried
+	assert.EqualValues(t, 1, calls)
+
+	// only the 503 counted toward the breaker, which is still closed
+	resp, err := client.Get(context.Background(), server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
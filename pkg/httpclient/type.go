@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.HTTPClient"
+
+	// InstrumentationName identifies the spans created by this package.
+	InstrumentationName = "github.com/Alwanly/go-codebase/pkg/httpclient"
+
+	defaultTimeout     = 10 * time.Second
+	defaultMaxRetries  = 2
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 2 * time.Second
+)
+
+// ErrBodyReplay is returned when the request body cannot be read again for a retry. It is not
+// retried and does not count toward the circuit breaker of the host.
+var ErrBodyReplay = errors.New("cannot read the request body again")
+
+// Opts represents the options for configuring the HTTP client.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+
+	// Timeout bounds a single attempt. Default is 10s.
+	Timeout time.Duration
+	// HostTimeouts overrides Timeout per host, for example {"api.example.com": 30 * time.Second}.
+	HostTimeouts map[string]time.Duration
+
+	// MaxRetries is the number of retries after the first attempt. Default is 2, negative disables retries.
+	MaxRetries int
+	// BackoffBase and BackoffMax bound the jittered exponential backoff between attempts.
+	// Defaults are 100ms and 2s.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+
+	// Breaker configures the circuit breaker of every host. Name is set to the host.
+	Breaker circuitbreaker.Opts
+
+	// Transport sends the requests. Default is http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// Client is an HTTP client with per host timeouts, retries and circuit breakers.
+type Client struct {
+	logger       *zap.Logger
+	client       *http.Client
+	tracer       trace.Tracer
+	timeout      time.Duration
+	hostTimeouts map[string]time.Duration
+	maxRetries   int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	breakerOpts  circuitbreaker.Opts
+
+	mu       sync.Mutex
+	breakers map[string]*circuitbreaker.Breaker
+}
@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var defaultCatalog = NewCatalog()
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{translations: map[string]map[string]string{}}
+}
+
+// Register adds the translations of messages to a locale, replacing existing ones.
+func (c *Catalog) Register(locale string, messages map[string]string) {
+	locale = normalizeTag(locale)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.translations[locale] == nil {
+		c.translations[locale] = map[string]string{}
+	}
+	for message, translation := range messages {
+		c.translations[locale][message] = translation
+	}
+}
+
+// Translate returns the message translated to the locale with its placeholders replaced by the
+// params. Messages without a translation are returned in English.
+func (c *Catalog) Translate(locale, message string, params ...string) string {
+	c.mu.RLock()
+	translation, ok := c.translations[normalizeTag(locale)][message]
+	c.mu.RUnlock()
+	if !ok {
+		translation = message
+	}
+
+	for i, param := range params {
+		translation = strings.ReplaceAll(translation, "{"+strconv.Itoa(i)+"}", param)
+	}
+	return translation
+}
+
+// Locales returns the default locale and the locales with translations, sorted.
+func (c *Catalog) Locales() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := []string{DefaultLocale}
+	for locale := range c.translations {
+		if locale != DefaultLocale {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// Register adds translations to the default catalog, usually from the init function of the
+// package owning the messages.
+func Register(locale string, messages map[string]string) {
+	defaultCatalog.Register(locale, messages)
+}
+
+// Translate translates a message with the default catalog.
+func Translate(locale, message string, params ...string) string {
+	return defaultCatalog.Translate(locale, message, params...)
+}
+
+// Locales returns the locales of the default catalog.
+func Locales() []string {
+	return defaultCatalog.Locales()
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header, lowercased and
+// ordered by decreasing quality. Tags with a zero or invalid quality are dropped.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag     string
+		quality float64
+	}
+
+	tags := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := normalizeTag(fields[0])
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(name, "q") {
+				q, err := strconv.ParseFloat(value, 64)
+				if err != nil || q < 0 || q > 1 {
+					q = 0
+				}
+				quality = q
+			}
+		}
+		if quality > 0 {
+			tags = append(tags, weighted{tag: tag, quality: quality})
+		}
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].quality > tags[j].quality
+	})
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// Negotiate returns the supported locale best matching an Accept-Language header, or an empty
+// string. A tag matches a supported locale exactly or by its primary language, so id-ID matches
+// id; * matches the first supported locale.
+func Negotiate(header string, supported []string) string {
+	for _, tag := range ParseAcceptLanguage(header) {
+		if tag == "*" && len(supported) > 0 {
+			return supported[0]
+		}
+
+		primary, _, _ := strings.Cut(tag, "-")
+		for _, candidate := range []string{tag, primary} {
+			for _, locale := range supported {
+				if normalizeTag(locale) == candidate {
+					return locale
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Middleware negotiates the locale of the request from its Accept-Language header and stores it
+// in the request, see Locale and FromContext.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		locale := negotiate(c)
+		c.Locals(localLocaleKey, locale)
+		c.SetUserContext(WithLocale(c.UserContext(), locale))
+		return c.Next()
+	}
+}
+
+// Locale returns the locale of the request, negotiated by Middleware or from the Accept-Language
+// header when the middleware is not used.
+func Locale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals(localLocaleKey).(string); ok {
+		return locale
+	}
+	return negotiate(c)
+}
+
+// WithLocale returns a copy of ctx carrying the locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext returns the locale carried by ctx, or the default locale.
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// negotiate returns the locale of the request accepted by the default catalog.
+func negotiate(c *fiber.Ctx) string {
+	if locale := Negotiate(c.Get(fiber.HeaderAcceptLanguage), Locales()); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// normalizeTag lowercases a language tag and uses hyphens as separators.
+func normalizeTag(tag string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(tag)), "_", "-")
+}
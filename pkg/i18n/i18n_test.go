@@ -0,0 +1,76 @@
+package i18n_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string][]string{
+		"":                                   {},
+		"id":                                 {"id"},
+		"fr-CH, fr;q=0.9, en;q=0.8, *;q=0.5": {"fr-ch", "fr", "en", "*"},
+		"en;q=0.5, id_ID":                    {"id-id", "en"},
+		"en;q=0, id;q=abc, fr":               {"fr"},
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, i18n.ParseAcceptLanguage(header), header)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	supported := []string{"en", "id"}
+	tests := map[string]string{
+		"":               "",
+		"id-ID,en;q=0.8": "id",
+		"fr, id;q=0.5":   "id",
+		"en;q=0.5, id":   "id",
+		"fr":             "",
+		"fr, *;q=0.1":    "en",
+		"ID":             "id",
+	}
+	for header, expected := range tests {
+		assert.Equal(t, expected, i18n.Negotiate(header, supported), header)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := i18n.NewCatalog()
+	catalog.Register("id", map[string]string{"{0} is invalid": "{0} tidak valid"})
+
+	assert.Equal(t, "page tidak valid", catalog.Translate("id", "{0} is invalid", "page"))
+	assert.Equal(t, "page tidak valid", catalog.Translate("ID", "{0} is invalid", "page"))
+	assert.Equal(t, "page is invalid", catalog.Translate("en", "{0} is invalid", "page"))
+	assert.Equal(t, "page is invalid", catalog.Translate("fr", "{0} is invalid", "page"))
+	assert.Equal(t, []string{"en", "id"}, catalog.Locales())
+
+	// contract messages are translated by the default catalog
+	assert.Equal(t, "Gagal memvalidasi data", i18n.Translate(i18n.LocaleIndonesian, contract.ErrorValidatePayload))
+}
+
+func TestMiddleware(t *testing.T) {
+	app := fiber.New()
+	app.Use(i18n.Middleware())
+	app.Get("/", func(c *fiber.Ctx) error {
+		assert.Equal(t, i18n.Locale(c), i18n.FromContext(c.UserContext()))
+		return c.SendString(i18n.Locale(c))
+	})
+
+	for header, expected := range map[string]string{"": "en", "id-ID": "id", "fr": "en"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(fiber.HeaderAcceptLanguage, header)
+		resp, _ := app.Test(req)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, expected, string(body), header)
+	}
+
+	assert.Equal(t, i18n.DefaultLocale, i18n.FromContext(context.Background()))
+}
@@ -0,0 +1,30 @@
+package i18n
+
+import "github.com/Alwanly/go-codebase/pkg/contract"
+
+// contractMessages are the Indonesian translations of the common contract messages.
+var contractMessages = map[string]string{
+	contract.ErrorValidatePayload:       "Gagal memvalidasi data",
+	contract.ErrorMutatePayload:         "Gagal mengubah data",
+	contract.ErrorInsufficientPrivilege: "Pengguna tidak memiliki hak untuk melakukan tindakan ini",
+	contract.ErrorIdempotencyInFlight:   "Permintaan dengan idempotency key yang sama masih diproses",
+	contract.ErrorIdempotencyMismatch:   "Idempotency key sudah digunakan dengan data yang berbeda",
+	contract.ErrorRequestTimeout:        "Permintaan tidak selesai dalam batas waktu yang diizinkan",
+	contract.ErrorNotFound:              "Data tidak ditemukan",
+	contract.ErrorConflict:              "Data bertentangan dengan keadaan saat ini",
+	contract.ErrorServiceUnavailable:    "Layanan sedang tidak tersedia",
+	contract.ErrorInternalServer:        "Terjadi kesalahan pada server",
+	contract.ErrorMaintenanceMode:       "Layanan sedang dalam pemeliharaan",
+	contract.ErrorReadOnlyMode:          "Layanan sedang dalam mode baca saja",
+	contract.ErrorUnsupportedVersion:    "Versi API yang diminta tidak didukung",
+	contract.ErrorFailedToFindRecord:    "Gagal mencari data",
+	contract.ErrorFailedToReadCursor:    "Gagal membaca kursor",
+	contract.ErrorFailedToCountRecord:   "Gagal menghitung data",
+	contract.ErrorFailedToDeleteRecord:  "Gagal menghapus data",
+	contract.ErrorFailedToInsertRecord:  "Gagal menyimpan data",
+	contract.ErrorFailedToUpdateRecord:  "Gagal memperbarui data",
+}
+
+func init() {
+	Register(LocaleIndonesian, contractMessages)
+}
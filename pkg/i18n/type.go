@@ -0,0 +1,26 @@
+package i18n
+
+import "sync"
+
+const (
+	ContextName = "Components.I18n"
+
+	// LocaleEnglish is the default locale, the messages are written in English.
+	LocaleEnglish = "en"
+	// LocaleIndonesian is the Indonesian locale.
+	LocaleIndonesian = "id"
+
+	// DefaultLocale is used when the request accepts no supported locale.
+	DefaultLocale = LocaleEnglish
+
+	localLocaleKey = "i18n:locale"
+)
+
+type contextKey struct{}
+
+// Catalog holds the translations of the messages per locale. Messages are keyed by their English
+// text and may have {0}, {1}... placeholders.
+type Catalog struct {
+	mu           sync.RWMutex
+	translations map[string]map[string]string
+}
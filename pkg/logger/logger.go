@@ -1,9 +1,11 @@
 package logger
 
 import (
+	"context"
 	"os"
 
 	"go.elastic.co/ecszap"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -57,6 +59,53 @@ func WithID(log *zap.Logger, contextName string, scopeName string) *zap.Logger {
 	return log.With(zap.String("context", contextName), zap.String("scope", scopeName))
 }
 
+type requestIDContextKey struct{}
+
+// WithContext returns a logger carrying the request ID and the trace and span IDs of the span in
+// ctx, if any.
+func WithContext(ctx context.Context, log *zap.Logger) *zap.Logger {
+	fields := TraceFields(ctx)
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields = append(fields, zap.String("http.request.id", id))
+	}
+	if len(fields) == 0 {
+		return log
+	}
+	return log.With(fields...)
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID, written to the logs by
+// WithContext.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// TraceFields returns the ECS trace.id and span.id fields of the span in ctx.
+func TraceFields(ctx context.Context) []zapcore.Field {
+	if ctx == nil {
+		return nil
+	}
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	return []zapcore.Field{
+		zap.String("trace.id", spanCtx.TraceID().String()),
+		zap.String("span.id", spanCtx.SpanID().String()),
+	}
+}
+
 func WithPrettyPrint() func(*BuilderOption) {
 	return func(o *BuilderOption) {
 		o.PrettyPrint = true
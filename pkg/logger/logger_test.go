@@ -1,10 +1,13 @@
 package logger_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Alwanly/go-codebase/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -22,3 +25,15 @@ func TestWithPrettyPrint(t *testing.T) {
 	log := logger.NewLogger("test_service", "debug", logger.WithPrettyPrint())
 	assert.NotNil(t, log)
 }
+
+func TestWithContext_RequestID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logger.ContextWithRequestID(context.Background(), "req-1")
+
+	logger.WithContext(ctx, zap.New(core)).Info("get book")
+	logger.WithContext(context.Background(), zap.New(core)).Info("get book")
+
+	entries := logs.All()
+	assert.Equal(t, "req-1", entries[0].ContextMap()["http.request.id"])
+	assert.NotContains(t, entries[1].ContextMap(), "http.request.id")
+}
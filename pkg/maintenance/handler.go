@@ -0,0 +1,79 @@
+package maintenance
+
+import (
+	"net/http"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/binding"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:    opts.Logger,
+		service:   opts.Service,
+		validator: opts.Validator,
+		audit:     opts.Audit,
+	}
+}
+
+// Get returns the switch state.
+func (h *Handler) Get(c *fiber.Ctx) error {
+	state, err := h.service.State(c.UserContext())
+	if err != nil {
+		return apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+	return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, state))
+}
+
+// Set changes the switch state of every instance.
+func (h *Handler) Set(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Set")
+
+	// bind model
+	model := &RequestSetState{}
+	if err := binding.BindModel(l, c, model, binding.BindFromBody()); err != nil {
+		return err
+	}
+
+	// validate model
+	if err := validator.ValidateModelContext(c.UserContext(), l, h.validator, model, i18n.Locale(c)); err != nil {
+		return err
+	}
+
+	before, err := h.service.State(c.UserContext())
+	if err != nil {
+		return apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+
+	state := State{
+		Mode:              model.Mode,
+		Message:           model.Message,
+		RetryAfterSeconds: model.RetryAfterSeconds,
+	}
+	if err := h.service.SetState(c.UserContext(), state); err != nil {
+		return apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorFailedToUpdateRecord).Wrap(err)
+	}
+
+	after, err := h.service.State(c.UserContext())
+	if err != nil {
+		return apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorFailedToFindRecord).Wrap(err)
+	}
+
+	// the state lives in Redis, the change is applied already when the log is stored
+	if h.audit != nil {
+		entry := audit.Entry{Action: audit.ActionUpdate, EntityType: AuditEntityType, EntityID: AuditEntityType, Before: before, After: after}
+		if err := h.audit.Record(c.UserContext(), entry); err != nil {
+			l.Error("Cannot record the maintenance state change", zap.Error(err))
+		}
+	}
+
+	return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, after))
+}
@@ -0,0 +1,169 @@
+package maintenance
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidMode = errors.New("invalid maintenance mode")
+
+func NewMaintenance(opts *Opts) *Service {
+	key := opts.Key
+	if key == "" {
+		key = defaultKey
+	}
+	refreshInterval := opts.RefreshInterval
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRefreshInterval
+	}
+
+	return &Service{
+		logger:          logger.WithID(opts.Logger, ContextName, "Maintenance"),
+		redis:           opts.Redis,
+		key:             key,
+		refreshInterval: refreshInterval,
+		cached:          State{Mode: ModeOff},
+	}
+}
+
+func (s *Service) State(ctx context.Context) (State, error) {
+	raw, err := s.redis.Get(ctx, s.key)
+	if errors.Is(err, redis.ErrNil) {
+		return State{Mode: ModeOff}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+
+	state := State{}
+	if err := utils.JSONUnMarshal([]byte(raw), &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+func (s *Service) SetState(ctx context.Context, state State) error {
+	switch state.Mode {
+	case ModeOff, ModeReadOnly, ModeMaintenance:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidMode, state.Mode)
+	}
+	state.UpdatedAt = time.Now().UTC()
+
+	raw, err := utils.JSONMarshal(state)
+	if err != nil {
+		return err
+	}
+	if err := s.redis.Set(ctx, s.key, raw, 0); err != nil {
+		s.logger.Error("Cannot store maintenance state", zap.Error(err))
+		return err
+	}
+
+	// apply on this instance right away
+	s.mu.Lock()
+	s.cached = state
+	s.refreshAt = time.Now().Add(s.refreshInterval)
+	s.mu.Unlock()
+
+	s.logger.Warn("Maintenance mode changed", zap.String("mode", string(state.Mode)))
+	return nil
+}
+
+// current returns the state, reading Redis at most once per refresh interval. When Redis
+// cannot be read the last known state is kept.
+func (s *Service) current(ctx context.Context) State {
+	s.mu.RLock()
+	state, refreshAt := s.cached, s.refreshAt
+	s.mu.RUnlock()
+	if time.Now().Before(refreshAt) {
+		return state
+	}
+
+	fresh, err := s.State(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshAt = time.Now().Add(s.refreshInterval)
+	if err != nil {
+		s.logger.Warn("Cannot read maintenance state, keep the last known one", zap.Error(err))
+		return s.cached
+	}
+	s.cached = fresh
+	return fresh
+}
+
+// Middleware rejects unsafe methods in read-only mode and every request in maintenance mode with
+// 503 and Retry-After, except for skipped paths and bypassing clients.
+func (s *Service) Middleware(opts MiddlewareOpts) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		state := s.current(c.UserContext())
+		if state.Mode == ModeOff || state.Mode == "" {
+			return c.Next()
+		}
+		if state.Mode == ModeReadOnly && isSafeMethod(c.Method()) {
+			return c.Next()
+		}
+		if skipPath(c.Path(), opts.SkipPaths) || bypass(c, opts) {
+			return c.Next()
+		}
+
+		retryAfter := state.RetryAfterSeconds
+		if retryAfter <= 0 {
+			retryAfter = defaultRetryAfter
+		}
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		statusCode, message := contract.StatusCodeMaintenance, contract.ErrorMaintenanceMode
+		if state.Mode == ModeReadOnly {
+			statusCode, message = contract.StatusCodeReadOnly, contract.ErrorReadOnlyMode
+		}
+		if state.Message != "" {
+			message = state.Message
+		}
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusServiceUnavailable, statusCode, message, nil))
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func skipPath(path string, skipPaths []string) bool {
+	for _, skip := range skipPaths {
+		skip = strings.TrimSuffix(skip, "/")
+		if path == skip || strings.HasPrefix(path, skip+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func bypass(c *fiber.Ctx, opts MiddlewareOpts) bool {
+	if key := c.Get(HeaderAPIKey); key != "" {
+		for _, allowed := range opts.BypassAPIKeys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(allowed)) == 1 {
+				return true
+			}
+		}
+	}
+
+	if len(opts.BypassRoles) > 0 && opts.Identify != nil {
+		if user, ok := opts.Identify(c); ok && user.HasRole(opts.BypassRoles...) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package maintenance_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/model"
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/authentication"
+	"github.com/Alwanly/go-codebase/pkg/maintenance"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newMaintenanceApp(t *testing.T) (*fiber.App, *maintenance.Service) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	s := maintenance.NewMaintenance(&maintenance.Opts{
+		Logger:          zap.NewNop(),
+		Redis:           &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+		RefreshInterval: time.Millisecond,
+	})
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	h := maintenance.NewHandler(&maintenance.HandlerOpts{Logger: zap.NewNop(), Service: s, Validator: v})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(s.Middleware(maintenance.MiddlewareOpts{
+		SkipPaths:     []string{"/health", "/admin/maintenance"},
+		BypassAPIKeys: []string{"ops-key"},
+		BypassRoles:   []string{"admin"},
+		Identify: func(c *fiber.Ctx) (*middleware.AuthUserData, bool) {
+			if c.Get("X-Role") == "" {
+				return nil, false
+			}
+			return &middleware.AuthUserData{UserID: "user-1", Roles: []string{c.Get("X-Role")}}, true
+		},
+	}))
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Get("/health", ok)
+	app.Get("/books", ok)
+	app.Post("/books", ok)
+	app.Get("/admin/maintenance", h.Get)
+	app.Put("/admin/maintenance", h.Set)
+	return app, s
+}
+
+func do(t *testing.T, app *fiber.App, method string, path string, headers map[string]string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestMiddleware_ReadOnly(t *testing.T) {
+	app, s := newMaintenanceApp(t)
+	require.NoError(t, s.SetState(context.Background(), maintenance.State{Mode: maintenance.ModeReadOnly, RetryAfterSeconds: 120}))
+
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodGet, "/books", nil).StatusCode)
+
+	resp := do(t, app, http.MethodPost, "/books", nil)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "120", resp.Header.Get(fiber.HeaderRetryAfter))
+	assert.Contains(t, string(body), `"statusCode":"000026"`)
+
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodPost, "/books", map[string]string{maintenance.HeaderAPIKey: "ops-key"}).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, app, http.MethodPost, "/books", map[string]string{maintenance.HeaderAPIKey: "wrong"}).StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodPost, "/books", map[string]string{"X-Role": "admin"}).StatusCode)
+	assert.Equal(t, http.StatusServiceUnavailable, do(t, app, http.MethodPost, "/books", map[string]string{"X-Role": "viewer"}).StatusCode)
+}
+
+func TestMiddleware_Maintenance(t *testing.T) {
+	app, s := newMaintenanceApp(t)
+	require.NoError(t, s.SetState(context.Background(), maintenance.State{Mode: maintenance.ModeMaintenance, Message: "Database migration"}))
+
+	resp := do(t, app, http.MethodGet, "/books", nil)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "60", resp.Header.Get(fiber.HeaderRetryAfter))
+	assert.Contains(t, string(body), "Database migration")
+
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodGet, "/health", nil).StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodGet, "/admin/maintenance", nil).StatusCode)
+}
+
+func TestHandler_Set(t *testing.T) {
+	app, s := newMaintenanceApp(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(`{"mode":"read_only","retryAfterSeconds":30}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	state, err := s.State(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, maintenance.ModeReadOnly, state.Mode)
+	assert.Equal(t, 30, state.RetryAfterSeconds)
+
+	req = httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(`{"mode":"sleeping"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// switching back off is allowed while the switch itself is on
+	req = httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(`{"mode":"off"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	resp, err = app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, do(t, app, http.MethodPost, "/books", nil).StatusCode)
+}
+
+// recordingAudit keeps the audit logs instead of storing them.
+type recordingAudit struct {
+	logs []*model.AuditLog
+}
+
+func (a *recordingAudit) Record(ctx context.Context, entry audit.Entry) error {
+	log, err := audit.NewLog(ctx, entry)
+	if err != nil {
+		return err
+	}
+	a.logs = append(a.logs, log)
+	return nil
+}
+
+func (a *recordingAudit) List(context.Context, audit.Filter) ([]model.AuditLog, int64, error) {
+	return nil, 0, nil
+}
+
+func TestHandler_SetAudited(t *testing.T) {
+	mr := miniredis.RunT(t)
+	s := maintenance.NewMaintenance(&maintenance.Opts{
+		Logger: zap.NewNop(),
+		Redis:  &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+	})
+	v, err := validator.NewValidator()
+	require.NoError(t, err)
+	recorder := &recordingAudit{}
+	h := maintenance.NewHandler(&maintenance.HandlerOpts{Logger: zap.NewNop(), Service: s, Validator: v, Audit: recorder})
+	auth := middleware.NewAuthMiddleware(
+		middleware.SetJwtAuth(&authentication.JWTConfig{}),
+		middleware.SetBasicAuth(&authentication.BasicAuthTConfig{Username: "ops", Password: "secret"}),
+	)
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Put("/admin/maintenance", auth.BasicAuth(), h.Set)
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(`{"mode":"read_only"}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	req.SetBasicAuth("ops", "secret")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, recorder.logs, 1)
+	log := recorder.logs[0]
+	assert.Equal(t, "ops", log.ActorID)
+	assert.Equal(t, string(audit.ActionUpdate), log.Action)
+	assert.Equal(t, maintenance.AuditEntityType, log.EntityType)
+	assert.Equal(t, model.AuditChange{Before: "off", After: "read_only"}, log.Changes["mode"])
+}
@@ -0,0 +1,133 @@
+package maintenance
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/audit"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Maintenance"
+
+	// AuditEntityType is the entity type of the audit logs of the state changes.
+	AuditEntityType = "maintenance"
+
+	// HeaderAPIKey carries the API key checked against the bypass keys.
+	HeaderAPIKey = "X-API-Key"
+
+	defaultKey             = "maintenance:state"
+	defaultRefreshInterval = time.Second
+	defaultRetryAfter      = 60
+)
+
+// Mode is the operating mode of the service.
+type Mode string
+
+const (
+	// ModeOff serves all traffic.
+	ModeOff Mode = "off"
+	// ModeReadOnly rejects unsafe methods.
+	ModeReadOnly Mode = "read_only"
+	// ModeMaintenance rejects all traffic but the skipped paths.
+	ModeMaintenance Mode = "maintenance"
+)
+
+// State is the switch state stored in Redis.
+type State struct {
+	Mode Mode `json:"mode"`
+	// Message replaces the default message of the rejected requests.
+	Message string `json:"message,omitempty"`
+	// RetryAfterSeconds is sent in the Retry-After header of the rejected requests. Default is 60.
+	RetryAfterSeconds int       `json:"retryAfterSeconds"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+// Opts represents the options for configuring the maintenance switch.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Redis stores the switch state shared by every instance.
+	Redis redis.IRedisService
+	// Key is the Redis key of the state. Default is maintenance:state.
+	Key string
+	// RefreshInterval is how long an instance reuses the state before reading Redis again. Default is 1s.
+	RefreshInterval time.Duration
+}
+
+// MiddlewareOpts represents the options for configuring the maintenance middleware.
+type MiddlewareOpts struct {
+	// SkipPaths are always served, for example health checks and the admin endpoint.
+	// A path also skips the paths below it.
+	SkipPaths []string
+	// BypassAPIKeys are the X-API-Key values allowed through.
+	BypassAPIKeys []string
+	// BypassRoles are the roles allowed through. Identify resolves the user of the request.
+	BypassRoles []string
+	Identify    func(c *fiber.Ctx) (*middleware.AuthUserData, bool)
+}
+
+// IMaintenanceService represents the interface for the maintenance switch.
+type IMaintenanceService interface {
+	// State returns the current switch state.
+	//
+	// Parameters:
+	//   - ctx: context
+	//
+	// Returns:
+	//   - State: the state, ModeOff when never set
+	//   - error: error
+	State(ctx context.Context) (State, error)
+
+	// SetState stores the switch state for every instance.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - state: the state
+	//
+	// Returns:
+	//   - error: error
+	SetState(ctx context.Context, state State) error
+}
+
+// HandlerOpts represents the options for configuring the admin handler.
+type HandlerOpts struct {
+	Logger    *zap.Logger
+	Service   IMaintenanceService
+	Validator validator.IValidatorService
+	// Audit records the state changes. Optional.
+	Audit audit.IAuditService
+}
+
+// Handler serves the admin endpoint of the switch.
+type Handler struct {
+	logger    *zap.Logger
+	service   IMaintenanceService
+	validator validator.IValidatorService
+	audit     audit.IAuditService
+}
+
+// RequestSetState is the body of the admin endpoint.
+type RequestSetState struct {
+	Mode              Mode   `json:"mode" validate:"required,oneof=off read_only maintenance"`
+	Message           string `json:"message" validate:"max=255"`
+	RetryAfterSeconds int    `json:"retryAfterSeconds" validate:"min=0"`
+}
+
+// Service represents the maintenance switch.
+type Service struct {
+	logger          *zap.Logger
+	redis           redis.IRedisService
+	key             string
+	refreshInterval time.Duration
+
+	mu        sync.RWMutex
+	cached    State
+	refreshAt time.Time
+}
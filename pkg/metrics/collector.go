@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type dbStatsCollector struct {
+	provider DBStatsProvider
+
+	openConnections  *prometheus.Desc
+	idleConnections  *prometheus.Desc
+	inUseConnections *prometheus.Desc
+	waitCount        *prometheus.Desc
+	waitDuration     *prometheus.Desc
+}
+
+// NewDBStatsCollector returns a collector exporting the sql.DBStats of the provider.
+func NewDBStatsCollector(namespace string, provider DBStatsProvider) prometheus.Collector {
+	fqName := func(name string) string {
+		return prometheus.BuildFQName(sanitizeName(namespace), "db", name)
+	}
+
+	return &dbStatsCollector{
+		provider:         provider,
+		openConnections:  prometheus.NewDesc(fqName("open_connections"), "Number of established connections, both in use and idle.", nil, nil),
+		idleConnections:  prometheus.NewDesc(fqName("idle_connections"), "Number of idle connections.", nil, nil),
+		inUseConnections: prometheus.NewDesc(fqName("in_use_connections"), "Number of connections currently in use.", nil, nil),
+		waitCount:        prometheus.NewDesc(fqName("wait_count_total"), "Total number of connections waited for.", nil, nil),
+		waitDuration:     prometheus.NewDesc(fqName("wait_duration_seconds_total"), "Total time blocked waiting for a new connection.", nil, nil),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openConnections
+	ch <- c.idleConnections
+	ch <- c.inUseConnections
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.Stats()
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.idleConnections, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.inUseConnections, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
+
+type redisPoolStatsCollector struct {
+	provider RedisPoolStatsProvider
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	totalConns *prometheus.Desc
+	idleConns  *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+// NewRedisPoolStatsCollector returns a collector exporting the Redis pool stats of the provider.
+func NewRedisPoolStatsCollector(namespace string, provider RedisPoolStatsProvider) prometheus.Collector {
+	fqName := func(name string) string {
+		return prometheus.BuildFQName(sanitizeName(namespace), "redis_pool", name)
+	}
+
+	return &redisPoolStatsCollector{
+		provider:   provider,
+		hits:       prometheus.NewDesc(fqName("hits_total"), "Number of times a free connection was found in the pool.", nil, nil),
+		misses:     prometheus.NewDesc(fqName("misses_total"), "Number of times a free connection was not found in the pool.", nil, nil),
+		timeouts:   prometheus.NewDesc(fqName("timeouts_total"), "Number of times a wait for a connection timed out.", nil, nil),
+		totalConns: prometheus.NewDesc(fqName("total_connections"), "Number of connections in the pool.", nil, nil),
+		idleConns:  prometheus.NewDesc(fqName("idle_connections"), "Number of idle connections in the pool.", nil, nil),
+		staleConns: prometheus.NewDesc(fqName("stale_connections_total"), "Number of stale connections removed from the pool.", nil, nil),
+	}
+}
+
+func (c *redisPoolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.staleConns
+}
+
+func (c *redisPoolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.PoolStats()
+	if stats == nil {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+func NewMetrics(opts *Opts) *Service {
+	l := logger.WithID(opts.Logger, ContextName, "NewMetrics")
+
+	s := &Service{
+		Registry:  prometheus.NewRegistry(),
+		logger:    opts.Logger,
+		namespace: sanitizeName(opts.Namespace),
+	}
+
+	// runtime metrics
+	s.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	// http metrics
+	s.httpRequests = s.Counter("http_requests_total", "Total number of HTTP requests.", "method", "route", "status")
+	s.httpDuration = s.Histogram("http_request_duration_seconds", "HTTP request latency in seconds.", nil, "method", "route", "status")
+	s.httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: s.namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Number of HTTP requests currently being served.",
+	})
+	s.Registry.MustRegister(s.httpInFlight)
+
+	// database metrics
+	s.slowQueries = s.Counter("db_slow_queries_total", "Total number of queries slower than the slow threshold.", "operation")
+
+	l.Info("Metrics registry created")
+	return s
+}
+
+func (s *Service) Register(collectors ...prometheus.Collector) error {
+	for _, c := range collectors {
+		if err := s.Registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) RegisterDBStats(provider DBStatsProvider) error {
+	return s.Registry.Register(NewDBStatsCollector(s.namespace, provider))
+}
+
+func (s *Service) RegisterRedisPoolStats(provider RedisPoolStatsProvider) error {
+	return s.Registry.Register(NewRedisPoolStatsCollector(s.namespace, provider))
+}
+
+func (s *Service) Counter(name string, help string, labels ...string) *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: s.namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+
+	return registerOrExisting(s, c)
+}
+
+func (s *Service) Histogram(name string, help string, buckets []float64, labels ...string) *prometheus.HistogramVec {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+
+	h := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: s.namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labels)
+
+	return registerOrExisting(s, h)
+}
+
+func (s *Service) Gauge(name string, help string, labels ...string) *prometheus.GaugeVec {
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: s.namespace,
+		Name:      name,
+		Help:      help,
+	}, labels)
+
+	return registerOrExisting(s, g)
+}
+
+func (s *Service) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		s.httpInFlight.Inc()
+		defer s.httpInFlight.Dec()
+
+		err := c.Next()
+
+		// the error handler runs after this middleware, so resolve the final status here
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		if err != nil {
+			status = wrapper.ResponseFromError(err).Code
+		}
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) && fiberErr.Code == fiber.StatusNotFound {
+			route = RouteUnmatched
+		}
+
+		labels := prometheus.Labels{
+			// the method is reused by Fiber after the request, the labels outlive it
+			"method": utils.CopyString(c.Method()),
+			"route":  route,
+			"status": strconv.Itoa(status),
+		}
+		s.httpRequests.With(labels).Inc()
+		s.httpDuration.With(labels).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+func (s *Service) Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(s.Registry, promhttp.HandlerOpts{
+		Registry: s.Registry,
+	}))
+}
+
+func (s *Service) ObserveSlowQuery(_ context.Context, sql string, _ time.Duration) {
+	s.slowQueries.WithLabelValues(queryOperation(sql)).Inc()
+}
+
+// registerOrExisting registers the collector, or returns the one registered earlier under the same name.
+func registerOrExisting[T prometheus.Collector](s *Service, c T) T {
+	if err := s.Registry.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		s.logger.Error("Cannot register metric", zap.Error(err))
+	}
+
+	return c
+}
+
+// queryOperation returns the SQL verb of a query, keeping the label cardinality bounded.
+func queryOperation(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return "other"
+	}
+
+	switch verb := strings.ToLower(fields[0]); verb {
+	case "select", "insert", "update", "delete", "with":
+		return verb
+	default:
+		return "other"
+	}
+}
+
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
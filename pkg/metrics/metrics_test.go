@@ -0,0 +1,122 @@
+package metrics_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeDB struct{}
+
+func (fakeDB) Stats() sql.DBStats {
+	return sql.DBStats{OpenConnections: 3, Idle: 2, InUse: 1, WaitCount: 7}
+}
+
+func scrape(t *testing.T, app *fiber.App) string {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestMetrics_HTTPRequests(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "test-service"})
+
+	app := fiber.New()
+	app.Use(m.Middleware())
+	app.Get("/metrics", m.Handler())
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusOK)
+	})
+
+	_, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/42", nil))
+	_, _ = app.Test(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	body := scrape(t, app)
+	assert.Contains(t, body, `test_service_http_requests_total{method="GET",route="/books/:id",status="200"} 1`)
+	assert.Contains(t, body, `test_service_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `test_service_http_request_duration_seconds_bucket`)
+	assert.Contains(t, body, `test_service_http_requests_in_flight`)
+}
+
+func TestMetrics_ErrorStatuses(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "test-service"})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(m.Middleware())
+	app.Get("/metrics", m.Handler())
+	app.Post("/books", func(c *fiber.Ctx) error {
+		return &validator.ModelValidationError{
+			ResponseBody: wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeValidationFailed, contract.ErrorValidatePayload, nil),
+		}
+	})
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		return apperror.NotFound(contract.StatusCodeNotFound, contract.ErrorNotFound)
+	})
+
+	_, _ = app.Test(httptest.NewRequest(http.MethodPost, "/books", nil))
+	_, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/42", nil))
+
+	body := scrape(t, app)
+	assert.Contains(t, body, `test_service_http_requests_total{method="POST",route="/books",status="400"} 1`)
+	assert.Contains(t, body, `test_service_http_requests_total{method="GET",route="/books/:id",status="404"} 1`)
+	assert.NotContains(t, body, `status="500"`)
+}
+
+func TestMetrics_RecoveredPanic(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "test-service"})
+
+	app := fiber.New()
+	app.Use(m.Middleware())
+	app.Use(middleware.PanicRecovery(middleware.PanicRecoveryOpts{Logger: zap.NewNop()}))
+	app.Get("/metrics", m.Handler())
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		panic("boom")
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/books/42", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, scrape(t, app), `test_service_http_requests_total{method="GET",route="/books/:id",status="500"} 1`)
+}
+
+func TestMetrics_DBStatsAndSlowQueries(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "svc"})
+	assert.NoError(t, m.RegisterDBStats(fakeDB{}))
+	m.ObserveSlowQuery(context.Background(), "SELECT * FROM books", 0)
+
+	app := fiber.New()
+	app.Get("/metrics", m.Handler())
+
+	body := scrape(t, app)
+	assert.Contains(t, body, "svc_db_open_connections 3")
+	assert.Contains(t, body, "svc_db_wait_count_total 7")
+	assert.Contains(t, body, `svc_db_slow_queries_total{operation="select"} 1`)
+}
+
+func TestMetrics_BusinessMetrics(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "svc"})
+
+	// requesting the same metric twice returns the registered collector
+	first := m.Counter("books_created_total", "Books created.", "source")
+	second := m.Counter("books_created_total", "Books created.", "source")
+	first.WithLabelValues("api").Inc()
+	second.WithLabelValues("api").Inc()
+
+	app := fiber.New()
+	app.Get("/metrics", m.Handler())
+	assert.Contains(t, scrape(t, app), `svc_books_created_total{source="api"} 2`)
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Metrics"
+
+	// RouteUnmatched labels requests that did not match any registered route.
+	RouteUnmatched = "unmatched"
+)
+
+// Opts represents the options for configuring the metrics service.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Namespace prefixes every metric name, for example the service name.
+	Namespace string
+}
+
+// DBStatsProvider is implemented by services exposing sql.DBStats, such as database.DBService.
+type DBStatsProvider interface {
+	Stats() sql.DBStats
+}
+
+// RedisPoolStatsProvider is implemented by services exposing the Redis pool stats, such as redis.Service.
+type RedisPoolStatsProvider interface {
+	PoolStats() *redis.PoolStats
+}
+
+// Service represents the metrics service.
+type Service struct {
+	Registry *prometheus.Registry
+
+	logger    *zap.Logger
+	namespace string
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	httpInFlight prometheus.Gauge
+	slowQueries  *prometheus.CounterVec
+}
+
+// IMetricsService represents the interface for the metrics service.
+type IMetricsService interface {
+	// Register registers custom collectors, for example business metrics of a usecase.
+	//
+	// Parameters:
+	//   - collectors: collectors to register
+	//
+	// Returns:
+	//   - error: error
+	Register(collectors ...prometheus.Collector) error
+
+	// RegisterDBStats exports the connection pool stats of the database.
+	//
+	// Parameters:
+	//   - provider: database exposing sql.DBStats
+	//
+	// Returns:
+	//   - error: error
+	RegisterDBStats(provider DBStatsProvider) error
+
+	// RegisterRedisPoolStats exports the connection pool stats of Redis.
+	//
+	// Parameters:
+	//   - provider: Redis service exposing its pool stats
+	//
+	// Returns:
+	//   - error: error
+	RegisterRedisPoolStats(provider RedisPoolStatsProvider) error
+
+	// Counter returns the counter vector with the given name, registering it on first use.
+	//
+	// Parameters:
+	//   - name: metric name without namespace
+	//   - help: metric description
+	//   - labels: label names
+	//
+	// Returns:
+	//   - *prometheus.CounterVec: counter vector
+	Counter(name string, help string, labels ...string) *prometheus.CounterVec
+
+	// Histogram returns the histogram vector with the given name, registering it on first use.
+	//
+	// Parameters:
+	//   - name: metric name without namespace
+	//   - help: metric description
+	//   - buckets: histogram buckets, default buckets when nil
+	//   - labels: label names
+	//
+	// Returns:
+	//   - *prometheus.HistogramVec: histogram vector
+	Histogram(name string, help string, buckets []float64, labels ...string) *prometheus.HistogramVec
+
+	// Gauge returns the gauge vector with the given name, registering it on first use.
+	//
+	// Parameters:
+	//   - name: metric name without namespace
+	//   - help: metric description
+	//   - labels: label names
+	//
+	// Returns:
+	//   - *prometheus.GaugeVec: gauge vector
+	Gauge(name string, help string, labels ...string) *prometheus.GaugeVec
+
+	// Middleware returns the Fiber middleware recording request count, latency and in-flight requests.
+	Middleware() fiber.Handler
+
+	// Handler returns the Fiber handler serving metrics in the Prometheus text format.
+	Handler() fiber.Handler
+
+	// ObserveSlowQuery counts a query slower than the database slow threshold.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - sql: executed query
+	//   - elapsed: query duration
+	ObserveSlowQuery(ctx context.Context, sql string, elapsed time.Duration)
+}
@@ -1,10 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/Alwanly/go-codebase/pkg/authentication"
+	"github.com/Alwanly/go-codebase/pkg/utils"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -25,9 +27,13 @@ type AuthMiddleware struct {
 type AuthConfig func(*AuthOpts)
 
 type AuthUserData struct {
-	UserID string `json:"userId"`
+	UserID   string   `json:"userId"`
+	Roles    []string `json:"roles,omitempty"`
+	TenantID string   `json:"tenantId,omitempty"`
 }
 
+type userContextKey struct{}
+
 type AuthOpts struct {
 	*authentication.JWTConfig
 	*authentication.BasicAuthTConfig
@@ -64,31 +70,43 @@ func NewAuthMiddleware(opts ...AuthConfig) *AuthMiddleware {
 
 func (a *AuthMiddleware) JwtAuth() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
-		// get token from header
-		token := ctx.Get(fiber.HeaderAuthorization)
-		if !strings.Contains(token, "Bearer") {
-			return responseUnauthorized(ctx, "Bearer", "Invalid token")
-		}
-
-		// validate token
-		token = strings.Replace(token, "Bearer ", "", 1)
-		if token == "" {
-			return responseUnauthorized(ctx, "Bearer", "Invalid token")
-		}
-
-		// parse token
-		auth, err := a.Jwt.ParseToken(token)
-		if err != nil {
+		// parse token from header
+		user, ok := a.UserFromRequest(ctx)
+		if !ok {
 			return responseUnauthorized(ctx, "Bearer", "Invalid token")
 		}
 
 		// set claims to context
-		ctx.Locals(LocalTokenKey, decodeAuthToken(*auth))
+		ctx.Locals(LocalTokenKey, user)
+		ctx.SetUserContext(WithUser(ctx.UserContext(), user))
 
 		return ctx.Next()
 	}
 }
 
+// UserFromRequest returns the user of the request's bearer token without rejecting the request,
+// for middlewares running before authentication.
+func (a *AuthMiddleware) UserFromRequest(ctx *fiber.Ctx) (*AuthUserData, bool) {
+	// get token from header
+	token := ctx.Get(fiber.HeaderAuthorization)
+	if !strings.Contains(token, "Bearer") {
+		return nil, false
+	}
+
+	// validate token
+	token = strings.Replace(token, "Bearer ", "", 1)
+	if token == "" {
+		return nil, false
+	}
+
+	// parse token
+	auth, err := a.Jwt.ParseToken(token)
+	if err != nil {
+		return nil, false
+	}
+	return decodeAuthToken(*auth), true
+}
+
 func (a *AuthMiddleware) BasicAuth() fiber.Handler {
 	return func(ctx *fiber.Ctx) error {
 		// get auth from header
@@ -102,14 +120,48 @@ func (a *AuthMiddleware) BasicAuth() fiber.Handler {
 		if !a.Basic.Validate(username, password) {
 			return responseUnauthorized(ctx, "Basic", "Invalid auth")
 		}
+
+		// the username identifies the admin acting, e.g. as the author of a change
+		user := &AuthUserData{UserID: username}
+		ctx.Locals(LocalTokenKey, user)
+		ctx.SetUserContext(WithUser(ctx.UserContext(), user))
 		return ctx.Next()
 	}
 }
 
 func decodeAuthToken(dataClaims authentication.JWTClaims) *AuthUserData {
-	return &AuthUserData{
+	user := &AuthUserData{
 		UserID: dataClaims["userId"].(string),
 	}
+
+	// roles and tenant are optional
+	if tenantID, ok := dataClaims["tenantId"].(string); ok {
+		user.TenantID = tenantID
+	}
+	if roles, ok := dataClaims["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				user.Roles = append(user.Roles, name)
+			}
+		}
+	}
+	return user
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *AuthUserData) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user carried by ctx, set by JwtAuth and BasicAuth.
+func UserFromContext(ctx context.Context) (*AuthUserData, bool) {
+	user, ok := ctx.Value(userContextKey{}).(*AuthUserData)
+	return user, ok && user != nil
+}
+
+// HasRole reports whether the user has one of the given roles.
+func (u *AuthUserData) HasRole(roles ...string) bool {
+	return utils.AnySliceInSlice(roles, u.Roles)
 }
 
 func responseUnauthorized(c *fiber.Ctx, _ string, message ...string) error {
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/cache"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderCache   = "X-Cache"
+	CacheHit      = "HIT"
+	CacheMiss     = "MISS"
+	cacheAnyMatch = "*"
+)
+
+// CacheOpts represents the options for the response cache middleware.
+type CacheOpts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Cache stores the serialized responses. When nil, only ETags and conditional GET are handled.
+	Cache cache.ICacheService
+
+	// Tags returns the tags attached to the cached response, used to invalidate it later.
+	Tags func(c *fiber.Ctx) []string
+	// KeyGenerator returns the cache key of the request. Default is the caller, method, resolved API
+	// version and URL.
+	KeyGenerator func(c *fiber.Ctx) string
+}
+
+// Cache computes strong ETags for successful GET responses, answers If-None-Match with
+// 304 Not Modified and optionally serves responses from the cache.
+func Cache(opts CacheOpts) fiber.Handler {
+	l := logger.WithID(opts.Logger, "Middleware.Cache", "Cache")
+	keyGenerator := opts.KeyGenerator
+	if keyGenerator == nil {
+		keyGenerator = defaultCacheKey
+	}
+
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		key := keyGenerator(c)
+
+		// serve from cache
+		if opts.Cache != nil {
+			entry, err := opts.Cache.Get(ctx, key)
+			if err != nil {
+				l.Warn("Cannot read cached response", zap.Error(err))
+			}
+			if entry != nil {
+				c.Set(HeaderCache, CacheHit)
+				return sendCachedEntry(c, entry)
+			}
+		}
+
+		// the versions of the tags tell a response rendered before a concurrent write apart
+		var versions cache.Versions
+		if opts.Cache != nil {
+			var tags []string
+			if opts.Tags != nil {
+				tags = opts.Tags(c)
+			}
+			var err error
+			if versions, err = opts.Cache.TagVersions(ctx, tags...); err != nil {
+				l.Warn("Cannot read cache tag versions", zap.Error(err))
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if c.Response().StatusCode() != http.StatusOK {
+			return nil
+		}
+
+		entry := &cache.Entry{
+			Code:        http.StatusOK,
+			ETag:        strongETag(c.Response().Body()),
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        append([]byte(nil), c.Response().Body()...),
+		}
+
+		if opts.Cache != nil {
+			if versions != nil {
+				stored, err := opts.Cache.SetVersioned(ctx, key, entry, versions)
+				if err != nil {
+					l.Warn("Cannot store cached response", zap.Error(err))
+				} else if !stored {
+					l.Debug("Response invalidated while rendered, not cached", zap.String("key", key))
+				}
+			}
+			c.Set(HeaderCache, CacheMiss)
+		}
+
+		return sendCachedEntry(c, entry)
+	}
+}
+
+func sendCachedEntry(c *fiber.Ctx, entry *cache.Entry) error {
+	// the version of unversioned paths is negotiated with the Accept header
+	c.Vary(fiber.HeaderAccept)
+	c.Set(fiber.HeaderETag, entry.ETag)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), entry.ETag) {
+		c.Response().ResetBody()
+		return c.SendStatus(http.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, entry.ContentType)
+	return c.Status(entry.Code).Send(entry.Body)
+}
+
+// strongETag returns a strong validator derived from the response body.
+func strongETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// etagMatches implements the weak comparison required for If-None-Match.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == cacheAnyMatch || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultCacheKey(c *fiber.Ctx) string {
+	scope := "anonymous"
+	if authUser, ok := c.Locals(LocalTokenKey).(*AuthUserData); ok && authUser != nil {
+		scope = authUser.UserID
+	}
+
+	// the URL of a version negotiated with the Accept header is shared by all the versions
+	return scope + ":" + c.Method() + ":" + versioning.FromContext(c) + ":" + c.OriginalURL()
+}
@@ -0,0 +1,146 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/cache"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestCache_ETagAndConditionalGet(t *testing.T) {
+	app := fiber.New()
+	app.Get("/books/:id", middleware.Cache(middleware.CacheOpts{Logger: zap.NewNop()}), func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"id": c.Params("id")})
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	etag := resp.Header.Get(fiber.HeaderETag)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEmpty(t, etag)
+	assert.NotContains(t, etag, "W/")
+
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	resp, _ = app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.Empty(t, body)
+
+	req = httptest.NewRequest(http.MethodGet, "/books/2", nil)
+	req.Header.Set(fiber.HeaderIfNoneMatch, etag)
+	resp, _ = app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCache_InvalidateByTag(t *testing.T) {
+	mr := miniredis.RunT(t)
+	responseCache := cache.NewCache(&cache.Opts{
+		Logger: zap.NewNop(),
+		Redis:  &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+	})
+
+	calls := 0
+	app := fiber.New()
+	app.Get("/books/:id", middleware.Cache(middleware.CacheOpts{
+		Logger: zap.NewNop(),
+		Cache:  responseCache,
+		Tags: func(c *fiber.Ctx) []string {
+			return []string{"book:" + c.Params("id")}
+		},
+	}), func(c *fiber.Ctx) error {
+		calls++
+		return c.JSON(fiber.Map{"calls": calls})
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	assert.Equal(t, middleware.CacheMiss, resp.Header.Get(middleware.HeaderCache))
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, middleware.CacheHit, resp.Header.Get(middleware.HeaderCache))
+	assert.JSONEq(t, `{"calls":1}`, string(body))
+
+	assert.NoError(t, responseCache.InvalidateTags(context.Background(), "book:1"))
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, middleware.CacheMiss, resp.Header.Get(middleware.HeaderCache))
+	assert.JSONEq(t, `{"calls":2}`, string(body))
+}
+
+func TestCache_KeyedByNegotiatedVersion(t *testing.T) {
+	mr := miniredis.RunT(t)
+	responseCache := cache.NewCache(&cache.Opts{
+		Logger: zap.NewNop(),
+		Redis:  &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+	})
+
+	app := fiber.New()
+	books := versioning.NewRegistry(&versioning.Opts{Logger: zap.NewNop(), Vendor: "codebase"}).Resource(app, "/books")
+	for _, version := range []string{"v1", "v2"} {
+		books.Version(versioning.Version{Name: version}, func(e fiber.Router) {
+			e.Get("/:id", middleware.Cache(middleware.CacheOpts{Logger: zap.NewNop(), Cache: responseCache}), func(c *fiber.Ctx) error {
+				return c.JSON(fiber.Map{"id": c.Params("id"), "version": version})
+			})
+		})
+	}
+
+	for _, version := range []string{"1", "2", "1"} {
+		req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+		req.Header.Set(fiber.HeaderAccept, "application/json; version="+version)
+		resp, _ := app.Test(req)
+		body, _ := io.ReadAll(resp.Body)
+		assert.JSONEq(t, `{"id":"1","version":"v`+version+`"}`, string(body))
+		assert.Equal(t, fiber.HeaderAccept, resp.Header.Get(fiber.HeaderVary))
+	}
+}
+
+func TestCache_InvalidatedWhileRendering(t *testing.T) {
+	mr := miniredis.RunT(t)
+	responseCache := cache.NewCache(&cache.Opts{
+		Logger: zap.NewNop(),
+		Redis:  &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+	})
+
+	calls := 0
+	app := fiber.New()
+	app.Get("/books/:id", middleware.Cache(middleware.CacheOpts{
+		Logger: zap.NewNop(),
+		Cache:  responseCache,
+		Tags: func(c *fiber.Ctx) []string {
+			return []string{"book:" + c.Params("id")}
+		},
+	}), func(c *fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			// a write commits and invalidates the book after the handler read it
+			assert.NoError(t, responseCache.InvalidateTags(context.Background(), "book:1"))
+		}
+		return c.JSON(fiber.Map{"calls": calls})
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	assert.Equal(t, middleware.CacheMiss, resp.Header.Get(middleware.HeaderCache))
+
+	// the response rendered before the write was not stored
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, middleware.CacheMiss, resp.Header.Get(middleware.HeaderCache))
+	assert.JSONEq(t, `{"calls":2}`, string(body))
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, middleware.CacheHit, resp.Header.Get(middleware.HeaderCache))
+	assert.JSONEq(t, `{"calls":2}`, string(body))
+}
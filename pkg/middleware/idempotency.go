@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	DefaultIdempotencyTTL     = 24 * time.Hour
+	DefaultIdempotencyLockTTL = 30 * time.Second
+	DefaultIdempotencyPrefix  = "idempotency"
+
+	idempotencyStatusInFlight  = "in_flight"
+	idempotencyStatusCompleted = "completed"
+
+	// idempotencyAcquireAttempts bounds the attempts at acquiring a key released by the original
+	// request between the SETNX and the read of its record.
+	idempotencyAcquireAttempts = 3
+)
+
+// errIdempotencyKeyReleased is returned by replayIdempotentResponse when the key was released
+// before its record could be read.
+var errIdempotencyKeyReleased = errors.New("idempotency key released")
+
+// IdempotencyOpts represents the options for the idempotency middleware.
+type IdempotencyOpts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Redis stores the idempotency records.
+	Redis redis.IRedisService
+
+	// TTL is how long a completed response is kept for replay. Default is 24 hours.
+	TTL time.Duration
+	// LockTTL is how long a request may stay in flight before the key is released. Default is 30 seconds.
+	LockTTL time.Duration
+	// KeyPrefix is the Redis key prefix. Default is "idempotency".
+	KeyPrefix string
+}
+
+type idempotencyRecord struct {
+	Status      string `json:"status"`
+	Fingerprint string `json:"fingerprint"`
+	// Token identifies the request holding the in-flight record.
+	Token    string               `json:"token,omitempty"`
+	Response *idempotencyResponse `json:"response,omitempty"`
+}
+
+type idempotencyResponse struct {
+	Code    int                 `json:"code"`
+	Headers map[string][]string `json:"headers"`
+	Body    []byte              `json:"body"`
+}
+
+// Idempotency honors the Idempotency-Key header on unsafe methods. The first response
+// for a key, including a client error, is stored and replayed for retries, a retry that arrives while the original
+// request is still running gets 409, and reusing a key with a different payload gets 422. A request
+// whose key cannot be acquired or read gets 503 rather than running the request twice. The response
+// is only stored while the request still holds the key.
+func Idempotency(opts IdempotencyOpts) fiber.Handler {
+	l := logger.WithID(opts.Logger, "Middleware.Idempotency", "Idempotency")
+	ttl := utils.IfThenElse(opts.TTL > 0, opts.TTL, DefaultIdempotencyTTL)
+	lockTTL := utils.IfThenElse(opts.LockTTL > 0, opts.LockTTL, DefaultIdempotencyLockTTL)
+	prefix := utils.IfThenElse(opts.KeyPrefix != "", opts.KeyPrefix, DefaultIdempotencyPrefix)
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" || isSafeMethod(c.Method()) {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		storeKey := idempotencyStoreKey(c, prefix, key)
+		fingerprint := idempotencyFingerprint(c)
+
+		// try to acquire the key for this request, the token tells its record from the ones of the
+		// requests acquiring the key after it expired
+		lock, _ := utils.JSONMarshal(idempotencyRecord{Status: idempotencyStatusInFlight, Fingerprint: fingerprint, Token: idempotencyToken()})
+		for attempt := 1; ; attempt++ {
+			acquired, err := opts.Redis.SetNX(ctx, storeKey, lock, lockTTL)
+			if err != nil {
+				// running the request without the key could duplicate a retry
+				l.Error("Cannot acquire idempotency key", zap.Error(err))
+				return wrapper.Send(c, wrapper.ResponseFailed(http.StatusServiceUnavailable, contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable, nil))
+			}
+			if acquired {
+				break
+			}
+
+			err = replayIdempotentResponse(c, l, opts.Redis, storeKey, fingerprint)
+			if !errors.Is(err, errIdempotencyKeyReleased) {
+				return err
+			}
+			if attempt == idempotencyAcquireAttempts {
+				return wrapper.Send(c, wrapper.ResponseFailed(http.StatusConflict, contract.StatusCodeIdempotencyInFlight, contract.ErrorIdempotencyInFlight, nil))
+			}
+		}
+
+		// execute the request and release the key when it cannot be replayed
+		if err := c.Next(); err != nil {
+			if wrapper.ResponseFromError(err).Code >= http.StatusInternalServerError {
+				releaseIdempotencyKey(ctx, l, opts.Redis, storeKey, lock)
+				return err
+			}
+			// a client error is the first response too, write it here so it can be stored
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				releaseIdempotencyKey(ctx, l, opts.Redis, storeKey, lock)
+				return err
+			}
+		}
+
+		code := c.Response().StatusCode()
+		if code >= http.StatusInternalServerError {
+			releaseIdempotencyKey(ctx, l, opts.Redis, storeKey, lock)
+			return nil
+		}
+
+		record, _ := utils.JSONMarshal(idempotencyRecord{
+			Status:      idempotencyStatusCompleted,
+			Fingerprint: fingerprint,
+			Response: &idempotencyResponse{
+				Code:    code,
+				Headers: c.GetRespHeaders(),
+				Body:    c.Response().Body(),
+			},
+		})
+		// the handler may have answered after the deadline, the response must be stored anyway
+		stored, err := opts.Redis.CompareAndSet(context.WithoutCancel(ctx), storeKey, string(lock), record, ttl)
+		if err != nil {
+			l.Error("Cannot store idempotent response", zap.Error(err))
+		} else if !stored {
+			l.Warn("Idempotency key expired before the response was stored", zap.Duration("lockTTL", lockTTL))
+		}
+
+		return nil
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is held by another request with its stored
+// response, or errIdempotencyKeyReleased when the key was released in the meantime.
+func replayIdempotentResponse(c *fiber.Ctx, l *zap.Logger, r redis.IRedisService, storeKey string, fingerprint string) error {
+	raw, err := r.Get(c.UserContext(), storeKey)
+	if errors.Is(err, redis.ErrNil) {
+		// the original request released the key in the meantime
+		return errIdempotencyKeyReleased
+	}
+	if err != nil {
+		// running the request again could duplicate the original one
+		l.Error("Cannot read idempotency key", zap.Error(err))
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusServiceUnavailable, contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable, nil))
+	}
+
+	record := idempotencyRecord{}
+	if err := utils.JSONUnMarshal([]byte(raw), &record); err != nil {
+		l.Error("Cannot decode idempotency record", zap.Error(err))
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusServiceUnavailable, contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable, nil))
+	}
+
+	if record.Fingerprint != fingerprint {
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusUnprocessableEntity, contract.StatusCodeIdempotencyMismatch, contract.ErrorIdempotencyMismatch, nil))
+	}
+
+	if record.Status != idempotencyStatusCompleted || record.Response == nil {
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusConflict, contract.StatusCodeIdempotencyInFlight, contract.ErrorIdempotencyInFlight, nil))
+	}
+
+	replayIdempotentHeaders(c, record.Response.Headers)
+	c.Set(HeaderIdempotentReplayed, "true")
+	return c.Status(record.Response.Code).Send(record.Response.Body)
+}
+
+// replayIdempotentHeaders writes the stored headers the middlewares did not already set for this
+// request, e.g. X-Request-ID and the security headers are kept rather than duplicated.
+func replayIdempotentHeaders(c *fiber.Ctx, headers map[string][]string) {
+	for name, values := range headers {
+		switch name {
+		case fiber.HeaderContentLength, fiber.HeaderDate:
+			continue
+		case fiber.HeaderContentType:
+			c.Set(name, values[0])
+			continue
+		case fiber.HeaderVary:
+			for _, value := range values {
+				for _, field := range strings.Split(value, ",") {
+					c.Vary(strings.TrimSpace(field))
+				}
+			}
+			continue
+		}
+		if len(c.Response().Header.Peek(name)) > 0 {
+			continue
+		}
+		for _, value := range values {
+			c.Response().Header.Add(name, value)
+		}
+	}
+}
+
+// releaseIdempotencyKey deletes the in-flight record of the request, unless another request
+// acquired the key after it expired. The request context may be past its deadline.
+func releaseIdempotencyKey(ctx context.Context, l *zap.Logger, r redis.IRedisService, storeKey string, lock []byte) {
+	if _, err := r.CompareAndDelete(context.WithoutCancel(ctx), storeKey, string(lock)); err != nil {
+		l.Error("Cannot release idempotency key", zap.Error(err))
+	}
+}
+
+// idempotencyStoreKey scopes the key to the caller and the endpoint, so two users cannot collide.
+func idempotencyStoreKey(c *fiber.Ctx, prefix string, key string) string {
+	scope := "anonymous"
+	if authUser, ok := c.Locals(LocalTokenKey).(*AuthUserData); ok && authUser != nil {
+		scope = authUser.UserID
+	}
+
+	return prefix + ":" + scope + ":" + c.Method() + ":" + c.Path() + ":" + key
+}
+
+// idempotencyToken returns a random token identifying a request holding a key.
+func idempotencyToken() string {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+func idempotencyFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(c.OriginalURL()))
+	h.Write([]byte{'\n'})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,302 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newIdempotencyApp(t *testing.T, handler fiber.Handler) (*fiber.App, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	r := &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})}
+	return newIdempotencyAppWithRedis(r, handler), mr
+}
+
+func newIdempotencyAppWithRedis(r redis.IRedisService, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Post("/books", middleware.Idempotency(middleware.IdempotencyOpts{
+		Logger: zap.NewNop(),
+		Redis:  r,
+	}), handler)
+	return app
+}
+
+// flakyRedis fails the reads of the idempotency records with getErr, after running onGet, and the
+// acquisitions of the keys with setNXErr.
+type flakyRedis struct {
+	redis.IRedisService
+	getErr   error
+	setNXErr error
+	onGet    func()
+}
+
+func (r *flakyRedis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if r.setNXErr != nil {
+		return false, r.setNXErr
+	}
+	return r.IRedisService.SetNX(ctx, key, value, ttl)
+}
+
+func (r *flakyRedis) Get(ctx context.Context, key string) (string, error) {
+	if r.onGet != nil {
+		r.onGet()
+	}
+	if r.getErr != nil {
+		return "", r.getErr
+	}
+	return r.IRedisService.Get(ctx, key)
+}
+
+func newIdempotentRequest(key string, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.HeaderIdempotencyKey, key)
+	return req
+}
+
+func TestIdempotency_ReplaysFirstResponse(t *testing.T) {
+	var calls int32
+	app, _ := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		c.Set("X-Book-ID", "book-1")
+		return c.Status(http.StatusCreated).SendString(`{"id":"book-1"}`)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"id":"book-1"}`, string(body))
+	assert.Equal(t, "book-1", resp.Header.Get("X-Book-ID"))
+	assert.Equal(t, "true", resp.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_DifferentPayload(t *testing.T) {
+	app, _ := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{"title":"Emma"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	var app *fiber.App
+	app, _ = newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		// a retry arrives while the original request is still running
+		resp, _ := app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`))
+		assert.Equal(t, http.StatusConflict, resp.StatusCode)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
+
+func TestIdempotency_ReleasesKeyOnServerError(t *testing.T) {
+	var calls int32
+	app, _ := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return c.SendStatus(http.StatusInternalServerError)
+		}
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_WithoutKey(t *testing.T) {
+	var calls int32
+	app, mr := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/books", strings.NewReader(`{}`))
+		resp, _ := app.Test(req)
+		assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Empty(t, mr.Keys())
+}
+
+func TestIdempotency_ReadFailureDoesNotRunRequest(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &flakyRedis{
+		IRedisService: &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+		getErr:        errors.New("connection reset"),
+	}
+	var calls int32
+	app := newIdempotencyAppWithRedis(r, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	// the key is held by a request whose record cannot be read
+	mr.Set("idempotency:anonymous:POST:/books:key-1", `{"status":"in_flight"}`)
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_KeyReleasedBeforeRead(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &flakyRedis{IRedisService: &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})}}
+	var calls int32
+	app := newIdempotencyAppWithRedis(r, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	// the key expires between the SETNX and the read, the request acquires it again
+	key := "idempotency:anonymous:POST:/books:key-1"
+	mr.Set(key, `{"status":"in_flight"}`)
+	r.onGet = func() { mr.Del(key) }
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.True(t, mr.Exists(key))
+}
+
+func TestIdempotency_AcquireFailureDoesNotRunRequest(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &flakyRedis{
+		IRedisService: &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})},
+		setNXErr:      errors.New("circuit breaker is open"),
+	}
+	var calls int32
+	app := newIdempotencyAppWithRedis(r, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_ExpiredKeyNotOverwritten(t *testing.T) {
+	key := "idempotency:anonymous:POST:/books:key-1"
+	other := `{"status":"in_flight","fingerprint":"other","token":"other"}`
+	var mr *miniredis.Miniredis
+	app, mr := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		// the lock expires and another request acquires the key while the handler runs
+		mr.Set(key, other)
+		return c.SendStatus(http.StatusCreated)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	stored, _ := mr.Get(key)
+	assert.Equal(t, other, stored)
+
+	// nor released by a failed request
+	app, mr = newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		mr.Set(key, other)
+		return c.SendStatus(http.StatusInternalServerError)
+	})
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	stored, _ = mr.Get(key)
+	assert.Equal(t, other, stored)
+}
+
+func TestIdempotency_StoresResponseWrittenAfterDeadline(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})}
+	var calls int32
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(middleware.Timeout(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond}))
+	app.Post("/books", middleware.Idempotency(middleware.IdempotencyOpts{Logger: zap.NewNop(), Redis: r}), func(c *fiber.Ctx) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// the commit returns just after the deadline
+			time.Sleep(40 * time.Millisecond)
+		}
+		return c.Status(http.StatusCreated).SendString(`{"id":"book-1"}`)
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`), -1)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{"title":"Dune"}`), -1)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"id":"book-1"}`, string(body))
+	assert.Equal(t, "true", resp.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIdempotency_ReplayKeepsMiddlewareHeaders(t *testing.T) {
+	mr := miniredis.RunT(t)
+	r := &redis.Service{Redis: redisv9.NewClient(&redisv9.Options{Addr: mr.Addr()})}
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Use(func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+		c.Vary(fiber.HeaderAccept)
+		return c.Next()
+	})
+	app.Post("/books", middleware.Idempotency(middleware.IdempotencyOpts{Logger: zap.NewNop(), Redis: r}), func(c *fiber.Ctx) error {
+		c.Location("/books/book-1")
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Status(http.StatusCreated).JSON(fiber.Map{"id": "book-1"})
+	})
+
+	first, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusCreated, first.StatusCode)
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, "true", resp.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Len(t, resp.Header.Values(middleware.HeaderRequestID), 1)
+	assert.NotEqual(t, first.Header.Get(middleware.HeaderRequestID), resp.Header.Get(middleware.HeaderRequestID))
+	assert.Equal(t, []string{"nosniff"}, resp.Header.Values(fiber.HeaderXContentTypeOptions))
+	assert.Equal(t, []string{"Accept, Accept-Language"}, resp.Header.Values(fiber.HeaderVary))
+	assert.Equal(t, "/books/book-1", resp.Header.Get(fiber.HeaderLocation))
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+}
+
+func TestIdempotency_ReplaysClientError(t *testing.T) {
+	var calls int32
+	app, _ := newIdempotencyApp(t, func(c *fiber.Ctx) error {
+		atomic.AddInt32(&calls, 1)
+		return fiber.NewError(http.StatusBadRequest, "title is required")
+	})
+
+	resp, _ := app.Test(newIdempotentRequest("key-1", `{}`))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, _ = app.Test(newIdempotentRequest("key-1", `{}`))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, "title is required", string(body))
+	assert.Equal(t, "true", resp.Header.Get(middleware.HeaderIdempotentReplayed))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/errorreport"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// HeaderReferenceID carries the reference ID of a crash report so support can search for it.
+// The report also carries the X-Request-ID of the request.
+const HeaderReferenceID = "X-Reference-ID"
+
+// filteredHeaders are never attached to crash reports.
+var filteredHeaders = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+	"x-api-key":           true,
+}
+
+type PanicRecoveryOpts struct {
+	Logger *zap.Logger
+	// Reporter receives the crash reports. Default writes them to Logger.
+	Reporter errorreport.ErrorReporter
+}
+
+// PanicRecovery recovers from panics in the next handlers, sends a crash report with the stack,
+// request metadata and authenticated user to the reporter, and responds 500 with the report's
+// reference ID in the body and the X-Reference-ID header.
+func PanicRecovery(opts PanicRecoveryOpts) fiber.Handler {
+	l := logger.WithID(opts.Logger, "Middleware.PanicRecovery", "PanicRecovery")
+	reporter := opts.Reporter
+	if reporter == nil {
+		reporter = errorreport.NewZapReporter(opts.Logger)
+	}
+
+	return func(c *fiber.Ctx) (err error) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			report := newPanicReport(c, recovered)
+			// the request context may already be cancelled, the report must still be sent
+			if reportErr := reporter.Report(context.WithoutCancel(c.UserContext()), report); reportErr != nil {
+				l.Error("Cannot report panic", zap.String("reference.id", report.ID), zap.Error(reportErr))
+			}
+
+			c.Response().ResetBody()
+			c.Set(HeaderReferenceID, report.ID)
+			err = wrapper.Send(c, wrapper.ResponseFailed(http.StatusInternalServerError, contract.StatusCodeInternalServerError,
+				contract.ErrorInternalServer, wrapper.ReferenceData{ReferenceID: report.ID}))
+		}()
+
+		return c.Next()
+	}
+}
+
+func newPanicReport(c *fiber.Ctx, recovered interface{}) *errorreport.Report {
+	headers := map[string]string{}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if filteredHeaders[strings.ToLower(name)] {
+			headers[name] = "[Filtered]"
+			return
+		}
+		headers[name] = string(value)
+	})
+
+	report := &errorreport.Report{
+		ID:    errorreport.NewID(),
+		Time:  time.Now(),
+		Panic: recovered,
+		// skip the deferred function and runtime.gopanic
+		Stack: errorreport.CaptureStack(3),
+		Request: &errorreport.Request{
+			ID:      RequestIDFromContext(c.UserContext()),
+			Method:  c.Method(),
+			URL:     c.OriginalURL(),
+			Route:   c.Route().Path,
+			IP:      c.IP(),
+			Headers: headers,
+		},
+	}
+
+	if err, ok := recovered.(error); ok {
+		report.Message = err.Error()
+	} else {
+		report.Message = fmt.Sprint(recovered)
+	}
+
+	if authUser, ok := c.Locals(LocalTokenKey).(*AuthUserData); ok && authUser != nil {
+		report.User = &errorreport.User{ID: authUser.UserID}
+	}
+
+	if spanCtx := trace.SpanContextFromContext(c.UserContext()); spanCtx.IsValid() {
+		report.TraceID = spanCtx.TraceID().String()
+		report.SpanID = spanCtx.SpanID().String()
+	}
+
+	return report
+}
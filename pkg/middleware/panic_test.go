@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/errorreport"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubReporter struct {
+	reports []*errorreport.Report
+}
+
+func (s *stubReporter) Report(_ context.Context, r *errorreport.Report) error {
+	s.reports = append(s.reports, r)
+	return nil
+}
+
+func TestPanicRecovery(t *testing.T) {
+	reporter := &stubReporter{}
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(middleware.RequestID())
+	app.Use(middleware.PanicRecovery(middleware.PanicRecoveryOpts{Logger: zap.NewNop(), Reporter: reporter}))
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		c.Locals(middleware.LocalTokenKey, &middleware.AuthUserData{UserID: "user-1"})
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books/1?draft=true", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("User-Agent", "test")
+	req.Header.Set(middleware.HeaderRequestID, "req-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+
+	require.Len(t, reporter.reports, 1)
+	report := reporter.reports[0]
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, report.ID, resp.Header.Get(middleware.HeaderReferenceID))
+	assert.Contains(t, string(body), `"referenceId":"`+report.ID+`"`)
+	assert.Len(t, report.ID, 32)
+
+	assert.Equal(t, "boom", report.Message)
+	assert.Equal(t, "/books/1?draft=true", report.Request.URL)
+	assert.Equal(t, "/books/:id", report.Request.Route)
+	assert.Equal(t, "req-1", report.Request.ID)
+	assert.Equal(t, "[Filtered]", report.Request.Headers["Authorization"])
+	assert.Equal(t, "test", report.Request.Headers["User-Agent"])
+	require.NotNil(t, report.User)
+	assert.Equal(t, "user-1", report.User.ID)
+	require.NotEmpty(t, report.Stack)
+	assert.Contains(t, report.Stack[0].Function, "TestPanicRecovery")
+}
+
+func TestPanicRecovery_NoPanic(t *testing.T) {
+	reporter := &stubReporter{}
+	app := fiber.New()
+	app.Use(middleware.PanicRecovery(middleware.PanicRecoveryOpts{Logger: zap.NewNop(), Reporter: reporter}))
+	app.Get("/", func(c *fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) })
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(middleware.HeaderReferenceID))
+	assert.Empty(t, reporter.reports)
+}
@@ -1,20 +1,36 @@
 package middleware
 
 import (
-	"github.com/Alwanly/go-codebase/pkg/contract"
+	"context"
+	"errors"
+
 	"github.com/Alwanly/go-codebase/pkg/wrapper"
 	"github.com/gofiber/fiber/v2"
 	"go.uber.org/zap"
 )
 
+// Recover is the Fiber error handler. It maps every returned error to its response
+// through wrapper.ResponseFromError, so fiber.Error and domain errors keep their status.
 func Recover(l *zap.Logger) fiber.ErrorHandler {
 	return func(ctx *fiber.Ctx, err error) error {
-		l.Error("Unexpected error", zap.Error(err), zap.String("method", ctx.Method()), zap.String("url", ctx.Path()))
-		return ctx.Status(fiber.StatusInternalServerError).
-			JSON(wrapper.JSONResult{
-				Code:       fiber.StatusInternalServerError,
-				StatusCode: contract.StatusCodeInternalServerError,
-				Message:    "Internal server error",
-			})
+		logFields := []zap.Field{zap.Error(err), zap.String("method", ctx.Method()), zap.String("url", ctx.Path())}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			l.Warn("Request deadline exceeded", logFields...)
+			status, ok := ctx.Locals(localTimeoutStatusKey).(int)
+			if !ok {
+				status = fiber.StatusGatewayTimeout
+			}
+			return ResponseTimeout(ctx, status)
+		}
+
+		result := wrapper.ResponseFromError(err)
+		if result.Code >= fiber.StatusInternalServerError {
+			l.Error("Unexpected error", logFields...)
+		} else {
+			l.Debug("Request failed", append(logFields, zap.Int("status", result.Code))...)
+		}
+
+		return wrapper.Send(ctx, result)
 	}
 }
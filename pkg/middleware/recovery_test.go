@@ -0,0 +1,31 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRecover_MapsErrors(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		return apperror.NotFound(contract.StatusCodeNotFound, "Book not found")
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/books/1", nil))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.JSONEq(t, `{"statusCode":"000018","message":"Book not found","data":null}`, string(body))
+
+	// unknown routes keep the status raised by Fiber instead of becoming a 500
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
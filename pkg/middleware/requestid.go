@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries the request ID, kept from the client or generated.
+const HeaderRequestID = "X-Request-ID"
+
+// RequestID keeps the X-Request-ID header of the request, or generates one, and sends it back.
+// The ID is stored in the user context: it is forwarded on outbound calls, recorded in the audit
+// log and crash reports, and written to the logs of logger.WithContext as http.request.id.
+//
+// The X-Reference-ID of a crash is a separate ID, generated per report in the format error
+// trackers expect; the report carries the request ID to get from one to the other.
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// the header is reused by Fiber after the request, the ID outlives it in reports and logs
+		id := utils.CopyString(c.Get(HeaderRequestID))
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+
+		c.Set(HeaderRequestID, id)
+		c.SetUserContext(WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return logger.ContextWithRequestID(ctx, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx.
+func RequestIDFromContext(ctx context.Context) string {
+	return logger.RequestIDFromContext(ctx)
+}
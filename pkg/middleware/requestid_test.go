@@ -0,0 +1,29 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestID())
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString(middleware.RequestIDFromContext(c.UserContext()))
+	})
+
+	// kept from the client
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.HeaderRequestID, "req-1")
+	resp, _ := app.Test(req)
+	assert.Equal(t, "req-1", resp.Header.Get(middleware.HeaderRequestID))
+
+	// generated
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(t, resp.Header.Get(middleware.HeaderRequestID), 36)
+}
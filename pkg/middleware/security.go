@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+// CORSPolicy configures cross-origin requests.
+type CORSPolicy struct {
+	// AllowOrigins lists the allowed origins. Subdomain wildcards such as https://*.example.com
+	// are supported and "*" allows every origin. Empty allows no cross-origin request.
+	AllowOrigins     []string
+	AllowMethods     []string
+	AllowHeaders     []string
+	AllowCredentials bool
+	ExposeHeaders    []string
+	// MaxAge is how long browsers may cache a preflight response.
+	MaxAge time.Duration
+}
+
+// SecurityHeaders configures the security response headers. Empty values are not sent.
+type SecurityHeaders struct {
+	// HSTSMaxAge enables Strict-Transport-Security when greater than zero.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+
+	ContentSecurityPolicy string
+	ContentTypeNosniff    bool
+	ReferrerPolicy        string
+	FrameOptions          string
+}
+
+// SecurityPolicy is the CORS and security headers policy of a set of routes.
+type SecurityPolicy struct {
+	CORS    CORSPolicy
+	Headers SecurityHeaders
+}
+
+// RouteSecurity overrides the policy of the routes under a path prefix.
+type RouteSecurity struct {
+	// Prefix is the path prefix of the route group, for example /swagger.
+	Prefix string
+	// Override changes a copy of the default policy.
+	Override func(p *SecurityPolicy)
+}
+
+type SecurityOpts struct {
+	// Policy is the default policy.
+	Policy SecurityPolicy
+	// Routes are the route group overrides. The longest matching prefix wins.
+	Routes []RouteSecurity
+}
+
+type securityRoute struct {
+	prefix  string
+	handler fiber.Handler
+}
+
+// Security applies the CORS and security headers policy matching the request path. It returns an
+// error when the default policy or a route override is invalid, see CORSPolicy.Validate.
+func Security(opts SecurityOpts) (fiber.Handler, error) {
+	if err := opts.Policy.CORS.Validate(); err != nil {
+		return nil, err
+	}
+	defaultHandler := newSecurityHandler(opts.Policy)
+
+	routes := make([]securityRoute, 0, len(opts.Routes))
+	for _, route := range opts.Routes {
+		policy := opts.Policy.clone()
+		if route.Override != nil {
+			route.Override(&policy)
+		}
+		if err := policy.CORS.Validate(); err != nil {
+			return nil, fmt.Errorf("%w, in the policy of %s", err, route.Prefix)
+		}
+		routes = append(routes, securityRoute{prefix: strings.TrimSuffix(route.Prefix, "/"), handler: newSecurityHandler(policy)})
+	}
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].prefix) > len(routes[j].prefix) })
+
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		for _, route := range routes {
+			if path == route.prefix || strings.HasPrefix(path, route.prefix+"/") {
+				return route.handler(c)
+			}
+		}
+		return defaultHandler(c)
+	}, nil
+}
+
+// Validate returns an error for the origins rejected by the CORS middleware of Fiber: "*" with
+// other origins or with credentials, and origins other than a scheme and a host, such as
+// https://app.example.com or https://*.example.com.
+func (p CORSPolicy) Validate() error {
+	for _, origin := range p.AllowOrigins {
+		if origin != "*" {
+			if !utils.IsValidOrigin(origin) {
+				return fmt.Errorf("middleware: invalid CORS origin %q", origin)
+			}
+			continue
+		}
+		if len(p.AllowOrigins) > 1 {
+			return errors.New("middleware: the CORS origin * cannot be listed with other origins")
+		}
+		if p.AllowCredentials {
+			return errors.New("middleware: CORS credentials cannot be allowed for the origin *")
+		}
+	}
+	return nil
+}
+
+func newSecurityHandler(p SecurityPolicy) fiber.Handler {
+	headers := p.Headers.values()
+
+	corsConfig := cors.Config{
+		AllowMethods:     strings.Join(p.CORS.AllowMethods, ","),
+		AllowHeaders:     strings.Join(p.CORS.AllowHeaders, ","),
+		AllowCredentials: p.CORS.AllowCredentials,
+		ExposeHeaders:    strings.Join(p.CORS.ExposeHeaders, ","),
+		MaxAge:           int(p.CORS.MaxAge.Seconds()),
+	}
+	if len(p.CORS.AllowOrigins) == 0 {
+		// cors allows every origin when none is configured
+		corsConfig.AllowOriginsFunc = func(string) bool { return false }
+	} else {
+		corsConfig.AllowOrigins = strings.Join(p.CORS.AllowOrigins, ",")
+	}
+	corsHandler := cors.New(corsConfig)
+
+	return func(c *fiber.Ctx) error {
+		for _, header := range headers {
+			c.Set(header[0], header[1])
+		}
+		return corsHandler(c)
+	}
+}
+
+// values returns the header name and value pairs to send.
+func (h SecurityHeaders) values() [][2]string {
+	values := [][2]string{}
+	if h.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.Itoa(int(h.HSTSMaxAge.Seconds()))
+		if h.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if h.HSTSPreload {
+			hsts += "; preload"
+		}
+		values = append(values, [2]string{fiber.HeaderStrictTransportSecurity, hsts})
+	}
+	if h.ContentSecurityPolicy != "" {
+		values = append(values, [2]string{fiber.HeaderContentSecurityPolicy, h.ContentSecurityPolicy})
+	}
+	if h.ContentTypeNosniff {
+		values = append(values, [2]string{fiber.HeaderXContentTypeOptions, "nosniff"})
+	}
+	if h.ReferrerPolicy != "" {
+		values = append(values, [2]string{fiber.HeaderReferrerPolicy, h.ReferrerPolicy})
+	}
+	if h.FrameOptions != "" {
+		values = append(values, [2]string{fiber.HeaderXFrameOptions, h.FrameOptions})
+	}
+	return values
+}
+
+// clone returns a copy of the policy that does not share slices with p.
+func (p SecurityPolicy) clone() SecurityPolicy {
+	p.CORS.AllowOrigins = append([]string(nil), p.CORS.AllowOrigins...)
+	p.CORS.AllowMethods = append([]string(nil), p.CORS.AllowMethods...)
+	p.CORS.AllowHeaders = append([]string(nil), p.CORS.AllowHeaders...)
+	p.CORS.ExposeHeaders = append([]string(nil), p.CORS.ExposeHeaders...)
+	return p
+}
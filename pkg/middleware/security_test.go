@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecurityApp(t *testing.T) *fiber.App {
+	t.Helper()
+
+	app := fiber.New()
+	security, err := middleware.Security(middleware.SecurityOpts{
+		Policy: middleware.SecurityPolicy{
+			CORS: middleware.CORSPolicy{
+				AllowOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+				AllowCredentials: true,
+				ExposeHeaders:    []string{"ETag", "X-Cache"},
+				MaxAge:           time.Hour,
+			},
+			Headers: middleware.SecurityHeaders{
+				HSTSMaxAge:            365 * 24 * time.Hour,
+				HSTSIncludeSubdomains: true,
+				ContentSecurityPolicy: "default-src 'none'",
+				ContentTypeNosniff:    true,
+				ReferrerPolicy:        "no-referrer",
+				FrameOptions:          "DENY",
+			},
+		},
+		Routes: []middleware.RouteSecurity{
+			{Prefix: "/internal", Override: func(p *middleware.SecurityPolicy) {
+				p.CORS.AllowOrigins = nil
+				p.Headers.FrameOptions = "SAMEORIGIN"
+			}},
+		},
+	})
+	require.NoError(t, err)
+	app.Use(security)
+	handler := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusOK) }
+	app.Get("/books", handler)
+	app.Get("/internal/stats", handler)
+	app.Get("/internalx", handler)
+	return app
+}
+
+func TestSecurity_Headers(t *testing.T) {
+	resp, _ := newSecurityApp(t).Test(httptest.NewRequest(http.MethodGet, "/books", nil))
+
+	assert.Equal(t, "max-age=31536000; includeSubDomains", resp.Header.Get(fiber.HeaderStrictTransportSecurity))
+	assert.Equal(t, "default-src 'none'", resp.Header.Get(fiber.HeaderContentSecurityPolicy))
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+	assert.Equal(t, "no-referrer", resp.Header.Get(fiber.HeaderReferrerPolicy))
+	assert.Equal(t, "DENY", resp.Header.Get(fiber.HeaderXFrameOptions))
+}
+
+func TestSecurity_CORS(t *testing.T) {
+	app := newSecurityApp(t)
+	tests := []struct {
+		name    string
+		path    string
+		origin  string
+		allowed bool
+	}{
+		{"exact origin", "/books", "https://app.example.com", true},
+		{"wildcard subdomain", "/books", "https://admin.example.org", true},
+		{"unknown origin", "/books", "https://evil.example.com", false},
+		{"route group override", "/internal/stats", "https://app.example.com", false},
+		{"prefix is matched on segments", "/internalx", "https://app.example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(fiber.HeaderOrigin, tt.origin)
+			resp, _ := app.Test(req)
+
+			if !tt.allowed {
+				assert.Empty(t, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+				return
+			}
+			assert.Equal(t, tt.origin, resp.Header.Get(fiber.HeaderAccessControlAllowOrigin))
+			assert.Equal(t, "true", resp.Header.Get(fiber.HeaderAccessControlAllowCredentials))
+			assert.Equal(t, "ETag,X-Cache", resp.Header.Get(fiber.HeaderAccessControlExposeHeaders))
+		})
+	}
+
+	// preflight
+	req := httptest.NewRequest(http.MethodOptions, "/books", nil)
+	req.Header.Set(fiber.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(fiber.HeaderAccessControlRequestMethod, http.MethodPost)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "3600", resp.Header.Get(fiber.HeaderAccessControlMaxAge))
+
+	// overrides only change their route group
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/internal/stats", nil))
+	assert.Equal(t, "SAMEORIGIN", resp.Header.Get(fiber.HeaderXFrameOptions))
+	assert.Equal(t, "nosniff", resp.Header.Get(fiber.HeaderXContentTypeOptions))
+}
+
+func TestSecurity_InvalidCORS(t *testing.T) {
+	for name, policy := range map[string]middleware.CORSPolicy{
+		"credentials with any origin": {AllowOrigins: []string{"*"}, AllowCredentials: true},
+		"any origin with others":      {AllowOrigins: []string{"*", "https://app.example.com"}},
+		"path":                        {AllowOrigins: []string{"https://app.example.com/books"}},
+		"no scheme":                   {AllowOrigins: []string{"app.example.com"}},
+		"wildcard host":               {AllowOrigins: []string{"https://*"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := middleware.Security(middleware.SecurityOpts{Policy: middleware.SecurityPolicy{CORS: policy}})
+				assert.Error(t, err)
+			})
+		})
+	}
+
+	// nor in a route override
+	_, err := middleware.Security(middleware.SecurityOpts{Routes: []middleware.RouteSecurity{
+		{Prefix: "/public", Override: func(p *middleware.SecurityPolicy) {
+			p.CORS.AllowOrigins = []string{"*"}
+			p.CORS.AllowCredentials = true
+		}},
+	}})
+	assert.EqualError(t, err, "middleware: CORS credentials cannot be allowed for the origin *, in the policy of /public")
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	DefaultRequestTimeout = 30 * time.Second
+
+	// localTimeoutContextKey keeps the context carrying the deadline in effect, a route override
+	// replaces the one of Timeout.
+	localTimeoutContextKey = "timeout:context"
+	localTimeoutStatusKey  = "timeout:status"
+)
+
+// TimeoutOpts represents the options for the timeout middleware.
+type TimeoutOpts struct {
+	// Timeout is the deadline attached to every request. Default is 30 seconds.
+	Timeout time.Duration
+	// Status is the HTTP status sent when the deadline is exceeded, 504 or 503. Default is 504.
+	Status int
+}
+
+// Timeout attaches a deadline to the request user context, which is passed down to the
+// usecases and repositories. A request that exceeds it is answered with the timeout status, unless
+// the handler already wrote a successful or client error response.
+func Timeout(opts TimeoutOpts) fiber.Handler {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	status := opts.Status
+	if status == 0 {
+		status = http.StatusGatewayTimeout
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Locals(localTimeoutStatusKey, status)
+
+		parent := c.UserContext()
+		return runWithTimeout(c, parent, parent, timeout, status)
+	}
+}
+
+// RouteTimeout overrides the deadline set by Timeout for a single route or group. The deadline
+// replaces the default one, so it can extend it, and the values attached to the context before the
+// override, such as the authenticated user, are kept.
+func RouteTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status, ok := c.Locals(localTimeoutStatusKey).(int)
+		if !ok {
+			status = http.StatusGatewayTimeout
+		}
+
+		current := c.UserContext()
+		return runWithTimeout(c, context.WithoutCancel(current), current, timeout, status)
+	}
+}
+
+// runWithTimeout runs the next handlers with a deadline built on base, and restores the context
+// active before it on the way out.
+func runWithTimeout(c *fiber.Ctx, base context.Context, restore context.Context, timeout time.Duration, status int) error {
+	ctx, cancel := context.WithTimeout(base, timeout)
+	defer cancel()
+	// the error handler and the outer middlewares run after the deadline is cancelled
+	defer c.SetUserContext(restore)
+
+	c.SetUserContext(ctx)
+	c.Locals(localTimeoutContextKey, ctx)
+	err := c.Next()
+
+	// the usecase reports a failed query, replace it with the timeout response. A route
+	// override may have replaced the deadline, so check the one the handler actually used.
+	if deadline, ok := c.Locals(localTimeoutContextKey).(context.Context); ok &&
+		errors.Is(deadline.Err(), context.DeadlineExceeded) && !handlerResponded(c, err) {
+		return ResponseTimeout(c, status)
+	}
+
+	return err
+}
+
+// handlerResponded reports whether the handler answered the request although the deadline passed,
+// e.g. a 201 sent just after a commit: the client must not retry a write that happened.
+func handlerResponded(c *fiber.Ctx, err error) bool {
+	if err != nil {
+		return false
+	}
+	code := c.Response().StatusCode()
+	if code >= http.StatusInternalServerError {
+		return false
+	}
+	// nothing was written
+	return code != http.StatusOK || len(c.Response().Body()) > 0
+}
+
+// ResponseTimeout writes the response of a request that exceeded its deadline.
+func ResponseTimeout(c *fiber.Ctx, status int) error {
+	c.Response().ResetBody()
+	return wrapper.Send(c, wrapper.ResponseFailed(status, contract.StatusCodeRequestTimeout, contract.ErrorRequestTimeout, nil))
+}
@@ -0,0 +1,159 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/authentication"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// slowQuery simulates a repository call that honors the context deadline.
+func slowQuery(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newTimeoutApp(opts middleware.TimeoutOpts) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(middleware.Timeout(opts))
+	return app
+}
+
+func TestTimeout_DeadlineExceeded(t *testing.T) {
+	app := newTimeoutApp(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		if err := slowQuery(c.UserContext(), time.Second); err != nil {
+			return c.Status(http.StatusInternalServerError).SendString("query failed")
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), -1)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+	assert.Contains(t, string(body), string(contract.StatusCodeRequestTimeout))
+}
+
+func TestTimeout_SucceededAfterDeadline(t *testing.T) {
+	app := newTimeoutApp(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond})
+	app.Post("/books", func(c *fiber.Ctx) error {
+		// the commit returns just after the deadline
+		time.Sleep(40 * time.Millisecond)
+		return c.Status(http.StatusCreated).SendString(`{"id":"book-1"}`)
+	})
+	app.Get("/silent", func(c *fiber.Ctx) error {
+		time.Sleep(40 * time.Millisecond)
+		return nil
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/books", nil), -1)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, `{"id":"book-1"}`, string(body))
+
+	// a handler that wrote nothing gets the timeout response
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/silent", nil), -1)
+	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
+}
+
+func TestTimeout_ReturnedErrorAndServiceUnavailable(t *testing.T) {
+	app := newTimeoutApp(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond, Status: http.StatusServiceUnavailable})
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		return slowQuery(c.UserContext(), time.Second)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/slow", nil), -1)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestTimeout_RouteOverride(t *testing.T) {
+	app := newTimeoutApp(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond})
+	app.Get("/report", middleware.RouteTimeout(time.Second), func(c *fiber.Ctx) error {
+		deadline, ok := c.UserContext().Deadline()
+		assert.True(t, ok)
+		assert.Greater(t, time.Until(deadline), 500*time.Millisecond)
+
+		if err := slowQuery(c.UserContext(), 50*time.Millisecond); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, "/report", nil), -1)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestTimeout_RestoresParentContext(t *testing.T) {
+	var handlerErr error
+	app := fiber.New(fiber.Config{ErrorHandler: func(c *fiber.Ctx, err error) error {
+		handlerErr = c.UserContext().Err()
+		return c.SendStatus(http.StatusBadRequest)
+	}})
+	app.Use(middleware.Timeout(middleware.TimeoutOpts{Timeout: time.Second}))
+	app.Post("/books", func(c *fiber.Ctx) error {
+		return fiber.ErrBadRequest
+	})
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodPost, "/books", nil), -1)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.NoError(t, handlerErr)
+}
+
+func TestTimeout_RouteOverrideKeepsUser(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	auth := middleware.NewAuthMiddleware(middleware.SetJwtAuth(&authentication.JWTConfig{
+		PrivateKey:     string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})),
+		PublicKey:      string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+		ExpirationTime: 5,
+	}), middleware.SetBasicAuth(&authentication.BasicAuthTConfig{}))
+	token, err := auth.Jwt.GenerateToken(authentication.JWTClaims{"userId": "user-1"})
+	require.NoError(t, err)
+
+	app := newTimeoutApp(middleware.TimeoutOpts{Timeout: 20 * time.Millisecond})
+	var restored bool
+	app.Get("/report", auth.JwtAuth(), func(c *fiber.Ctx) error {
+		err := c.Next()
+		// the context of the authenticated request is active again after the override
+		_, restored = middleware.UserFromContext(c.UserContext())
+		return err
+	}, middleware.RouteTimeout(time.Second), func(c *fiber.Ctx) error {
+		user, ok := middleware.UserFromContext(c.UserContext())
+		assert.True(t, ok)
+		if ok {
+			assert.Equal(t, "user-1", user.UserID)
+		}
+
+		// the override extends the default deadline
+		if err := slowQuery(c.UserContext(), 50*time.Millisecond); err != nil {
+			return err
+		}
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+	resp, _ := app.Test(req, -1)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, restored)
+}
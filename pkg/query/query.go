@@ -0,0 +1,252 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	filterRegex = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+)
+
+// Parse returns the query of the filter, sort and q parameters of a query string, such as
+// filter[author][eq]=Herbert&filter[created_at][gte]=2024-01-01&sort=-created_at,title&q=dune.
+// The other parameters are ignored. A filter without operator, filter[author]=Herbert, compares
+// with eq. The rejected parameters are returned as Errors.
+func (s *Schema) Parse(values map[string][]string) (Query, error) {
+	q := Query{Filters: []Filter{}, Sorts: []Sort{}}
+	errs := Errors{}
+
+	// the values are a map, parse them in order for stable filters and errors
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range values[key] {
+			switch {
+			case key == ParamSort:
+				sorts, sortErrs := s.parseSort(value)
+				q.Sorts = append(q.Sorts, sorts...)
+				errs = append(errs, sortErrs...)
+			case key == ParamSearch:
+				if len(s.Search) == 0 {
+					errs = append(errs, Error{Param: key, Value: value, Message: MessageSearchNotAllowed, Params: []string{key}})
+					continue
+				}
+				q.Search = strings.TrimSpace(value)
+				q.searchColumns = s.Search
+			case key == ParamFilter || strings.HasPrefix(key, ParamFilter+"["):
+				filter, err := s.parseFilter(key, value)
+				if err != nil {
+					errs = append(errs, *err)
+					continue
+				}
+				q.Filters = append(q.Filters, filter)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return Query{}, errs
+	}
+
+	if len(q.Sorts) == 0 {
+		for _, defaultSort := range s.DefaultSort {
+			defaultSort.column = s.Fields[defaultSort.Field].Column
+			q.Sorts = append(q.Sorts, defaultSort)
+		}
+	}
+	return q, nil
+}
+
+// Validate returns an error when a field has no column, or a default sort is not a sortable field
+// of the schema. binding.Handle validates the schemas of the requests when the routes are
+// registered.
+func (s *Schema) Validate() error {
+	for name, field := range s.Fields {
+		if field.Column == "" {
+			return fmt.Errorf("query: field %q has no column", name)
+		}
+	}
+	for _, defaultSort := range s.DefaultSort {
+		if field, ok := s.Fields[defaultSort.Field]; !ok || !field.Sortable {
+			return fmt.Errorf("query: default sort %q is not a sortable field", defaultSort.Field)
+		}
+	}
+	return nil
+}
+
+// parseFilter returns the filter of a filter[field][operator] parameter.
+func (s *Schema) parseFilter(key string, value string) (Filter, *Error) {
+	match := filterRegex.FindStringSubmatch(key)
+	if match == nil {
+		return Filter{}, &Error{Param: key, Value: value, Message: MessageInvalidFilter, Params: []string{key}}
+	}
+	name, op := match[1], Operator(match[2])
+	if op == "" {
+		op = OpEq
+	}
+
+	field, ok := s.Fields[name]
+	if !ok || len(field.Operators) == 0 {
+		param := ParamFilter + "[" + name + "]"
+		return Filter{}, &Error{Param: key, Value: value, Message: MessageUnknownField, Params: []string{param}}
+	}
+	if !hasOperator(field.Operators, op) {
+		operators := make([]string, len(field.Operators))
+		for i, operator := range field.Operators {
+			operators[i] = string(operator)
+		}
+		return Filter{}, &Error{Param: key, Value: value, Message: MessageInvalidOperator, Params: []string{key, strings.Join(operators, ", ")}}
+	}
+
+	fieldType := field.Type
+	if fieldType == "" {
+		fieldType = TypeString
+	}
+	filter := Filter{Field: name, Operator: op, column: field.Column}
+	if op == OpContains {
+		// the text is matched whatever the type of the field
+		filter.Value = value
+		return filter, nil
+	}
+	if op != OpIn {
+		converted, ok := convert(fieldType, value)
+		if !ok {
+			return Filter{}, &Error{Param: key, Value: value, Expected: string(fieldType), Message: MessageInvalidType, Params: []string{key, string(fieldType)}}
+		}
+		filter.Value = converted
+		return filter, nil
+	}
+
+	items := strings.Split(value, ",")
+	if len(items) > MaxInValues {
+		return Filter{}, &Error{Param: key, Value: value, Message: MessageTooManyValues, Params: []string{key, strconv.Itoa(MaxInValues)}}
+	}
+	converted := make([]interface{}, len(items))
+	for i, item := range items {
+		if converted[i], ok = convert(fieldType, item); !ok {
+			return Filter{}, &Error{Param: key, Value: item, Expected: string(fieldType), Message: MessageInvalidType, Params: []string{key, string(fieldType)}}
+		}
+	}
+	filter.Value = converted
+	return filter, nil
+}
+
+// parseSort returns the sorts of a comma separated list of fields, descending when prefixed by -.
+func (s *Schema) parseSort(value string) ([]Sort, Errors) {
+	sorts := []Sort{}
+	errs := Errors{}
+	for _, item := range strings.Split(value, ",") {
+		name, desc := strings.CutPrefix(strings.TrimSpace(item), "-")
+		field, ok := s.Fields[name]
+		if !ok || !field.Sortable {
+			errs = append(errs, Error{Param: ParamSort, Value: item, Message: MessageUnknownSort, Params: []string{name}})
+			continue
+		}
+		sorts = append(sorts, Sort{Field: name, Desc: desc, column: field.Column})
+	}
+	return sorts, errs
+}
+
+// convert returns the value of a query string value of a field type.
+func convert(fieldType FieldType, value string) (interface{}, bool) {
+	switch fieldType {
+	case TypeInteger:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return n, err == nil
+	case TypeNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	case TypeBoolean:
+		b, err := strconv.ParseBool(value)
+		return b, err == nil
+	case TypeDateTime:
+		for _, layout := range []string{time.RFC3339, time.DateOnly} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t, true
+			}
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func hasOperator(operators []Operator, op Operator) bool {
+	for _, operator := range operators {
+		if operator == op {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSort reports whether the query sorts by the field.
+func (q Query) HasSort(field string) bool {
+	for _, order := range q.Sorts {
+		if order.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Scope adds the filters, the search and the sorts of the query to a GORM query, e.g.
+// tx.Scopes(q.Scope). The columns come from the schema and the values are bound as parameters.
+func (q Query) Scope(tx *gorm.DB) *gorm.DB {
+	for _, filter := range q.Filters {
+		tx = tx.Where(filter.expression())
+	}
+
+	if q.Search != "" && len(q.searchColumns) > 0 {
+		pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+		matches := make([]clause.Expression, len(q.searchColumns))
+		for i, column := range q.searchColumns {
+			matches[i] = ilike(column, pattern)
+		}
+		tx = tx.Where(clause.Or(matches...))
+	}
+
+	for _, order := range q.Sorts {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: order.column}, Desc: order.Desc})
+	}
+	return tx
+}
+
+// expression returns the condition of the filter.
+func (f Filter) expression() clause.Expression {
+	column := clause.Column{Name: f.column}
+	switch f.Operator {
+	case OpNe:
+		return clause.Neq{Column: column, Value: f.Value}
+	case OpGt:
+		return clause.Gt{Column: column, Value: f.Value}
+	case OpGte:
+		return clause.Gte{Column: column, Value: f.Value}
+	case OpLt:
+		return clause.Lt{Column: column, Value: f.Value}
+	case OpLte:
+		return clause.Lte{Column: column, Value: f.Value}
+	case OpIn:
+		return clause.IN{Column: column, Values: f.Value.([]interface{})}
+	case OpContains:
+		return ilike(f.column, "%"+likeEscaper.Replace(f.Value.(string))+"%")
+	}
+	return clause.Eq{Column: column, Value: f.Value}
+}
+
+// ilike matches a column case-insensitively with a LIKE pattern.
+func ilike(column string, pattern string) clause.Expression {
+	return clause.Expr{SQL: "? ILIKE ?", Vars: []interface{}{clause.Column{Name: column}, pattern}}
+}
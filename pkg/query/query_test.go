@@ -0,0 +1,124 @@
+package query_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type book struct {
+	ID    string
+	Title string
+}
+
+var bookSchema = &query.Schema{
+	Fields: map[string]query.Field{
+		"title":      {Column: "title", Operators: []query.Operator{query.OpEq, query.OpContains, query.OpIn}, Sortable: true},
+		"pages":      {Column: "page_count", Type: query.TypeInteger, Operators: []query.Operator{query.OpGt, query.OpLte}},
+		"created_at": {Column: "created_at", Type: query.TypeDateTime, Operators: []query.Operator{query.OpGte, query.OpLt}, Sortable: true},
+		"published":  {Column: "published", Type: query.TypeBoolean, Operators: []query.Operator{query.OpEq}},
+		"id":         {Column: "id", Sortable: true},
+	},
+	Search:      []string{"title", "author"},
+	DefaultSort: []query.Sort{{Field: "created_at", Desc: true}, {Field: "id"}},
+}
+
+// toSQL returns the SQL of finding the books of the query.
+func toSQL(t *testing.T, q query.Query) string {
+	t.Helper()
+
+	db := mustDryRun(t)
+	stmt := db.Table("books").Scopes(q.Scope).Find(&[]book{}).Statement
+	return db.Dialector.Explain(stmt.SQL.String(), stmt.Vars...)
+}
+
+func TestParse(t *testing.T) {
+	values, _ := url.ParseQuery("filter[title][in]=Dune,Emma&filter[pages][gt]=100&filter[created_at][gte]=2024-01-01&filter[published]=true&sort=-created_at,title&q=100%25_sure&page=2")
+
+	q, err := bookSchema.Parse(values)
+	require.NoError(t, err)
+	assert.Equal(t, []query.Filter{
+		{Field: "created_at", Operator: query.OpGte, Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Field: "pages", Operator: query.OpGt, Value: int64(100)},
+		{Field: "published", Operator: query.OpEq, Value: true},
+		{Field: "title", Operator: query.OpIn, Value: []interface{}{"Dune", "Emma"}},
+	}, stripColumns(q.Filters))
+	assert.Equal(t, "100%_sure", q.Search)
+
+	assert.Equal(t,
+		`SELECT * FROM "books" WHERE "created_at" >= '2024-01-01 00:00:00' AND "page_count" > 100 AND "published" = true AND "title" IN ('Dune','Emma') AND ("title" ILIKE '%100\%\_sure%' OR "author" ILIKE '%100\%\_sure%') ORDER BY "created_at" DESC,"title"`,
+		toSQL(t, q))
+}
+
+func TestParse_Defaults(t *testing.T) {
+	q, err := bookSchema.Parse(url.Values{"filter[title][contains]": {"dune"}})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "books" WHERE "title" ILIKE '%dune%' ORDER BY "created_at" DESC,"id"`, toSQL(t, q))
+
+	// values are never written in the query
+	q, err = bookSchema.Parse(url.Values{"filter[title]": {"'; DROP TABLE books; --"}})
+	require.NoError(t, err)
+	stmt := q.Scope(mustDryRun(t).Table("books")).Find(&[]book{}).Statement
+	assert.Equal(t, `SELECT * FROM "books" WHERE "title" = $1 ORDER BY "created_at" DESC,"id"`, stmt.SQL.String())
+}
+
+func TestParse_Errors(t *testing.T) {
+	values, _ := url.ParseQuery("filter[secret][eq]=x&filter[pages][eq]=1&filter[pages][gt]=many&filter[title][in]=&filter=x&sort=title,-password&q=")
+
+	_, err := (&query.Schema{Fields: bookSchema.Fields}).Parse(values)
+	var errs query.Errors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, query.Errors{
+		{Param: "filter", Value: "x", Message: query.MessageInvalidFilter, Params: []string{"filter"}},
+		{Param: "filter[pages][eq]", Value: "1", Message: query.MessageInvalidOperator, Params: []string{"filter[pages][eq]", "gt, lte"}},
+		{Param: "filter[pages][gt]", Value: "many", Expected: "integer", Message: query.MessageInvalidType, Params: []string{"filter[pages][gt]", "integer"}},
+		{Param: "filter[secret][eq]", Value: "x", Message: query.MessageUnknownField, Params: []string{"filter[secret]"}},
+		{Param: "q", Value: "", Message: query.MessageSearchNotAllowed, Params: []string{"q"}},
+		{Param: "sort", Value: "-password", Message: query.MessageUnknownSort, Params: []string{"password"}},
+	}, errs)
+	assert.Contains(t, err.Error(), "filter[pages][eq] must use one of the operators gt, lte")
+}
+
+// mustDryRun returns a database building the queries without running them.
+func mustDryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db
+}
+
+// stripColumns returns the filters as seen by the callers, without their unexported column.
+func stripColumns(filters []query.Filter) []query.Filter {
+	stripped := make([]query.Filter, len(filters))
+	for i, filter := range filters {
+		stripped[i] = query.Filter{Field: filter.Field, Operator: filter.Operator, Value: filter.Value}
+	}
+	return stripped
+}
+
+func TestSchema_Validate(t *testing.T) {
+	require.NoError(t, bookSchema.Validate())
+
+	unknown := &query.Schema{Fields: bookSchema.Fields, DefaultSort: []query.Sort{{Field: "author"}}}
+	assert.EqualError(t, unknown.Validate(), `query: default sort "author" is not a sortable field`)
+
+	unsortable := &query.Schema{Fields: bookSchema.Fields, DefaultSort: []query.Sort{{Field: "pages"}}}
+	assert.EqualError(t, unsortable.Validate(), `query: default sort "pages" is not a sortable field`)
+
+	noColumn := &query.Schema{Fields: map[string]query.Field{"title": {Sortable: true}}}
+	assert.EqualError(t, noColumn.Validate(), `query: field "title" has no column`)
+}
+
+func TestQuery_HasSort(t *testing.T) {
+	q, err := bookSchema.Parse(url.Values{"sort": {"title,-id"}})
+	require.NoError(t, err)
+	assert.True(t, q.HasSort("id"))
+	assert.False(t, q.HasSort("created_at"))
+}
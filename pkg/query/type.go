@@ -0,0 +1,144 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+)
+
+// Query string parameters of the DSL.
+const (
+	ParamFilter = "filter"
+	ParamSort   = "sort"
+	ParamSearch = "q"
+)
+
+// Operators of the filters, e.g. filter[created_at][gte]=2024-01-01.
+const (
+	OpEq       = Operator("eq")
+	OpNe       = Operator("ne")
+	OpGt       = Operator("gt")
+	OpGte      = Operator("gte")
+	OpLt       = Operator("lt")
+	OpLte      = Operator("lte")
+	OpIn       = Operator("in")
+	OpContains = Operator("contains")
+)
+
+// Types of the filtered values, named like in the binding errors.
+const (
+	TypeString   = FieldType("string")
+	TypeInteger  = FieldType("integer")
+	TypeNumber   = FieldType("number")
+	TypeBoolean  = FieldType("boolean")
+	TypeDateTime = FieldType("date-time")
+)
+
+// MaxInValues is the maximum number of comma separated values of the in operator.
+const MaxInValues = 100
+
+// Messages of the query errors, translated like the binding errors.
+const (
+	MessageInvalidFilter    = "{0} must be written as filter[field][operator]"
+	MessageUnknownField     = "{0} is not a filterable field"
+	MessageInvalidOperator  = "{0} must use one of the operators {1}"
+	MessageInvalidType      = "{0} must be of type {1}"
+	MessageTooManyValues    = "{0} must have at most {1} values"
+	MessageUnknownSort      = "{0} is not a sortable field"
+	MessageSearchNotAllowed = "{0} is not supported"
+)
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		MessageInvalidFilter:    "{0} harus ditulis sebagai filter[field][operator]",
+		MessageUnknownField:     "{0} bukan field yang dapat difilter",
+		MessageInvalidOperator:  "{0} harus menggunakan salah satu operator {1}",
+		MessageInvalidType:      "{0} harus bertipe {1}",
+		MessageTooManyValues:    "{0} harus memiliki paling banyak {1} nilai",
+		MessageUnknownSort:      "{0} bukan field yang dapat diurutkan",
+		MessageSearchNotAllowed: "{0} tidak didukung",
+	})
+}
+
+type (
+	// Operator compares a field with the value of a filter.
+	Operator string
+
+	// FieldType is the type of the values of a field, converted from the query string.
+	FieldType string
+
+	// Field is a field of a resource exposed to the DSL, by its name in the query string.
+	Field struct {
+		// Column is the database column of the field.
+		Column string
+		// Type is the type of the filtered values, TypeString by default.
+		Type FieldType
+		// Operators are the filter operators allowed on the field, none to disable the filters.
+		Operators []Operator
+		// Sortable allows sorting by the field.
+		Sortable bool
+	}
+
+	// Schema is the whitelist of the fields and operators of a resource. Anything else in the query
+	// string is rejected.
+	Schema struct {
+		// Fields are the fields by name in the query string, e.g. created_at.
+		Fields map[string]Field
+		// Search are the columns matched case-insensitively by the q parameter, none to disable it.
+		Search []string
+		// DefaultSort is the sort used when the query string has none.
+		DefaultSort []Sort
+	}
+
+	// Filter compares a field with a value, converted to the type of the field. The value of the in
+	// operator is a slice.
+	Filter struct {
+		Field    string
+		Operator Operator
+		Value    interface{}
+
+		column string
+	}
+
+	// Sort orders by a field, ascending unless Desc.
+	Sort struct {
+		Field string
+		Desc  bool
+
+		column string
+	}
+
+	// Query is a parsed query string, compiled into GORM clauses by Scope.
+	Query struct {
+		Filters []Filter
+		Sorts   []Sort
+		// Search is the text searched in the Search columns of the schema.
+		Search string
+
+		searchColumns []string
+	}
+
+	// Error describes a query string parameter rejected by the schema.
+	Error struct {
+		// Param is the query string parameter, e.g. filter[author][gt].
+		Param string
+		// Value is the rejected value.
+		Value string
+		// Expected is the expected type of the value, if any.
+		Expected string
+		// Message is the English message template, with its Params, translated with i18n.Translate.
+		Message string
+		Params  []string
+	}
+
+	// Errors is returned by Parse for the rejected parameters.
+	Errors []Error
+)
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, i18n.Translate(i18n.DefaultLocale, err.Message, err.Params...))
+	}
+	return strings.Join(messages, "; ")
+}
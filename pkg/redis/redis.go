@@ -2,12 +2,35 @@ package redis
 
 import (
 	"context"
+	"time"
 
 	"github.com/Alwanly/go-codebase/pkg/logger"
 	"github.com/go-redis/redis/v9"
 	"go.uber.org/zap"
 )
 
+var (
+	// compareAndSetScript sets KEYS[1] to ARGV[2], expiring in ARGV[3] milliseconds unless zero,
+	// when it holds ARGV[1].
+	compareAndSetScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1`)
+
+	// compareAndDeleteScript deletes KEYS[1] when it holds ARGV[1].
+	compareAndDeleteScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+return redis.call("DEL", KEYS[1])`)
+)
+
 func NewRedis(opts *Opts) (*Service, error) {
 	l := logger.WithID(opts.Logger, ContextName, "NewRedis")
 
@@ -19,6 +42,9 @@ func NewRedis(opts *Opts) (*Service, error) {
 	// create redis client
 	opt, _ := redis.ParseURL(*opts.RedisURI)
 	cl := redis.NewClient(opt)
+	for _, hook := range opts.Hooks {
+		cl.AddHook(hook)
+	}
 
 	// setup cancellation
 	ctxTimeout, cancel := context.WithTimeout(context.Background(), PingTimeout)
@@ -55,6 +81,10 @@ func (db *Service) PingRedisWithError() (bool, error) {
 	return res.Err() == nil, res.Err()
 }
 
+func (db *Service) PoolStats() *redis.PoolStats {
+	return db.Redis.PoolStats()
+}
+
 func (db *Service) CloseRedis() error {
 	return db.Redis.Close()
 }
@@ -62,3 +92,46 @@ func (db *Service) CloseRedis() error {
 func (db *Service) GetTransaction() (redis.Pipeliner, error) {
 	return db.Redis.TxPipeline(), nil
 }
+
+func (db *Service) Get(ctx context.Context, key string) (string, error) {
+	return db.Redis.Get(ctx, key).Result()
+}
+
+func (db *Service) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return db.Redis.Set(ctx, key, value, ttl).Err()
+}
+
+func (db *Service) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return db.Redis.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (db *Service) CompareAndSet(ctx context.Context, key string, expected string, value interface{}, ttl time.Duration) (bool, error) {
+	return compareAndSetScript.Run(ctx, db.Redis, []string{key}, expected, value, ttl.Milliseconds()).Bool()
+}
+
+func (db *Service) CompareAndDelete(ctx context.Context, key string, expected string) (bool, error) {
+	return compareAndDeleteScript.Run(ctx, db.Redis, []string{key}, expected).Bool()
+}
+
+func (db *Service) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return db.Redis.Del(ctx, keys...).Err()
+}
+
+func (db *Service) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	return db.Redis.SAdd(ctx, key, members...).Err()
+}
+
+func (db *Service) SMembers(ctx context.Context, key string) ([]string, error) {
+	return db.Redis.SMembers(ctx, key).Result()
+}
+
+func (db *Service) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return db.Redis.Expire(ctx, key, ttl).Err()
+}
+
+func (db *Service) Incr(ctx context.Context, key string) (int64, error) {
+	return db.Redis.Incr(ctx, key).Result()
+}
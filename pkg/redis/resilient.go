@@ -0,0 +1,121 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+)
+
+// ResilientService decorates a Redis service with a guard: commands fail fast with a dependency
+// unavailable error while the breaker is open or the bulkhead is full.
+type ResilientService struct {
+	IRedisService
+	guard resilience.IGuard
+}
+
+// NewResilientRedis decorates service with guard. Transactions returned by GetTransaction are
+// executed by the caller and not guarded.
+func NewResilientRedis(service IRedisService, guard resilience.IGuard) *ResilientService {
+	return &ResilientService{
+		IRedisService: service,
+		guard:         guard,
+	}
+}
+
+// IsFailure reports whether a Redis error counts toward the breaker. Missing keys and cancelled
+// callers do not.
+func IsFailure(err error) bool {
+	return !errors.Is(err, ErrNil) && !errors.Is(err, context.Canceled)
+}
+
+// Guard returns the guard protecting the service.
+func (s *ResilientService) Guard() resilience.IGuard {
+	return s.guard
+}
+
+func (s *ResilientService) Get(ctx context.Context, key string) (value string, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		value, err = s.IRedisService.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (s *ResilientService) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return s.guard.Do(ctx, func(ctx context.Context) error {
+		return s.IRedisService.Set(ctx, key, value, ttl)
+	})
+}
+
+func (s *ResilientService) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (acquired bool, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		acquired, err = s.IRedisService.SetNX(ctx, key, value, ttl)
+		return err
+	})
+	return acquired, err
+}
+
+func (s *ResilientService) CompareAndSet(ctx context.Context, key string, expected string, value interface{}, ttl time.Duration) (stored bool, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		stored, err = s.IRedisService.CompareAndSet(ctx, key, expected, value, ttl)
+		return err
+	})
+	return stored, err
+}
+
+func (s *ResilientService) CompareAndDelete(ctx context.Context, key string, expected string) (deleted bool, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		deleted, err = s.IRedisService.CompareAndDelete(ctx, key, expected)
+		return err
+	})
+	return deleted, err
+}
+
+func (s *ResilientService) Del(ctx context.Context, keys ...string) error {
+	return s.guard.Do(ctx, func(ctx context.Context) error {
+		return s.IRedisService.Del(ctx, keys...)
+	})
+}
+
+func (s *ResilientService) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	return s.guard.Do(ctx, func(ctx context.Context) error {
+		return s.IRedisService.SAdd(ctx, key, members...)
+	})
+}
+
+func (s *ResilientService) SMembers(ctx context.Context, key string) (members []string, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		members, err = s.IRedisService.SMembers(ctx, key)
+		return err
+	})
+	return members, err
+}
+
+func (s *ResilientService) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	return s.guard.Do(ctx, func(ctx context.Context) error {
+		return s.IRedisService.Expire(ctx, key, ttl)
+	})
+}
+
+func (s *ResilientService) Incr(ctx context.Context, key string) (value int64, err error) {
+	err = s.guard.Do(ctx, func(ctx context.Context) error {
+		value, err = s.IRedisService.Incr(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (s *ResilientService) PingRedisWithError() (ok bool, err error) {
+	err = s.guard.Do(context.Background(), func(context.Context) error {
+		ok, err = s.IRedisService.PingRedisWithError()
+		return err
+	})
+	return ok, err
+}
+
+func (s *ResilientService) PingRedis() bool {
+	ok, _ := s.PingRedisWithError()
+	return ok
+}
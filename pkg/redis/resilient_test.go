@@ -0,0 +1,53 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/redis"
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/alicebob/miniredis/v2"
+	redisv9 "github.com/go-redis/redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResilientRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	guard := resilience.NewGuard(&resilience.Opts{
+		Name:      "redis",
+		Breaker:   circuitbreaker.Opts{FailureThreshold: 1, OpenTimeout: time.Hour},
+		IsFailure: redis.IsFailure,
+	})
+	r := redis.NewResilientRedis(&redis.Service{Redis: redisv9.NewClient(&redisv9.Options{
+		Addr:        mr.Addr(),
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})}, guard)
+	ctx := context.Background()
+
+	require.NoError(t, r.Set(ctx, "key", "value", 0))
+	value, err := r.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	// missing keys are not failures
+	_, err = r.Get(ctx, "missing")
+	assert.ErrorIs(t, err, redis.ErrNil)
+	assert.Equal(t, circuitbreaker.StateClosed, guard.State())
+
+	// a stalled redis opens the breaker, then calls fail fast
+	mr.Close()
+	_, err = r.Get(ctx, "key")
+	require.Error(t, err)
+	assert.False(t, apperror.IsKind(err, apperror.KindUnavailable))
+	assert.Equal(t, circuitbreaker.StateOpen, guard.State())
+
+	_, err = r.SetNX(ctx, "key", "value", time.Minute)
+	assert.True(t, apperror.IsKind(err, apperror.KindUnavailable))
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+	assert.False(t, r.PingRedis())
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"time"
 
 	"github.com/go-redis/redis/v9"
@@ -13,6 +14,9 @@ const (
 	PingTimeout = 10 * time.Second
 )
 
+// ErrNil is returned when the requested key does not exist.
+var ErrNil = redis.Nil
+
 // DBServiceOpts represents the options for configuring the database service.
 type Opts struct {
 	// Debug enables debug mode.
@@ -25,6 +29,9 @@ type Opts struct {
 
 	// Application Name (for tracing)
 	ApplicationName *string
+
+	// Hooks are registered on the client, for example to trace commands.
+	Hooks []redis.Hook
 }
 
 // DBService represents the database service.
@@ -37,6 +44,123 @@ type IRedisService interface {
 
 	// ---- Redis
 	GetTransaction() (redis.Pipeliner, error)
+
+	// Get returns the value stored at key.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//
+	// Returns:
+	//   - string: value, empty when the key does not exist
+	//   - error: ErrNil when the key does not exist
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set stores the value at key with the given expiration. Zero expiration means no expiration.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - value: value
+	//   - ttl: expiration
+	//
+	// Returns:
+	//   - error: error
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+
+	// SetNX stores the value at key only when the key does not exist yet.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - value: value
+	//   - ttl: expiration
+	//
+	// Returns:
+	//   - bool: true if the value was stored, false if the key already exists
+	//   - error: error
+	SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+
+	// CompareAndSet stores the value at key only when the key still holds the expected value.
+	// Zero expiration means no expiration.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - expected: the value the key must hold
+	//   - value: value
+	//   - ttl: expiration
+	//
+	// Returns:
+	//   - bool: true if the value was stored, false if the key holds another value or does not exist
+	//   - error: error
+	CompareAndSet(ctx context.Context, key string, expected string, value interface{}, ttl time.Duration) (bool, error)
+
+	// CompareAndDelete deletes key only when it still holds the expected value.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - expected: the value the key must hold
+	//
+	// Returns:
+	//   - bool: true if the key was deleted
+	//   - error: error
+	CompareAndDelete(ctx context.Context, key string, expected string) (bool, error)
+
+	// Del deletes the given keys.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - keys: keys
+	//
+	// Returns:
+	//   - error: error
+	Del(ctx context.Context, keys ...string) error
+
+	// SAdd adds the members to the set stored at key.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - members: members
+	//
+	// Returns:
+	//   - error: error
+	SAdd(ctx context.Context, key string, members ...interface{}) error
+
+	// SMembers returns all members of the set stored at key.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//
+	// Returns:
+	//   - []string: members
+	//   - error: error
+	SMembers(ctx context.Context, key string) ([]string, error)
+
+	// Expire sets the expiration of key.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//   - ttl: expiration
+	//
+	// Returns:
+	//   - error: error
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+
+	// Incr increments the integer stored at key, created with 0 when missing.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - key: key
+	//
+	// Returns:
+	//   - int64: the value after the increment
+	//   - error: error
+	Incr(ctx context.Context, key string) (int64, error)
 	// PingRedis pings the Redis database to check if it's available.
 	//
 	// Returns:
@@ -50,6 +174,12 @@ type IRedisService interface {
 	//   - error: error stack trace.
 	PingRedisWithError() (bool, error)
 
+	// PoolStats returns the connection pool statistics.
+	//
+	// Returns:
+	//   - *redis.PoolStats: connection pool statistics
+	PoolStats() *redis.PoolStats
+
 	// CloseRedis closes the Redis database connection.
 	CloseRedis() error
 }
@@ -0,0 +1,174 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func NewGuard(opts *Opts) *Guard {
+	g := &Guard{
+		logger:    opts.Logger,
+		name:      opts.Name,
+		maxWait:   opts.MaxWait,
+		isFailure: opts.IsFailure,
+	}
+	if g.logger == nil {
+		g.logger = zap.NewNop()
+	}
+	if g.maxWait <= 0 {
+		g.maxWait = defaultMaxWait
+	}
+	if g.isFailure == nil {
+		g.isFailure = func(err error) bool {
+			return !errors.Is(err, context.Canceled)
+		}
+	}
+	if opts.MaxConcurrent > 0 {
+		g.slots = make(chan struct{}, opts.MaxConcurrent)
+	}
+	if opts.Metrics != nil {
+		g.state = opts.Metrics.Gauge("dependency_circuit_state", "State of the dependency circuit breakers: 0 closed, 1 half open, 2 open.", "dependency")
+		g.rejected = opts.Metrics.Counter("dependency_rejected_calls_total", "Calls to a dependency rejected by its circuit breaker or bulkhead.", "dependency", "reason")
+		g.state.WithLabelValues(g.name).Set(stateValue(circuitbreaker.StateClosed))
+	}
+
+	breakerOpts := opts.Breaker
+	breakerOpts.Name = opts.Name
+	onStateChange := breakerOpts.OnStateChange
+	breakerOpts.OnStateChange = func(name string, from circuitbreaker.State, to circuitbreaker.State) {
+		g.onStateChange(from, to)
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+	g.breaker = circuitbreaker.NewCircuitBreaker(breakerOpts)
+	return g
+}
+
+// Name returns the name of the protected dependency.
+func (g *Guard) Name() string {
+	return g.name
+}
+
+// State returns the state of the circuit breaker.
+func (g *Guard) State() circuitbreaker.State {
+	return g.breaker.State()
+}
+
+// Acquire reserves a call. It fails fast with a dependency unavailable error when the breaker is
+// open or no slot frees up in time, otherwise done must be called with the error of the call.
+func (g *Guard) Acquire(ctx context.Context) (done func(err error), err error) {
+	// do not wait for a slot when the call would be rejected anyway
+	if g.breaker.State() == circuitbreaker.StateOpen {
+		g.reject("circuit_open")
+		return nil, g.unavailable(circuitbreaker.ErrOpen)
+	}
+
+	release, err := g.acquireSlot(ctx)
+	if err != nil {
+		if errors.Is(err, ErrBulkheadFull) {
+			g.reject("bulkhead_full")
+			return nil, g.unavailable(err)
+		}
+		return nil, err
+	}
+
+	record, err := g.breaker.Allow()
+	if err != nil {
+		release()
+		g.reject("circuit_open")
+		return nil, g.unavailable(err)
+	}
+
+	return func(err error) {
+		record(err == nil || !g.isFailure(err))
+		release()
+	}, nil
+}
+
+// Do runs fn when the guard allows it and records its outcome.
+func (g *Guard) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := g.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// IsUnavailable reports whether err was returned by a guard rejecting a call.
+func IsUnavailable(err error) bool {
+	return errors.Is(err, circuitbreaker.ErrOpen) || errors.Is(err, ErrBulkheadFull)
+}
+
+// acquireSlot waits up to maxWait for a free slot of the bulkhead.
+func (g *Guard) acquireSlot(ctx context.Context) (release func(), err error) {
+	if g.slots == nil {
+		return func() {}, nil
+	}
+
+	release = func() { <-g.slots }
+	select {
+	case g.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	timer := time.NewTimer(g.maxWait)
+	defer timer.Stop()
+
+	select {
+	case g.slots <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, ErrBulkheadFull
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// unavailable returns the typed dependency unavailable error wrapping the rejection cause.
+func (g *Guard) unavailable(cause error) error {
+	return apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable).
+		Wrap(fmt.Errorf("%s: %w", g.name, cause))
+}
+
+func (g *Guard) reject(reason string) {
+	if g.rejected != nil {
+		g.rejected.WithLabelValues(g.name, reason).Inc()
+	}
+}
+
+func (g *Guard) onStateChange(from circuitbreaker.State, to circuitbreaker.State) {
+	l := logger.WithID(g.logger, ContextName, "onStateChange")
+	l.Warn("Dependency circuit breaker changed state",
+		zap.String("dependency", g.name),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+	)
+
+	if g.state != nil {
+		g.state.WithLabelValues(g.name).Set(stateValue(to))
+	}
+}
+
+func stateValue(state circuitbreaker.State) float64 {
+	switch state {
+	case circuitbreaker.StateHalfOpen:
+		return 1
+	case circuitbreaker.StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
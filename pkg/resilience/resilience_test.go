@@ -0,0 +1,104 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/Alwanly/go-codebase/pkg/resilience"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+var errStalled = errors.New("i/o timeout")
+
+func TestGuard_OpensAndFailsFast(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "test"})
+	guard := resilience.NewGuard(&resilience.Opts{
+		Metrics: m,
+		Name:    "redis",
+		Breaker: circuitbreaker.Opts{FailureThreshold: 2, OpenTimeout: time.Hour},
+	})
+
+	for i := 0; i < 2; i++ {
+		err := guard.Do(context.Background(), func(context.Context) error { return errStalled })
+		assert.ErrorIs(t, err, errStalled)
+	}
+	assert.Equal(t, circuitbreaker.StateOpen, guard.State())
+
+	called := false
+	err := guard.Do(context.Background(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.False(t, called)
+	assert.True(t, apperror.IsKind(err, apperror.KindUnavailable))
+	assert.ErrorIs(t, err, circuitbreaker.ErrOpen)
+	assert.True(t, resilience.IsUnavailable(err))
+
+	state := m.Gauge("dependency_circuit_state", "State of the dependency circuit breakers: 0 closed, 1 half open, 2 open.", "dependency")
+	assert.Equal(t, float64(2), testutil.ToFloat64(state.WithLabelValues("redis")))
+	rejected := m.Counter("dependency_rejected_calls_total", "Calls to a dependency rejected by its circuit breaker or bulkhead.", "dependency", "reason")
+	assert.Equal(t, float64(1), testutil.ToFloat64(rejected.WithLabelValues("redis", "circuit_open")))
+}
+
+func TestGuard_IsFailure(t *testing.T) {
+	errMissing := errors.New("missing")
+	guard := resilience.NewGuard(&resilience.Opts{
+		Name:      "redis",
+		Breaker:   circuitbreaker.Opts{FailureThreshold: 1},
+		IsFailure: func(err error) bool { return !errors.Is(err, errMissing) },
+	})
+
+	_ = guard.Do(context.Background(), func(context.Context) error { return errMissing })
+	_ = guard.Do(context.Background(), func(context.Context) error { return context.Canceled })
+	assert.Equal(t, circuitbreaker.StateOpen, guard.State(), "custom filter replaces the default one")
+
+	guard = resilience.NewGuard(&resilience.Opts{Name: "postgres", Breaker: circuitbreaker.Opts{FailureThreshold: 1}})
+	_ = guard.Do(context.Background(), func(context.Context) error { return context.Canceled })
+	assert.Equal(t, circuitbreaker.StateClosed, guard.State(), "cancelled callers do not count by default")
+}
+
+func TestGuard_Bulkhead(t *testing.T) {
+	guard := resilience.NewGuard(&resilience.Opts{
+		Name:          "postgres",
+		MaxConcurrent: 1,
+		MaxWait:       100 * time.Millisecond,
+	})
+
+	done, err := guard.Acquire(context.Background())
+	require.NoError(t, err)
+
+	// the only slot is busy
+	_, err = guard.Acquire(context.Background())
+	assert.ErrorIs(t, err, resilience.ErrBulkheadFull)
+	assert.True(t, apperror.IsKind(err, apperror.KindUnavailable))
+
+	// a cancelled caller gets its own error
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = guard.Acquire(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a slot freed while waiting is taken
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		time.Sleep(5 * time.Millisecond)
+		done(nil)
+	}()
+	next, err := guard.Acquire(context.Background())
+	require.NoError(t, err)
+	next(nil)
+	wg.Wait()
+
+	assert.Equal(t, circuitbreaker.StateClosed, guard.State(), "rejections do not count as failures")
+}
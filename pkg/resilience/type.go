@@ -0,0 +1,90 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/circuitbreaker"
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Resilience"
+
+	defaultMaxWait = 100 * time.Millisecond
+)
+
+// ErrBulkheadFull is returned when every concurrency slot stayed busy for MaxWait.
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// Opts represents the options for configuring a guard.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Metrics exports the breaker state and the rejected calls. Optional.
+	Metrics *metrics.Service
+
+	// Name identifies the dependency in errors, logs, metrics and health checks, for example "postgres".
+	Name string
+
+	// Breaker configures the circuit breaker. Name is set to the guard name.
+	Breaker circuitbreaker.Opts
+
+	// MaxConcurrent is the number of concurrent calls allowed. Zero or negative means unlimited.
+	MaxConcurrent int
+	// MaxWait is how long a call waits for a free slot before being rejected. Default is 100ms.
+	MaxWait time.Duration
+
+	// IsFailure decides whether the error of a call counts toward the breaker.
+	// Default counts every error except context cancellation.
+	IsFailure func(err error) bool
+}
+
+// Guard protects a dependency with a circuit breaker and a bulkhead.
+type Guard struct {
+	logger    *zap.Logger
+	name      string
+	breaker   *circuitbreaker.Breaker
+	slots     chan struct{}
+	maxWait   time.Duration
+	isFailure func(err error) bool
+
+	state    *prometheus.GaugeVec
+	rejected *prometheus.CounterVec
+}
+
+// IGuard represents the interface for a guard.
+type IGuard interface {
+	// Name returns the name of the protected dependency.
+	Name() string
+
+	// State returns the state of the circuit breaker.
+	//
+	// Returns:
+	//   - circuitbreaker.State: breaker state
+	State() circuitbreaker.State
+
+	// Acquire reserves a call. It fails fast with a dependency unavailable error when the breaker is
+	// open or no slot frees up in time, otherwise done must be called with the error of the call.
+	//
+	// Parameters:
+	//   - ctx: context
+	//
+	// Returns:
+	//   - func(error): releases the call and records its outcome
+	//   - error: dependency unavailable error
+	Acquire(ctx context.Context) (done func(err error), err error)
+
+	// Do runs fn when the guard allows it and records its outcome.
+	//
+	// Parameters:
+	//   - ctx: context
+	//   - fn: call to the dependency
+	//
+	// Returns:
+	//   - error: dependency unavailable error, or the error of fn
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
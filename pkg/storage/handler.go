@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"errors"
+	"mime"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	ErrorInvalidDownloadURL = "Download link is invalid"
+	ErrorExpiredDownloadURL = "Download link has expired"
+	ErrorObjectNotFound     = "File not found"
+)
+
+func init() {
+	i18n.Register(i18n.LocaleIndonesian, map[string]string{
+		ErrorInvalidDownloadURL: "Tautan unduhan tidak valid",
+		ErrorExpiredDownloadURL: "Tautan unduhan sudah kedaluwarsa",
+		ErrorObjectNotFound:     "File tidak ditemukan",
+	})
+}
+
+func NewHandler(opts *HandlerOpts) *Handler {
+	return &Handler{
+		logger:  opts.Logger,
+		storage: opts.Storage,
+		signer:  opts.Signer,
+	}
+}
+
+// Download serves an object behind a signed URL. The route must end with a wildcard holding the
+// object key, e.g. /files/*.
+func (h *Handler) Download(c *fiber.Ctx) error {
+	l := logger.WithID(h.logger, ContextName, "Download")
+
+	key, err := url.PathUnescape(c.Params("*"))
+	if err != nil {
+		return apperror.Forbidden(contract.StatusCodeForbidden, ErrorInvalidDownloadURL)
+	}
+
+	err = h.signer.Verify(key, c.Query(QueryExpires), c.Query(QuerySignature))
+	if errors.Is(err, ErrSignatureExpired) {
+		return apperror.Forbidden(contract.StatusCodeForbidden, ErrorExpiredDownloadURL)
+	}
+	if err != nil {
+		return apperror.Forbidden(contract.StatusCodeForbidden, ErrorInvalidDownloadURL)
+	}
+
+	r, object, err := h.storage.Get(c.UserContext(), key)
+	if errors.Is(err, ErrNotFound) {
+		return apperror.NotFound(contract.StatusCodeNotFound, ErrorObjectNotFound)
+	}
+	if err != nil {
+		l.Error("failed to open object", zap.String("key", key), zap.Error(err))
+		return apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorInternalServer).Wrap(err)
+	}
+
+	// the URL is the credential, so shared caches must not keep the object
+	c.Set(fiber.HeaderContentType, object.ContentType)
+	c.Set(fiber.HeaderCacheControl, "private, max-age="+strconv.FormatInt(remaining(c.Query(QueryExpires)), 10))
+	c.Set(fiber.HeaderContentDisposition, disposition(object))
+	return c.SendStream(r, int(object.Size))
+}
+
+// remaining returns the seconds left before a verified expiry.
+func remaining(expires string) int64 {
+	expiresAt, _ := strconv.ParseInt(expires, 10, 64)
+	seconds := expiresAt - time.Now().Unix()
+	if seconds < 0 {
+		return 0
+	}
+	return seconds
+}
+
+// disposition displays images inline and downloads every other type, so an uploaded document is
+// never rendered by the browser.
+func disposition(object Object) string {
+	kind := "attachment"
+	if strings.HasPrefix(object.ContentType, "image/") {
+		kind = "inline"
+	}
+	return mime.FormatMediaType(kind, map[string]string{"filename": path.Base(object.Key)})
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	localObjectsDir = "objects"
+	localMetaDir    = "meta"
+)
+
+// Local stores the objects on the local disk. The content lives under root/objects and the
+// metadata under root/meta, so no key can shadow the metadata of another.
+type Local struct {
+	root string
+}
+
+// localMeta is the metadata stored next to an object.
+type localMeta struct {
+	ContentType string `json:"contentType"`
+}
+
+// NewLocal returns a local disk storage rooted at the given directory, creating it if needed.
+func NewLocal(root string) (*Local, error) {
+	if root == "" {
+		root = "storage"
+	}
+	for _, dir := range []string{localObjectsDir, localMetaDir} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o750); err != nil {
+			return nil, err
+		}
+	}
+	return &Local{root: root}, nil
+}
+
+func (s *Local) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (Object, error) {
+	if !validKey(key) {
+		return Object{}, ErrInvalidKey
+	}
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+
+	size, err := writeFile(s.objectPath(key), r)
+	if err != nil {
+		return Object{}, err
+	}
+	meta, err := json.Marshal(localMeta{ContentType: contentType(opts)})
+	if err != nil {
+		return Object{}, err
+	}
+	if _, err := writeFile(s.metaPath(key), bytes.NewReader(meta)); err != nil {
+		return Object{}, err
+	}
+
+	return Object{Key: key, Size: size, ContentType: contentType(opts), ModTime: time.Now()}, nil
+}
+
+func (s *Local) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	object, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, Object{}, err
+	}
+
+	f, err := os.Open(s.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, Object{}, ErrNotFound
+	}
+	if err != nil {
+		return nil, Object{}, err
+	}
+	return f, object, nil
+}
+
+func (s *Local) Stat(ctx context.Context, key string) (Object, error) {
+	if !validKey(key) {
+		return Object{}, ErrNotFound
+	}
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+
+	info, err := os.Stat(s.objectPath(key))
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	meta := localMeta{}
+	if raw, err := os.ReadFile(s.metaPath(key)); err == nil {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	return Object{Key: key, Size: info.Size(), ContentType: contentType(PutOptions{ContentType: meta.ContentType}), ModTime: info.ModTime()}, nil
+}
+
+func (s *Local) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	for _, p := range []string{s.objectPath(key), s.metaPath(key)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Local) objectPath(key string) string {
+	return filepath.Join(s.root, localObjectsDir, filepath.FromSlash(key))
+}
+
+func (s *Local) metaPath(key string) string {
+	return filepath.Join(s.root, localMetaDir, filepath.FromSlash(key)+".json")
+}
+
+// writeFile writes a file through a temporary file renamed into place, so readers never see a
+// partial object.
+func writeFile(name string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+	return size, os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// Memory stores the objects in memory. Objects are lost on restart and not shared between
+// instances.
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+type memoryObject struct {
+	data   []byte
+	object Object
+}
+
+// NewMemory returns an empty in-memory storage.
+func NewMemory() *Memory {
+	return &Memory{objects: map[string]memoryObject{}}
+}
+
+func (s *Memory) Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (Object, error) {
+	if !validKey(key) {
+		return Object{}, ErrInvalidKey
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Object{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+
+	object := Object{Key: key, Size: int64(len(data)), ContentType: contentType(opts), ModTime: time.Now()}
+	s.mu.Lock()
+	s.objects[key] = memoryObject{data: data, object: object}
+	s.mu.Unlock()
+	return object, nil
+}
+
+func (s *Memory) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Object{}, err
+	}
+
+	s.mu.RLock()
+	stored, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, Object{}, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(stored.data)), stored.object, nil
+}
+
+func (s *Memory) Stat(ctx context.Context, key string) (Object, error) {
+	if err := ctx.Err(); err != nil {
+		return Object{}, err
+	}
+
+	s.mu.RLock()
+	stored, ok := s.objects[key]
+	s.mu.RUnlock()
+	if !ok {
+		return Object{}, ErrNotFound
+	}
+	return stored.object, nil
+}
+
+func (s *Memory) Delete(ctx context.Context, key string) error {
+	if !validKey(key) {
+		return ErrInvalidKey
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewSigner returns a URL signer. It fails without a signing key, since anyone could then forge
+// download URLs.
+func NewSigner(opts *SignerOpts) (*Signer, error) {
+	if len(opts.Key) == 0 {
+		return nil, ErrMissingSigningKey
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = defaultURLTTL
+	}
+
+	return &Signer{
+		key:     opts.Key,
+		baseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+		ttl:     ttl,
+	}, nil
+}
+
+// URL returns a download URL of an object valid for the configured TTL.
+func (s *Signer) URL(key string) string {
+	return s.URLUntil(key, time.Now().Add(s.ttl))
+}
+
+// URLUntil returns a download URL of an object valid until the given time.
+func (s *Signer) URLUntil(key string, expiresAt time.Time) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set(QueryExpires, expires)
+	query.Set(QuerySignature, s.sign(key, expires))
+	return s.baseURL + "/" + strings.Join(segments, "/") + "?" + query.Encode()
+}
+
+// Verify checks the expiry and the signature of a download URL of an object.
+func (s *Signer) Verify(key, expires, signature string) error {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		return ErrInvalidSignature
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > expiresAt {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+// sign returns the signature of an object key and expiry.
+func (s *Signer) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// NewStorage returns the storage of the configured driver.
+func NewStorage(opts *Opts) (IStorage, error) {
+	l := logger.WithID(opts.Logger, ContextName, "NewStorage")
+
+	switch opts.Driver {
+	case "", DriverLocal:
+		l.Info("Using local storage", zap.String("root", opts.Root))
+		return NewLocal(opts.Root)
+	case DriverMemory:
+		l.Warn("Using in-memory storage, objects are lost on restart")
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, opts.Driver)
+	}
+}
+
+// validKey reports whether a key is a clean relative path that cannot escape the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	if path.Clean(key) != key {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// contentType returns the content type of an object, defaulting to binary data.
+func contentType(opts PutOptions) string {
+	if opts.ContentType == "" {
+		return "application/octet-stream"
+	}
+	return opts.ContentType
+}
@@ -0,0 +1,123 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/storage"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStorage_Drivers(t *testing.T) {
+	local, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for name, s := range map[string]storage.IStorage{"local": local, "memory": storage.NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			object, err := s.Put(ctx, "books/42/cover.png", strings.NewReader("png data"), storage.PutOptions{ContentType: "image/png"})
+			require.NoError(t, err)
+			assert.Equal(t, int64(8), object.Size)
+			assert.Equal(t, "image/png", object.ContentType)
+
+			r, object, err := s.Get(ctx, "books/42/cover.png")
+			require.NoError(t, err)
+			data, _ := io.ReadAll(r)
+			r.Close()
+			assert.Equal(t, "png data", string(data))
+			assert.Equal(t, "image/png", object.ContentType)
+
+			// replacing keeps a single object
+			_, err = s.Put(ctx, "books/42/cover.png", strings.NewReader("new"), storage.PutOptions{})
+			require.NoError(t, err)
+			object, err = s.Stat(ctx, "books/42/cover.png")
+			require.NoError(t, err)
+			assert.Equal(t, int64(3), object.Size)
+			assert.Equal(t, "application/octet-stream", object.ContentType)
+
+			require.NoError(t, s.Delete(ctx, "books/42/cover.png"))
+			require.NoError(t, s.Delete(ctx, "books/42/cover.png"))
+			_, _, err = s.Get(ctx, "books/42/cover.png")
+			assert.ErrorIs(t, err, storage.ErrNotFound)
+
+			for _, key := range []string{"", "/etc/passwd", "../secret", "books/../../secret", "books//cover", "books\\cover"} {
+				_, err := s.Put(ctx, key, strings.NewReader("x"), storage.PutOptions{})
+				assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+			}
+		})
+	}
+}
+
+func TestNewStorage_UnknownDriver(t *testing.T) {
+	_, err := storage.NewStorage(&storage.Opts{Logger: zap.NewNop(), Driver: "s3"})
+	assert.ErrorIs(t, err, storage.ErrUnknownDriver)
+}
+
+func TestSigner(t *testing.T) {
+	_, err := storage.NewSigner(&storage.SignerOpts{})
+	assert.ErrorIs(t, err, storage.ErrMissingSigningKey)
+
+	signer, err := storage.NewSigner(&storage.SignerOpts{Key: []byte("secret"), BaseURL: "https://api.example.com/files/"})
+	require.NoError(t, err)
+
+	signed, err := url.Parse(signer.URL("books/42/cover image.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "/files/books/42/cover%20image.png", signed.EscapedPath())
+	expires, signature := signed.Query().Get(storage.QueryExpires), signed.Query().Get(storage.QuerySignature)
+
+	assert.NoError(t, signer.Verify("books/42/cover image.png", expires, signature))
+	assert.ErrorIs(t, signer.Verify("books/43/cover image.png", expires, signature), storage.ErrInvalidSignature)
+	assert.ErrorIs(t, signer.Verify("books/42/cover image.png", expires+"0", signature), storage.ErrInvalidSignature)
+
+	other, _ := storage.NewSigner(&storage.SignerOpts{Key: []byte("other")})
+	assert.ErrorIs(t, other.Verify("books/42/cover image.png", expires, signature), storage.ErrInvalidSignature)
+
+	expired, _ := url.Parse(signer.URLUntil("books/42/cover image.png", time.Now().Add(-time.Minute)))
+	assert.ErrorIs(t, signer.Verify("books/42/cover image.png", expired.Query().Get(storage.QueryExpires), expired.Query().Get(storage.QuerySignature)), storage.ErrSignatureExpired)
+}
+
+func TestHandler_Download(t *testing.T) {
+	s := storage.NewMemory()
+	_, err := s.Put(context.Background(), "books/42/cover.png", strings.NewReader("png data"), storage.PutOptions{ContentType: "image/png"})
+	require.NoError(t, err)
+	_, err = s.Put(context.Background(), "books/42/notes.html", strings.NewReader("<script>"), storage.PutOptions{ContentType: "text/html"})
+	require.NoError(t, err)
+	signer, _ := storage.NewSigner(&storage.SignerOpts{Key: []byte("secret"), BaseURL: "/files"})
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Get("/files/*", storage.NewHandler(&storage.HandlerOpts{Logger: zap.NewNop(), Storage: s, Signer: signer}).Download)
+
+	resp, _ := app.Test(httptest.NewRequest(http.MethodGet, signer.URL("books/42/cover.png"), nil))
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "png data", string(body))
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "inline; filename=cover.png", resp.Header.Get("Content-Disposition"))
+	assert.Contains(t, resp.Header.Get("Cache-Control"), "private")
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, signer.URL("books/42/notes.html"), nil))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "attachment; filename=notes.html", resp.Header.Get("Content-Disposition"))
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, "/files/books/42/cover.png", nil))
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, signer.URLUntil("books/42/cover.png", time.Now().Add(-time.Minute)), nil))
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Contains(t, string(body), storage.ErrorExpiredDownloadURL)
+
+	resp, _ = app.Test(httptest.NewRequest(http.MethodGet, signer.URL("books/43/cover.png"), nil))
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
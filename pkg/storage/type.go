@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Storage"
+
+	// DriverLocal stores the objects on the local disk.
+	DriverLocal = "local"
+	// DriverMemory stores the objects in memory, for tests and local development.
+	DriverMemory = "memory"
+
+	// QueryExpires carries the expiry of a signed URL, in Unix seconds.
+	QueryExpires = "expires"
+	// QuerySignature carries the signature of a signed URL.
+	QuerySignature = "signature"
+
+	defaultURLTTL = 15 * time.Minute
+)
+
+var (
+	ErrNotFound          = errors.New("storage: object not found")
+	ErrInvalidKey        = errors.New("storage: invalid object key")
+	ErrUnknownDriver     = errors.New("storage: unknown driver")
+	ErrInvalidSignature  = errors.New("storage: invalid signature")
+	ErrSignatureExpired  = errors.New("storage: signature expired")
+	ErrMissingSigningKey = errors.New("storage: missing signing key")
+)
+
+// Object describes a stored object.
+type Object struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType"`
+	ModTime     time.Time `json:"modTime"`
+}
+
+// PutOptions represents the options of a stored object.
+type PutOptions struct {
+	// ContentType is served with the object. Default is application/octet-stream.
+	ContentType string
+}
+
+// Opts represents the options for configuring the storage.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Driver is local or memory. Default is local.
+	Driver string
+	// Root is the directory of the local driver.
+	Root string
+}
+
+// SignerOpts represents the options for configuring the URL signer.
+type SignerOpts struct {
+	// Key signs the URLs. It must be shared by every instance serving the downloads.
+	Key []byte
+	// BaseURL is the URL the download handler is mounted on, e.g. https://api.example.com/files.
+	BaseURL string
+	// TTL is how long a signed URL is valid. Default is 15m.
+	TTL time.Duration
+}
+
+// Signer signs and verifies download URLs with HMAC-SHA256.
+type Signer struct {
+	key     []byte
+	baseURL string
+	ttl     time.Duration
+}
+
+// HandlerOpts represents the options for configuring the download handler.
+type HandlerOpts struct {
+	Logger  *zap.Logger
+	Storage IStorage
+	Signer  *Signer
+}
+
+// Handler serves the objects behind signed URLs.
+type Handler struct {
+	logger  *zap.Logger
+	storage IStorage
+	signer  *Signer
+}
+
+// IStorage stores binary objects by key. Keys are slash separated relative paths, e.g.
+// books/42/cover.
+type IStorage interface {
+	// Put stores an object, replacing any object with the same key.
+	//
+	// Parameters:
+	//   - ctx: The context.
+	//   - key: The object key.
+	//   - r: The object content.
+	//   - opts: The object options.
+	//
+	// Returns:
+	//   - Object: The stored object.
+	//   - error: ErrInvalidKey for an invalid key, or any error from the storage.
+	Put(ctx context.Context, key string, r io.Reader, opts PutOptions) (Object, error)
+
+	// Get opens an object. The caller closes the reader.
+	//
+	// Parameters:
+	//   - ctx: The context.
+	//   - key: The object key.
+	//
+	// Returns:
+	//   - io.ReadCloser: The object content.
+	//   - Object: The object.
+	//   - error: ErrNotFound when the object does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+
+	// Stat returns an object without opening it.
+	//
+	// Parameters:
+	//   - ctx: The context.
+	//   - key: The object key.
+	//
+	// Returns:
+	//   - Object: The object.
+	//   - error: ErrNotFound when the object does not exist.
+	Stat(ctx context.Context, key string) (Object, error)
+
+	// Delete removes an object. Removing a missing object is not an error.
+	//
+	// Parameters:
+	//   - ctx: The context.
+	//   - key: The object key.
+	//
+	// Returns:
+	//   - error: ErrInvalidKey for an invalid key, or any error from the storage.
+	Delete(ctx context.Context, key string) error
+}
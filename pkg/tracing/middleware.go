@@ -0,0 +1,80 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fiberCarrier adapts the Fiber request headers to a propagation.TextMapCarrier.
+type fiberCarrier struct {
+	c *fiber.Ctx
+}
+
+func (f fiberCarrier) Get(key string) string {
+	return f.c.Get(key)
+}
+
+func (f fiberCarrier) Set(key string, value string) {
+	f.c.Request().Header.Set(key, value)
+}
+
+func (f fiberCarrier) Keys() []string {
+	keys := []string{}
+	f.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Middleware starts a server span for every request, continuing the trace from the
+// W3C traceparent header, and attaches the span to the request user context.
+func (s *Service) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), fiberCarrier{c: c})
+		ctx, span := s.Tracer.Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				// the request strings are reused by Fiber after the request, spans outlive it
+				attribute.String(string(semconv.HTTPRequestMethodKey), utils.CopyString(c.Method())),
+				semconv.URLPath(utils.CopyString(c.Path())),
+				semconv.URLScheme(c.Protocol()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(utils.CopyString(c.Get(fiber.HeaderUserAgent))),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		// the route template is only known after routing
+		route := c.Route().Path
+		span.SetName(c.Method() + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route))
+
+		// the error handler runs after this middleware, so resolve the final status here
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = wrapper.ResponseFromError(err).Code
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+		// client errors are answered as expected, only server errors fail the span
+		if status >= http.StatusInternalServerError {
+			if err != nil {
+				span.RecordError(err)
+			}
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/go-redis/redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook creates a client span for every Redis command and pipeline.
+type redisHook struct {
+	tracer trace.Tracer
+}
+
+// RedisHook returns a go-redis hook tracing every command, to be registered with AddHook.
+func (s *Service) RedisHook() redis.Hook {
+	return &redisHook{tracer: s.Tracer}
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := h.tracer.Start(ctx, "redis.dial", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemRedis))
+		defer span.End()
+
+		conn, err := next(ctx, network, addr)
+		recordRedisError(span, err)
+		return conn, err
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.tracer.Start(ctx, "redis."+cmd.FullName(), trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemRedis,
+				semconv.DBOperationName(cmd.FullName()),
+			))
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			names = append(names, cmd.FullName())
+		}
+
+		ctx, span := h.tracer.Start(ctx, "redis.pipeline", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemRedis,
+				semconv.DBOperationName(strings.Join(names, " ")),
+				attribute.Int("db.redis.num_cmd", len(cmds)),
+			))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// recordRedisError marks the span as failed, except for redis.Nil which only means a missing key.
+func recordRedisError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+func NewTracing(opts *Opts) (*Service, error) {
+	l := logger.WithID(opts.Logger, ContextName, "NewTracing")
+
+	ratio := 1.0
+	if opts.SampleRatio != nil {
+		ratio = *opts.SampleRatio
+	}
+	if ratio < 0 || ratio > 1 {
+		err := fmt.Errorf("sample ratio %v must be between 0 and 1", ratio)
+		l.Error("Invalid trace sample ratio", zap.Error(err))
+		return nil, err
+	}
+
+	// create exporter
+	exporter, output, err := newExporter(opts)
+	if err != nil {
+		l.Error("Cannot create trace exporter", zap.String("exporter", opts.Exporter), zap.Error(err))
+		return nil, err
+	}
+
+	// describe the service
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.ServiceVersion(opts.ServiceVersion),
+		semconv.DeploymentEnvironment(opts.Environment),
+	))
+	if err != nil {
+		l.Error("Cannot create trace resource", zap.Error(err))
+		return nil, err
+	}
+
+	// create provider
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	}
+	if exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	// register as global provider and use W3C trace context propagation
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	l.Info("Tracer created", zap.String("exporter", opts.Exporter))
+	return &Service{
+		Provider: provider,
+		Tracer:   provider.Tracer(InstrumentationName),
+		logger:   opts.Logger,
+		output:   output,
+	}, nil
+}
+
+// Shutdown flushes the pending spans and stops the exporter.
+func (s *Service) Shutdown(ctx context.Context) error {
+	if err := s.Provider.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	if s.output != nil {
+		return s.output.Close()
+	}
+
+	return nil
+}
+
+func newExporter(opts *Opts) (sdktrace.SpanExporter, io.Closer, error) {
+	switch opts.Exporter {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), clientOpts...)
+		return exporter, nil, err
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(opts.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", opts.Exporter)
+	}
+}
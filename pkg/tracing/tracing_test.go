@@ -0,0 +1,166 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/middleware"
+	"github.com/Alwanly/go-codebase/pkg/tracing"
+	"github.com/Alwanly/go-codebase/pkg/utils"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v9"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestTracing(t *testing.T) (*tracing.Service, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	s, err := tracing.NewTracing(&tracing.Opts{Logger: zap.NewNop(), ServiceName: "test"})
+	assert.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	s.Provider.RegisterSpanProcessor(recorder)
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+
+	return s, recorder
+}
+
+func attributeValue(attrs []attribute.KeyValue, key string) attribute.Value {
+	for _, a := range attrs {
+		if string(a.Key) == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware_ContinuesTraceParent(t *testing.T) {
+	s, recorder := newTestTracing(t)
+
+	core, logs := observer.New(zap.DebugLevel)
+	app := fiber.New()
+	app.Use(s.Middleware())
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		logger.WithContext(c.UserContext(), zap.New(core)).Info("get book")
+		return c.SendStatus(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/books/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	span := spans[0]
+	assert.Equal(t, "GET /books/:id", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.Equal(t, int64(http.StatusOK), attributeValue(span.Attributes(), "http.response.status_code").AsInt64())
+
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", fields["trace.id"])
+	assert.Equal(t, span.SpanContext().SpanID().String(), fields["span.id"])
+}
+
+func TestMiddleware_ErrorStatuses(t *testing.T) {
+	s, recorder := newTestTracing(t)
+
+	app := fiber.New(fiber.Config{ErrorHandler: middleware.Recover(zap.NewNop())})
+	app.Use(s.Middleware())
+	app.Post("/books", func(c *fiber.Ctx) error {
+		return &validator.ModelValidationError{
+			ResponseBody: wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeValidationFailed, contract.ErrorValidatePayload, nil),
+		}
+	})
+	app.Get("/books/:id", func(c *fiber.Ctx) error {
+		return apperror.NotFound(contract.StatusCodeNotFound, contract.ErrorNotFound)
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/books", nil),
+		httptest.NewRequest(http.MethodGet, "/books/42", nil),
+		httptest.NewRequest(http.MethodGet, "/fail", nil),
+	} {
+		_, _ = app.Test(req)
+	}
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	expected := []struct {
+		status int64
+		code   codes.Code
+	}{{400, codes.Unset}, {404, codes.Unset}, {500, codes.Error}}
+	for i, span := range spans {
+		assert.Equal(t, expected[i].status, attributeValue(span.Attributes(), "http.response.status_code").AsInt64(), span.Name())
+		assert.Equal(t, expected[i].code, span.Status().Code, span.Name())
+	}
+}
+
+func TestRedisHook(t *testing.T) {
+	s, recorder := newTestTracing(t)
+
+	mr := miniredis.RunT(t)
+	cl := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	cl.AddHook(s.RedisHook())
+
+	ctx, parent := s.Tracer.Start(context.Background(), "parent")
+	assert.NoError(t, cl.Set(ctx, "key", "value", 0).Err())
+	parent.End()
+
+	var cmdSpan sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "redis.set" {
+			cmdSpan = span
+		}
+	}
+	assert.NotNil(t, cmdSpan)
+	assert.Equal(t, parent.SpanContext().SpanID(), cmdSpan.Parent().SpanID())
+	assert.Equal(t, "redis", attributeValue(cmdSpan.Attributes(), "db.system").AsString())
+}
+
+func TestNewTracing_UnknownExporter(t *testing.T) {
+	_, err := tracing.NewTracing(&tracing.Opts{Logger: zap.NewNop(), Exporter: "datadog"})
+	assert.Error(t, err)
+}
+
+func TestNewTracing_SampleRatio(t *testing.T) {
+	// an explicit 0 samples no trace
+	s, err := tracing.NewTracing(&tracing.Opts{Logger: zap.NewNop(), SampleRatio: utils.ToPointer(0.0)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Shutdown(context.Background()) })
+	_, span := s.Tracer.Start(context.Background(), "GET /books")
+	assert.False(t, span.SpanContext().IsSampled())
+	span.End()
+
+	// unset samples every trace
+	s, _ = newTestTracing(t)
+	_, span = s.Tracer.Start(context.Background(), "GET /books")
+	assert.True(t, span.SpanContext().IsSampled())
+	span.End()
+
+	for _, ratio := range []float64{-0.1, 1.5} {
+		_, err := tracing.NewTracing(&tracing.Opts{Logger: zap.NewNop(), SampleRatio: utils.ToPointer(ratio)})
+		assert.Error(t, err, ratio)
+	}
+}
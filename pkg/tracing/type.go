@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"io"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Tracing"
+
+	// InstrumentationName identifies the spans created by this package.
+	InstrumentationName = "github.com/Alwanly/go-codebase/pkg/tracing"
+
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Opts represents the options for configuring the tracer provider.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+
+	// ServiceName, ServiceVersion and Environment describe the traced service.
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+
+	// Exporter selects where spans are sent: none, otlp, stdout or file. Default is none.
+	Exporter string
+	// OTLPEndpoint is the OTLP/HTTP collector endpoint, for example localhost:4318.
+	OTLPEndpoint string
+	// OTLPInsecure disables TLS for the OTLP exporter.
+	OTLPInsecure bool
+	// FilePath is the file spans are written to when the exporter is file.
+	FilePath string
+	// SampleRatio is the ratio of new traces that are sampled, between 0 (none) and 1. Default is 1
+	// (all traces) when nil.
+	SampleRatio *float64
+}
+
+// Service represents the tracing service.
+type Service struct {
+	Provider *sdktrace.TracerProvider
+	Tracer   trace.Tracer
+
+	logger *zap.Logger
+	output io.Closer
+}
@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsValidOrigin reports whether the origin is an http or https scheme and a host, with an optional
+// subdomain wildcard, e.g. https://*.example.com, as accepted by the CORS middleware of Fiber.
+func IsValidOrigin(origin string) bool {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	return u.Host != "" && !strings.Contains(u.Host, "*") && (u.Path == "" || u.Path == "/") && u.RawQuery == "" && u.Fragment == ""
+}
@@ -0,0 +1,92 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// DatabaseRules returns the rules querying the database, run by ValidateStructCtx in the
+// transaction of the context. They are named db_unique and db_exists rather than unique and exists,
+// so the built-in unique, which checks the elements of a slice or map, is left untouched:
+//
+//   - db_unique=books.title: no row of books has the value in title. db_unique=books.title ID
+//     ignores the row whose id is the value of the ID field, for updates
+//   - db_exists=users.id: a row of users has the value in id
+//
+// A failing query is returned by ValidateStructCtx instead of a validation error.
+func DatabaseRules(db database.IDBService) []Rule {
+	return []Rule{
+		{Tag: "db_unique", FnCtx: validateUnique(db), Messages: map[string]string{
+			"en": "{0} is already taken",
+			"id": "{0} sudah digunakan",
+		}},
+		{Tag: "db_exists", FnCtx: validateExists(db), Messages: map[string]string{
+			"en": "{0} does not exist",
+			"id": "{0} tidak ditemukan",
+		}},
+	}
+}
+
+func validateUnique(db database.IDBService) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		params := strings.Fields(fl.Param())
+		if len(params) == 0 || len(params) > 2 {
+			panic(fmt.Sprintf("validator: invalid db_unique %q", fl.Param()))
+		}
+		table, column := tableColumn("db_unique", params[0])
+
+		query := db.GetTransaction(ctx).Table(table).Where(clause.Eq{Column: clause.Column{Name: column}, Value: fl.Field().Interface()})
+		if len(params) == 2 {
+			self, _, _, found := fl.GetStructFieldOKAdvanced2(fl.Parent(), params[1])
+			if !found {
+				panic(fmt.Sprintf("validator: unknown field %q in db_unique", params[1]))
+			}
+			query = query.Where(clause.Neq{Column: clause.Column{Name: "id"}, Value: self.Interface()})
+		}
+
+		count, err := count(query)
+		if err != nil {
+			ReportRuleError(ctx, err)
+			return true
+		}
+		return count == 0
+	}
+}
+
+func validateExists(db database.IDBService) validator.FuncCtx {
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
+		table, column := tableColumn("db_exists", fl.Param())
+
+		count, err := count(db.GetTransaction(ctx).Table(table).Where(clause.Eq{Column: clause.Column{Name: column}, Value: fl.Field().Interface()}))
+		if err != nil {
+			ReportRuleError(ctx, err)
+			return true
+		}
+		return count > 0
+	}
+}
+
+// tableColumn splits a table.column parameter. Both are identifiers, they are written in the query.
+func tableColumn(tag string, param string) (string, string) {
+	table, column, ok := strings.Cut(param, ".")
+	if !ok || !identifierRegex.MatchString(table) || !identifierRegex.MatchString(column) {
+		panic(fmt.Sprintf("validator: invalid %s %q, want table.column", tag, param))
+	}
+	return table, column
+}
+
+// count returns the number of matching rows.
+func count(query *gorm.DB) (int64, error) {
+	var n int64
+	err := query.Count(&n).Error
+	return n, err
+}
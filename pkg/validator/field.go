@@ -0,0 +1,254 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// nameTags are the struct tags naming a field in the request sources, by priority: the JSON body,
+// the query string, the path params, the form body and the headers.
+var nameTags = []string{"json", "query", "params", "form", "reqHeader"}
+
+// secretFields are the parts of field names whose values are omitted from the validation errors.
+var secretFields = []string{"password", "passphrase", "secret", "token"}
+
+// fieldParamTags are the tags whose parameter names other fields, e.g. gtfield=StartDate. The Go
+// names in their messages are replaced with the names of the fields in the request.
+var fieldParamTags = map[string]bool{
+	"eqfield": true, "nefield": true, "gtfield": true, "gtefield": true, "ltfield": true, "ltefield": true,
+	"eqcsfield": true, "necsfield": true, "gtcsfield": true, "gtecsfield": true, "ltcsfield": true, "ltecsfield": true,
+	"fieldcontains": true, "fieldexcludes": true,
+	"required_if": true, "required_unless": true, "required_with": true, "required_with_all": true,
+	"required_without": true, "required_without_all": true,
+	"excluded_if": true, "excluded_unless": true, "excluded_with": true, "excluded_with_all": true,
+	"excluded_without": true, "excluded_without_all": true,
+	"after_field": true, "before_field": true,
+}
+
+type fieldNamesContextKey struct{}
+
+// BoundFrom returns the tag of the request source a field was bound from, given the names of the
+// field by tag, or false when it is not known.
+type BoundFrom func(names map[string]string) (string, bool)
+
+// WithFieldNames returns a copy of ctx carrying the names of the fields of the request, as
+// returned by FieldNames, used by ValidateModelContext to name the fields of the errors.
+func WithFieldNames(ctx context.Context, names map[string]string) context.Context {
+	return context.WithValue(ctx, fieldNamesContextKey{}, names)
+}
+
+func fieldNamesFromContext(ctx context.Context) (map[string]string, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	names, ok := ctx.Value(fieldNamesContextKey{}).(map[string]string)
+	return names, ok
+}
+
+// FieldNames returns the names in the request of the fields of a struct type, by their Go path,
+// e.g. Items.Title. A top-level field named differently in several sources, such as
+// json:"name" query:"q", is named after the source boundFrom returns, or the first one by priority.
+func FieldNames(t reflect.Type, boundFrom BoundFrom) map[string]string {
+	names := map[string]string{}
+	collectFieldNames(names, t, "", boundFrom, map[reflect.Type]bool{})
+	return names
+}
+
+func collectFieldNames(names map[string]string, t reflect.Type, prefix string, boundFrom BoundFrom, visiting map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		path := field.Name
+		if prefix != "" {
+			path = prefix + "." + field.Name
+		}
+		names[path] = requestName(field, boundFrom)
+		// the sources only tell the top-level fields apart
+		collectFieldNames(names, field.Type, path, nil, visiting)
+	}
+}
+
+// requestName returns the name of a field in the source boundFrom returns, or its first name by
+// priority, or its Go name.
+func requestName(field reflect.StructField, boundFrom BoundFrom) string {
+	if boundFrom != nil {
+		tags := map[string]string{}
+		for _, tag := range nameTags {
+			if name, _, _ := strings.Cut(field.Tag.Get(tag), ","); name != "" && name != "-" {
+				tags[tag] = name
+			}
+		}
+		if tag, ok := boundFrom(tags); ok {
+			return tags[tag]
+		}
+	}
+	if name := fieldName(field); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// fieldName returns the name of a field in the request, from the first of its name tags, or an
+// empty string to use the Go field name.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range nameTags {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return ""
+}
+
+// IsSecretField reports whether the value of a field must not be returned in errors, such as a
+// password, from its name or path.
+func IsSecretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, secret := range secretFields {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldPath returns the path of the field from the validated struct, e.g. items[2].title, with
+// the names of the fields, if known.
+func fieldPath(e validator.FieldError, names map[string]string) string {
+	// the namespaces start with the name of the validated struct
+	segments := splitNamespace(e.Namespace())
+	goSegments := splitNamespace(e.StructNamespace())
+	if len(segments) < 2 || len(segments) != len(goSegments) {
+		return e.Field()
+	}
+
+	path := make([]string, 0, len(segments)-1)
+	for i := 1; i < len(segments); i++ {
+		name, index := cutIndex(segments[i])
+		if resolved, ok := names[goPath(goSegments[1:i+1])]; ok {
+			name = resolved
+		}
+		path = append(path, name+index)
+	}
+	return strings.Join(path, ".")
+}
+
+// fieldMessage returns the translated message of the error, with the names of the field and of the
+// fields in its parameter, if known.
+func fieldMessage(e validator.FieldError, trans ut.Translator, names map[string]string) string {
+	message := e.Translate(trans)
+	goSegments := splitNamespace(e.StructNamespace())
+	if len(goSegments) < 2 {
+		return message
+	}
+
+	if name, ok := names[goPath(goSegments[1:])]; ok && name != e.Field() {
+		message = replaceName(message, e.Field(), name)
+	}
+	if !fieldParamTags[e.Tag()] {
+		return message
+	}
+
+	parent := goPath(goSegments[1 : len(goSegments)-1])
+	for _, param := range strings.Fields(e.Param()) {
+		sibling := param
+		if parent != "" {
+			sibling = parent + "." + param
+		}
+		if name, ok := names[sibling]; ok {
+			message = replaceName(message, param, name)
+		} else if path, ok := requestPath(strings.Split(param, "."), names); ok {
+			// the cs tags name a field from the validated struct, e.g. Inner.Field
+			message = replaceName(message, param, path)
+		}
+	}
+	return message
+}
+
+// requestPath returns the path in the request of the field at the Go path of the validated struct.
+func requestPath(goSegments []string, names map[string]string) (string, bool) {
+	path := make([]string, len(goSegments))
+	for i := range goSegments {
+		name, ok := names[strings.Join(goSegments[:i+1], ".")]
+		if !ok {
+			return "", false
+		}
+		path[i] = name
+	}
+	return strings.Join(path, "."), true
+}
+
+// splitNamespace splits a namespace on the dots outside of the indexes, e.g. Items[a.b].Title.
+func splitNamespace(namespace string) []string {
+	segments := []string{}
+	depth, start := 0, 0
+	for i, r := range namespace {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case r == '.' && depth == 0:
+			segments = append(segments, namespace[start:i])
+			start = i + 1
+		}
+	}
+	return append(segments, namespace[start:])
+}
+
+// cutIndex splits a namespace segment into the field name and its index, e.g. items and [2].
+func cutIndex(segment string) (string, string) {
+	if i := strings.Index(segment, "["); i >= 0 {
+		return segment[:i], segment[i:]
+	}
+	return segment, ""
+}
+
+// goPath returns the key of FieldNames of the struct namespace segments, without the indexes.
+func goPath(segments []string) string {
+	path := make([]string, len(segments))
+	for i, segment := range segments {
+		path[i], _ = cutIndex(segment)
+	}
+	return strings.Join(path, ".")
+}
+
+// replaceName replaces the whole word old with name in the message.
+func replaceName(message string, old string, name string) string {
+	return regexp.MustCompile(`\b`+regexp.QuoteMeta(old)+`\b`).ReplaceAllLiteralString(message, name)
+}
+
+// isSecret reports whether the value of the field must not be returned, such as a password.
+func isSecret(e validator.FieldError) bool {
+	return IsSecretField(e.StructField()) || IsSecretField(e.Field())
+}
+
+// newValidationError returns the validation error of a field, with its message translated and
+// the fields named as in the request, from names when known.
+func newValidationError(e validator.FieldError, trans ut.Translator, names map[string]string) ValidationError {
+	var value interface{}
+	if !isSecret(e) {
+		value = e.Value()
+	}
+	return ValidationError{
+		Field:   fieldPath(e, names),
+		Value:   value,
+		Message: fieldMessage(e, trans, names),
+	}
+}
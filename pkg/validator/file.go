@@ -0,0 +1,145 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at.
+const sniffLength = 512
+
+var fileHeaderType = reflect.TypeOf(multipart.FileHeader{})
+
+// fileRules are the rules of the uploaded files:
+//
+//   - file_max_size=2MB: the file is at most 2MB, units are B, KB, MB and GB
+//   - file_mime=image/png image/jpeg: the type sniffed from the content is one of the types, image/* matches any image
+//   - max_files=3: at most 3 files are uploaded
+func fileRules() []Rule {
+	return []Rule{
+		{Tag: "file_max_size", Fn: validateFileMaxSize, Messages: map[string]string{
+			"en": "{0} must not be larger than {1}",
+			"id": "{0} tidak boleh lebih besar dari {1}",
+		}},
+		{Tag: "file_mime", Fn: validateFileMIME, Messages: map[string]string{
+			"en": "{0} must be a file of type {1}",
+			"id": "{0} harus berupa file dengan tipe {1}",
+		}},
+		{Tag: "max_files", Fn: validateMaxFiles, Messages: map[string]string{
+			"en": "{0} must contain at most {1} files",
+			"id": "{0} hanya boleh berisi paling banyak {1} file",
+		}},
+	}
+}
+
+func validateFileMaxSize(fl validator.FieldLevel) bool {
+	file, ok := fileHeader(fl.Field())
+	if !ok {
+		return false
+	}
+	limit, err := ParseSize(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("validator: invalid file_max_size %q", fl.Param()))
+	}
+	return file.Size <= limit
+}
+
+func validateFileMIME(fl validator.FieldLevel) bool {
+	file, ok := fileHeader(fl.Field())
+	if !ok {
+		return false
+	}
+	detected, err := DetectFileType(file)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range strings.Fields(fl.Param()) {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(detected, prefix+"/") {
+			return true
+		}
+		if detected == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func validateMaxFiles(fl validator.FieldLevel) bool {
+	limit, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		panic(fmt.Sprintf("validator: invalid max_files %q", fl.Param()))
+	}
+
+	field := fl.Field()
+	if field.Kind() != reflect.Slice {
+		return false
+	}
+	return field.Len() <= limit
+}
+
+// fileHeader returns the uploaded file of the field value.
+func fileHeader(field reflect.Value) (*multipart.FileHeader, bool) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil, false
+		}
+		field = field.Elem()
+	}
+	if field.Type() != fileHeaderType {
+		return nil, false
+	}
+	if field.CanAddr() {
+		return field.Addr().Interface().(*multipart.FileHeader), true
+	}
+	file := field.Interface().(multipart.FileHeader)
+	return &file, true
+}
+
+// DetectFileType returns the MIME type of the uploaded file sniffed from its content, without
+// parameters, ignoring the Content-Type sent by the client.
+func DetectFileType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	detected := http.DetectContentType(head[:n])
+	return strings.TrimSpace(strings.SplitN(detected, ";", 2)[0]), nil
+}
+
+// ParseSize parses a size such as 512, 512B, 64KB, 2MB or 1GB to bytes.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value = strings.TrimSpace(number)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return n * multiplier, nil
+}
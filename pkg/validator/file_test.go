@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type uploadStruct struct {
+	Avatar      *multipart.FileHeader   `validate:"required,file_max_size=1KB,file_mime=image/png image/jpeg"`
+	Attachments []*multipart.FileHeader `validate:"max_files=2,dive,file_mime=image/*"`
+}
+
+// newFiles returns the files of a multipart form with the given field, file name and content.
+func newFiles(t *testing.T, field string, contents ...[]byte) []*multipart.FileHeader {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for _, content := range contents {
+		// the client declared type must be ignored
+		part, err := w.CreateFormFile(field, "upload.png")
+		require.NoError(t, err)
+		_, _ = part.Write(content)
+	}
+	require.NoError(t, w.Close())
+
+	form, err := multipart.NewReader(body, w.Boundary()).ReadForm(1 << 20)
+	require.NoError(t, err)
+	return form.File[field]
+}
+
+func TestFileValidations(t *testing.T) {
+	v, _ := NewValidator()
+
+	valid := uploadStruct{
+		Avatar:      newFiles(t, "avatar", pngHeader)[0],
+		Attachments: newFiles(t, "attachments", pngHeader, []byte("GIF89a")),
+	}
+	assert.NoError(t, v.ValidateStruct(valid))
+
+	invalid := uploadStruct{
+		Avatar:      newFiles(t, "avatar", []byte("<html><body>not an image</body></html>"))[0],
+		Attachments: newFiles(t, "attachments", pngHeader, pngHeader, pngHeader),
+	}
+	errs := v.TranslateError(v.ValidateStruct(invalid))
+	require.Len(t, errs, 2)
+	assert.Equal(t, "Avatar must be a file of type image/png image/jpeg", errs[0].Message)
+	assert.Equal(t, "Attachments must contain at most 2 files", errs[1].Message)
+
+	tooLarge := uploadStruct{Avatar: newFiles(t, "avatar", append(pngHeader, make([]byte, 1024)...))[0]}
+	errs = v.TranslateError(v.ValidateStruct(tooLarge))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Avatar must not be larger than 1KB", errs[0].Message)
+
+	// custom tags are translated too
+	errs = v.TranslateToLocale(v.ValidateStruct(tooLarge), "id")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Avatar tidak boleh lebih besar dari 1KB", errs[0].Message)
+
+	errs = v.TranslateError(v.ValidateStruct(uploadStruct{}))
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Avatar is a required field", errs[0].Message)
+}
+
+func TestParseSize(t *testing.T) {
+	for size, expected := range map[string]int64{"512": 512, "512B": 512, "64KB": 64 << 10, "2MB": 2 << 20, "1gb": 1 << 30} {
+		actual, err := ParseSize(size)
+		assert.NoError(t, err, size)
+		assert.Equal(t, expected, actual, size)
+	}
+
+	for _, size := range []string{"", "MB", "-1KB", "2TB"} {
+		_, err := ParseSize(size)
+		assert.Error(t, err, size)
+	}
+}
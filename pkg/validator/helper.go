@@ -1,11 +1,15 @@
 package validator
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"reflect"
 
 	"github.com/Alwanly/go-codebase/pkg/contract"
 	"github.com/Alwanly/go-codebase/pkg/logger"
 	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
@@ -18,17 +22,43 @@ func (e *ModelValidationError) Error() string {
 	return "Failed to validate request body"
 }
 
+func (e *ModelValidationError) ErrorResponse() wrapper.JSONResult {
+	return e.ResponseBody
+}
+
 func ValidateModel(log *zap.Logger, v IValidatorService, m interface{}) error {
+	return ValidateModelWithLocale(log, v, m, "")
+}
+
+// ValidateModelWithLocale validates the model like ValidateModel, with the messages translated to
+// the locale. Unknown or empty locales use the default locale of the validator.
+func ValidateModelWithLocale(log *zap.Logger, v IValidatorService, m interface{}, locale string) error {
+	return ValidateModelContext(context.Background(), log, v, m, locale)
+}
+
+// ValidateModelContext validates the model like ValidateModelWithLocale, with the context given to
+// the context-aware rules. An error reported by a rule, such as a failed database query, is
+// returned as is.
+func ValidateModelContext(ctx context.Context, log *zap.Logger, v IValidatorService, m interface{}, locale string) error {
 	// create local logger
 	l := logger.WithID(log, ContextName, "ValidateModel")
 
 	// try validate model
-	if err := v.ValidateStruct(m); err != nil {
+	if err := v.ValidateStructCtx(ctx, m); err != nil {
+		var validationErrs validator.ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			l.Error("Failed to run the validation rules", zap.Error(err))
+			return err
+		}
+
 		// log error
 		l.Error(contract.ErrorValidatePayload, zap.Error(err))
 
-		// translate error
-		localizedErr := v.TranslateError(err)
+		// translate error, with the names of the fields in the parameters such as gtfield=From
+		if _, ok := fieldNamesFromContext(ctx); !ok {
+			ctx = WithFieldNames(ctx, FieldNames(reflect.TypeOf(m), nil))
+		}
+		localizedErr := v.TranslateToLocaleContext(ctx, err, locale)
 
 		// return error
 		result := wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeValidationFailed, contract.ErrorValidatePayload, localizedErr)
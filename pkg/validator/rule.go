@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// Rule is a custom validation tag with its messages.
+type Rule struct {
+	// Tag is the name of the rule in the validate tag, e.g. slug.
+	Tag string
+	// Fn validates a field. Set either Fn or FnCtx.
+	Fn validator.Func
+	// FnCtx validates a field with the context given to ValidateStructCtx, e.g. to query the
+	// database. Failures of the dependency are reported with ReportRuleError.
+	FnCtx validator.FuncCtx
+	// CallEvenIfNull calls the rule for nil and zero values too.
+	CallEvenIfNull bool
+	// DefaultParam replaces an empty rule parameter in the messages.
+	DefaultParam string
+	// Messages are the messages per locale, {0} is the field and {1} the rule parameter. Locales
+	// without a message use the English one.
+	Messages map[string]string
+}
+
+type ruleErrorsKey struct{}
+
+// ruleErrors collects the errors reported by the rules during a validation.
+type ruleErrors struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (s *Service) RegisterRules(rules ...Rule) error {
+	for _, rule := range rules {
+		if rule.Tag == "" || (rule.Fn == nil) == (rule.FnCtx == nil) {
+			return errors.New("validator: a rule needs a tag and either Fn or FnCtx")
+		}
+
+		fn := rule.FnCtx
+		if fn == nil {
+			fn = func(_ context.Context, fl validator.FieldLevel) bool { return rule.Fn(fl) }
+		}
+		if err := s.Validate.RegisterValidationCtx(rule.Tag, fn, rule.CallEvenIfNull); err != nil {
+			return err
+		}
+
+		for locale, trans := range s.TranslateLanguage {
+			message, ok := rule.Messages[locale]
+			if !ok {
+				message = rule.Messages[i18n.LocaleEnglish]
+			}
+			if message == "" {
+				continue
+			}
+			if err := s.Validate.RegisterTranslation(rule.Tag, trans, registerTranslation(rule.Tag, message), translateWithParam(rule.Tag, rule.DefaultParam)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ReportRuleError records the failure of a dependency queried by a rule, such as the database. The
+// rule should then return true: ValidateStructCtx returns the error instead of a validation error.
+func ReportRuleError(ctx context.Context, err error) {
+	if holder, ok := ctx.Value(ruleErrorsKey{}).(*ruleErrors); ok {
+		holder.mu.Lock()
+		holder.errs = append(holder.errs, err)
+		holder.mu.Unlock()
+	}
+}
+
+// withRuleErrors returns a copy of ctx collecting the errors reported by the rules.
+func withRuleErrors(ctx context.Context) (context.Context, *ruleErrors) {
+	holder := &ruleErrors{}
+	return context.WithValue(ctx, ruleErrorsKey{}, holder), holder
+}
+
+func (r *ruleErrors) err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return errors.Join(r.errs...)
+}
+
+func registerTranslation(tag string, translation string) validator.RegisterTranslationsFunc {
+	return func(ut ut.Translator) error {
+		return ut.Add(tag, translation, true)
+	}
+}
+
+func translateWithParam(tag string, defaultParam string) validator.TranslationFunc {
+	return func(ut ut.Translator, fe validator.FieldError) string {
+		param := fe.Param()
+		if param == "" {
+			param = defaultParam
+		}
+		t, err := ut.T(tag, fe.Field(), param)
+		if err != nil {
+			return fe.Error()
+		}
+		return t
+	}
+}
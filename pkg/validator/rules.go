@@ -0,0 +1,138 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const defaultPasswordLength = 8
+
+var (
+	uuid7Regex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-7[0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`)
+	slugRegex  = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// builtinRules are the rules registered by NewValidator, next to the ones of the validator such as
+// isbn, isbn10, isbn13, e164, eqfield and gtfield:
+//
+//   - uuid7: a version 7 UUID
+//   - slug: lowercase letters and digits separated by single hyphens, e.g. clean-code-2nd-edition
+//   - strong_password=12: at least 12 characters (default 8) with upper and lower case letters, a digit and a symbol
+//   - after_field=From, before_field=To: a time after or before another field, both time.Time or RFC 3339 strings
+func builtinRules() []Rule {
+	return []Rule{
+		{Tag: "uuid7", Fn: validateRegex(uuid7Regex), Messages: map[string]string{
+			"en": "{0} must be a valid UUIDv7",
+			"id": "{0} harus berupa UUIDv7 yang valid",
+		}},
+		{Tag: "slug", Fn: validateRegex(slugRegex), Messages: map[string]string{
+			"en": "{0} must contain only lowercase letters, digits and single hyphens",
+			"id": "{0} hanya boleh berisi huruf kecil, angka dan tanda hubung tunggal",
+		}},
+		{Tag: "strong_password", Fn: validateStrongPassword, DefaultParam: strconv.Itoa(defaultPasswordLength), Messages: map[string]string{
+			"en": "{0} must be at least {1} characters long with upper and lower case letters, a digit and a symbol",
+			"id": "{0} harus memiliki paling sedikit {1} karakter dengan huruf besar dan kecil, angka dan simbol",
+		}},
+		{Tag: "after_field", Fn: validateTimeField(func(value, other time.Time) bool { return value.After(other) }), Messages: map[string]string{
+			"en": "{0} must be after {1}",
+			"id": "{0} harus setelah {1}",
+		}},
+		{Tag: "before_field", Fn: validateTimeField(func(value, other time.Time) bool { return value.Before(other) }), Messages: map[string]string{
+			"en": "{0} must be before {1}",
+			"id": "{0} harus sebelum {1}",
+		}},
+	}
+}
+
+// indonesianMessages are the Indonesian messages of the validator rules missing from its
+// Indonesian translations.
+var indonesianMessages = map[string]string{
+	"e164": "{0} harus berupa nomor telepon format E.164 yang valid",
+}
+
+func validateRegex(regex *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return fl.Field().Kind() == reflect.String && regex.MatchString(fl.Field().String())
+	}
+}
+
+func validateStrongPassword(fl validator.FieldLevel) bool {
+	length := defaultPasswordLength
+	if fl.Param() != "" {
+		n, err := strconv.Atoi(fl.Param())
+		if err != nil {
+			panic(fmt.Sprintf("validator: invalid strong_password %q", fl.Param()))
+		}
+		length = n
+	}
+	if fl.Field().Kind() != reflect.String {
+		return false
+	}
+
+	password := []rune(fl.Field().String())
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	return len(password) >= length && upper && lower && digit && symbol
+}
+
+// validateTimeField compares a time with the sibling field named by the parameter. Empty values
+// are valid, required checks them.
+func validateTimeField(compare func(value, other time.Time) bool) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		value, ok := timeValue(fl.Field())
+		if !ok {
+			return false
+		}
+		otherField, _, _, found := fl.GetStructFieldOK2()
+		if !found {
+			panic(fmt.Sprintf("validator: unknown field %q in %s", fl.Param(), fl.GetTag()))
+		}
+		other, ok := timeValue(otherField)
+		if !ok || value.IsZero() || other.IsZero() {
+			return ok
+		}
+		return compare(value, other)
+	}
+}
+
+// timeValue returns the time of a time.Time or RFC 3339 string value, the zero time for empty
+// values, and false for values that are not times.
+func timeValue(v reflect.Value) (time.Time, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return time.Time{}, true
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return v.Interface().(time.Time), true
+	case v.Kind() == reflect.String:
+		if v.String() == "" {
+			return time.Time{}, true
+		}
+		t, err := time.Parse(time.RFC3339, v.String())
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
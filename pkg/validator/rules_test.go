@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/database"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	v, _ := NewValidator()
+
+	tests := []struct {
+		tag     string
+		valid   []interface{}
+		invalid []interface{}
+	}{
+		{"uuid7", []interface{}{"01890a5d-ac96-774b-bcce-b302099a8057", "01890A5D-AC96-774B-BCCE-B302099A8057"}, []interface{}{"9b2f1c1e-5a4b-4c3d-8e7f-0a1b2c3d4e5f", "01890a5d-ac96-774b-7cce-b302099a8057", "not-a-uuid", 7}},
+		{"slug", []interface{}{"clean-code", "go-1-23"}, []interface{}{"Clean-Code", "clean--code", "-clean", "clean code", ""}},
+		{"strong_password", []interface{}{"Secr3t!pw", "Ünïcode-9x"}, []interface{}{"Secr3t!", "secret!pw1", "SECRET!PW1", "Secretpw1", "Secret!pw"}},
+		{"strong_password=12", []interface{}{"Secr3t!pw-long"}, []interface{}{"Secr3t!pw"}},
+		{"isbn", []interface{}{"0306406152", "9780306406157"}, []interface{}{"0306406153", "978-0-306"}},
+		{"e164", []interface{}{"+6281234567890"}, []interface{}{"081234567890", "+0123"}},
+	}
+	for _, tt := range tests {
+		for _, value := range tt.valid {
+			assert.NoError(t, v.(*Service).Validate.Var(value, tt.tag), "%s %v", tt.tag, value)
+		}
+		for _, value := range tt.invalid {
+			assert.Error(t, v.(*Service).Validate.Var(value, tt.tag), "%s %v", tt.tag, value)
+		}
+	}
+}
+
+func TestBuiltinRules_Messages(t *testing.T) {
+	v, _ := NewValidator()
+
+	type account struct {
+		Slug     string `validate:"slug"`
+		Password string `validate:"strong_password"`
+		Phone    string `validate:"e164"`
+	}
+	err := v.ValidateStruct(account{Slug: "Bad Slug", Password: "secret", Phone: "0812"})
+
+	errs := v.TranslateToLocale(err, "en")
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Slug must contain only lowercase letters, digits and single hyphens", errs[0].Message)
+	assert.Equal(t, "Password must be at least 8 characters long with upper and lower case letters, a digit and a symbol", errs[1].Message)
+	assert.Equal(t, "Phone must be a valid E.164 formatted phone number", errs[2].Message)
+
+	errs = v.TranslateToLocale(err, "id")
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Password harus memiliki paling sedikit 8 karakter dengan huruf besar dan kecil, angka dan simbol", errs[1].Message)
+	assert.Equal(t, "Phone harus berupa nomor telepon format E.164 yang valid", errs[2].Message)
+}
+
+func TestCrossFieldRules(t *testing.T) {
+	v, _ := NewValidator()
+
+	type period struct {
+		From    *string   `validate:"omitempty,before_field=To"`
+		To      *string   `validate:"omitempty,after_field=From"`
+		StartAt time.Time `validate:"required"`
+		EndAt   time.Time `validate:"after_field=StartAt"`
+	}
+	from, to := "2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z"
+	now := time.Now()
+
+	assert.NoError(t, v.ValidateStruct(period{From: &from, To: &to, StartAt: now, EndAt: now.Add(time.Hour)}))
+	assert.NoError(t, v.ValidateStruct(period{From: &from, StartAt: now}))
+
+	err := v.ValidateStruct(period{From: &to, To: &from, StartAt: now, EndAt: now.Add(-time.Hour)})
+	errs := v.TranslateToLocale(err, "en")
+	require.Len(t, errs, 3)
+	assert.Equal(t, "From must be before To", errs[0].Message)
+	assert.Equal(t, "To must be after From", errs[1].Message)
+	assert.Equal(t, "EndAt must be after StartAt", errs[2].Message)
+}
+
+func TestRegisterRules(t *testing.T) {
+	v, _ := NewValidator()
+
+	require.NoError(t, v.RegisterRules(Rule{
+		Tag: "even",
+		Fn: func(fl validator.FieldLevel) bool {
+			return fl.Field().Int()%2 == 0
+		},
+		Messages: map[string]string{"en": "{0} must be even"},
+	}))
+	assert.Error(t, v.RegisterRules(Rule{Tag: "empty"}))
+
+	type numbers struct {
+		Count int `validate:"even"`
+	}
+	assert.NoError(t, v.ValidateStruct(numbers{Count: 2}))
+
+	// locales without a message use the English one
+	errs := v.TranslateToLocale(v.ValidateStruct(numbers{Count: 3}), "id")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Count must be even", errs[0].Message)
+}
+
+// newCountingDB returns a dry-run database answering the count queries with count, or err, and
+// recording their SQL.
+func newCountingDB(t *testing.T, count int64, err error) (*database.DBService, *[]string) {
+	t.Helper()
+
+	db, openErr := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	require.NoError(t, openErr)
+
+	queries := []string{}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count", func(tx *gorm.DB) {
+		queries = append(queries, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		if err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+		// Count reads the scanned count, or RowsAffected when it is not 1
+		if dest, ok := tx.Statement.Dest.(*int64); ok {
+			*dest = count
+		}
+		tx.RowsAffected = count
+	}))
+	return &database.DBService{Gorm: db}, &queries
+}
+
+func TestDatabaseRules(t *testing.T) {
+	type book struct {
+		ID       string `validate:"required"`
+		Title    string `validate:"db_unique=books.title ID"`
+		AuthorID string `validate:"db_exists=users.id"`
+	}
+
+	db, queries := newCountingDB(t, 1, nil)
+	v, _ := NewValidator()
+	require.NoError(t, v.RegisterRules(DatabaseRules(db)...))
+
+	err := v.ValidateStructCtx(context.Background(), book{ID: "42", Title: "Dune", AuthorID: "7"})
+	errs := v.TranslateToLocale(err, "en")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Title is already taken", errs[0].Message)
+	assert.Equal(t, []string{
+		`SELECT count(*) FROM "books" WHERE "title" = 'Dune' AND "id" <> '42'`,
+		`SELECT count(*) FROM "users" WHERE "id" = '7'`,
+	}, *queries)
+
+	db, _ = newCountingDB(t, 0, nil)
+	v, _ = NewValidator()
+	require.NoError(t, v.RegisterRules(DatabaseRules(db)...))
+	errs = v.TranslateToLocale(v.ValidateStructCtx(context.Background(), book{ID: "42", Title: "Dune", AuthorID: "7"}), "id")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "AuthorID tidak ditemukan", errs[0].Message)
+
+	// a failing query is not a validation error
+	queryErr := errors.New("connection refused")
+	db, _ = newCountingDB(t, 0, queryErr)
+	v, _ = NewValidator()
+	require.NoError(t, v.RegisterRules(DatabaseRules(db)...))
+	err = v.ValidateStructCtx(context.Background(), book{ID: "42", Title: "Dune", AuthorID: "7"})
+	assert.ErrorIs(t, err, queryErr)
+	assert.Nil(t, v.TranslateError(err))
+}
+
+func TestDatabaseRules_KeepBuiltInUnique(t *testing.T) {
+	type tags struct {
+		Names []string `validate:"unique"`
+	}
+
+	db, queries := newCountingDB(t, 1, nil)
+	v, _ := NewValidator()
+	require.NoError(t, v.RegisterRules(DatabaseRules(db)...))
+
+	// the built-in rule checks the elements without querying the database
+	assert.NoError(t, v.ValidateStructCtx(context.Background(), tags{Names: []string{"go", "sql"}}))
+	err := v.ValidateStructCtx(context.Background(), tags{Names: []string{"go", "go"}})
+	errs := v.TranslateError(err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "Names", errs[0].Field)
+	assert.Empty(t, *queries)
+}
@@ -1,12 +1,16 @@
 package validator
 
 import (
+	"context"
+
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 )
 
 const ContextName = "Validator"
 
+// ValidationError describes a field failing validation. Field is its path in the request, e.g.
+// items[2].title, and Value is omitted for secrets such as passwords.
 type ValidationError struct {
 	Field   string      `json:"field"`
 	Value   interface{} `json:"value"`
@@ -32,6 +36,26 @@ type IValidatorService interface {
 	//    - error if any with all the validation errors
 	ValidateStruct(input interface{}) error
 
+	// Validates the contents of a struct with the context given to the context-aware rules, such as
+	// the database rules
+	//
+	// Parameters:
+	//    - ctx: context, with the transaction queried by the database rules
+	//    - input: struct to be validated
+	//
+	// Returns:
+	//    - error if any with all the validation errors, or the error reported by a rule
+	ValidateStructCtx(ctx context.Context, input interface{}) error
+
+	// Registers custom validation rules with their messages
+	//
+	// Parameters:
+	//    - rules: rules to register, replacing the rules with the same tag
+	//
+	// Returns:
+	//    - error if a rule is invalid
+	RegisterRules(rules ...Rule) error
+
 	// Translates validation errors into human readable messages
 	//
 	// Parameters:
@@ -45,9 +69,30 @@ type IValidatorService interface {
 	//
 	// Parameters:
 	//    - err: validation error
-	//		- locale: locale code
+	//    - locale: locale code or Accept-Language header, negotiated with NegotiateLocale
 	//
 	// Returns:
 	//    - array of ValidationError
 	TranslateToLocale(err error, locale string) []ValidationError
+
+	// Translates validation errors into locale specified, naming the fields with the names recorded
+	// in ctx by WithFieldNames
+	//
+	// Parameters:
+	//    - ctx: context, carrying the names of the fields of the request
+	//    - err: validation error
+	//    - locale: locale code or Accept-Language header, negotiated with NegotiateLocale
+	//
+	// Returns:
+	//    - array of ValidationError
+	TranslateToLocaleContext(ctx context.Context, err error, locale string) []ValidationError
+
+	// Returns the supported locale best matching a locale code or an Accept-Language header
+	//
+	// Parameters:
+	//    - acceptLanguage: locale code or Accept-Language header, e.g. id-ID,en;q=0.8
+	//
+	// Returns:
+	//    - the supported locale, or the default locale when none matches
+	NegotiateLocale(acceptLanguage string) string
 }
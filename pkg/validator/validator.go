@@ -1,38 +1,74 @@
 package validator
 
 import (
+	"context"
+	"sort"
+
+	"github.com/Alwanly/go-codebase/pkg/i18n"
 	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
 func NewValidator() (IValidatorService, error) {
 	// create validator
 	v := validator.New()
 
-	// register custom validators
+	// name the fields as in the request, e.g. page_size rather than PageSize
+	v.RegisterTagNameFunc(fieldName)
 
-	// register english translator
+	// register english and indonesian translators
 	english := en.New()
-	uni := ut.New(english, english)
-	trans, _ := uni.GetTranslator("en")
-
-	// register english translator
-	_ = en_translations.RegisterDefaultTranslations(v, trans)
+	uni := ut.New(english, english, id.New())
+	trans, _ := uni.GetTranslator(i18n.LocaleEnglish)
+	indonesian, _ := uni.GetTranslator(i18n.LocaleIndonesian)
+	if err := en_translations.RegisterDefaultTranslations(v, trans); err != nil {
+		return nil, err
+	}
+	if err := id_translations.RegisterDefaultTranslations(v, indonesian); err != nil {
+		return nil, err
+	}
+	for tag, message := range indonesianMessages {
+		if err := v.RegisterTranslation(tag, indonesian, registerTranslation(tag, message), translateWithParam(tag, "")); err != nil {
+			return nil, err
+		}
+	}
 
-	return &Service{
+	s := &Service{
 		Validate:      v,
 		Translator:    trans,
-		DefaultLocale: "en",
+		DefaultLocale: i18n.DefaultLocale,
 		TranslateLanguage: map[string]ut.Translator{
-			"en": trans,
+			i18n.LocaleEnglish:    trans,
+			i18n.LocaleIndonesian: indonesian,
 		},
-	}, nil
+	}
+
+	// register custom validators
+	if err := s.RegisterRules(builtinRules()...); err != nil {
+		return nil, err
+	}
+	if err := s.RegisterRules(fileRules()...); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
 func (s *Service) ValidateStruct(input interface{}) error {
-	return s.Validate.Struct(input)
+	return s.ValidateStructCtx(context.Background(), input)
+}
+
+func (s *Service) ValidateStructCtx(ctx context.Context, input interface{}) error {
+	ctx, ruleErrs := withRuleErrors(ctx)
+	err := s.Validate.StructCtx(ctx, input)
+	if ruleErr := ruleErrs.err(); ruleErr != nil {
+		return ruleErr
+	}
+	return err
 }
 
 func (s *Service) TranslateError(err error) []ValidationError {
@@ -50,17 +86,17 @@ func (s *Service) TranslateError(err error) []ValidationError {
 	// translate each error
 	errors := []ValidationError{}
 	for _, e := range validatorErrs {
-		errors = append(errors, ValidationError{
-			Field:   e.Field(),
-			Value:   e.Value(),
-			Message: e.Translate(s.Translator),
-		})
+		errors = append(errors, newValidationError(e, s.Translator, nil))
 	}
 
 	return errors
 }
 
 func (s *Service) TranslateToLocale(err error, locale string) []ValidationError {
+	return s.TranslateToLocaleContext(context.Background(), err, locale)
+}
+
+func (s *Service) TranslateToLocaleContext(ctx context.Context, err error, locale string) []ValidationError {
 	// check if err is nil
 	if err == nil {
 		return nil
@@ -72,20 +108,29 @@ func (s *Service) TranslateToLocale(err error, locale string) []ValidationError
 		return nil
 	}
 
-	_, ok = s.TranslateLanguage[locale]
-	if !ok {
-		locale = s.DefaultLocale
-	}
-
 	// translate each error
+	trans := s.TranslateLanguage[s.NegotiateLocale(locale)]
+	names, _ := fieldNamesFromContext(ctx)
 	errors := []ValidationError{}
 	for _, e := range validatorErrs {
-		errors = append(errors, ValidationError{
-			Field:   e.Field(),
-			Value:   e.Value(),
-			Message: e.Translate(s.TranslateLanguage[locale]),
-		})
+		errors = append(errors, newValidationError(e, trans, names))
 	}
 
 	return errors
 }
+
+func (s *Service) NegotiateLocale(acceptLanguage string) string {
+	if _, ok := s.TranslateLanguage[acceptLanguage]; ok {
+		return acceptLanguage
+	}
+
+	locales := make([]string, 0, len(s.TranslateLanguage))
+	for locale := range s.TranslateLanguage {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	if locale := i18n.Negotiate(acceptLanguage, locales); locale != "" {
+		return locale
+	}
+	return s.DefaultLocale
+}
@@ -1,9 +1,15 @@
 package validator
 
 import (
+	"context"
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 )
 
 type TestStruct struct {
@@ -66,3 +72,114 @@ func TestTranslateToLocale(t *testing.T) {
 	assert.Equal(t, "Name", translatedErrors[0].Field)
 	assert.Equal(t, "Email", translatedErrors[1].Field)
 }
+
+func TestTranslateToLocale_Indonesian(t *testing.T) {
+	v, _ := NewValidator()
+
+	err := v.ValidateStruct(TestStruct{Name: "", Email: "invalid-email"})
+	assert.Error(t, err)
+
+	// locale codes and Accept-Language headers are negotiated
+	for _, locale := range []string{"id", "id-ID", "fr, id;q=0.5"} {
+		translatedErrors := v.TranslateToLocale(err, locale)
+		assert.Len(t, translatedErrors, 2)
+		assert.Equal(t, "Name wajib diisi", translatedErrors[0].Message, locale)
+	}
+
+	// unsupported locales use the default locale
+	translatedErrors := v.TranslateToLocale(err, "fr")
+	assert.Equal(t, "Name is a required field", translatedErrors[0].Message)
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	v, _ := NewValidator()
+
+	for accept, expected := range map[string]string{"": "en", "id": "id", "id-ID,en;q=0.8": "id", "fr": "en", "en-GB": "en"} {
+		assert.Equal(t, expected, v.NegotiateLocale(accept), accept)
+	}
+}
+
+func TestTranslateError_FieldNames(t *testing.T) {
+	v, _ := NewValidator()
+
+	type item struct {
+		Title string `json:"title" validate:"required"`
+	}
+	type request struct {
+		ID       string `params:"id" validate:"numeric"`
+		PageSize int    `query:"page_size" validate:"max=100"`
+		Password string `json:"password" validate:"min=8"`
+		Items    []item `json:"items" validate:"dive"`
+		Note     string `json:"-" validate:"max=3"`
+	}
+	err := v.ValidateStruct(request{
+		ID:       "abc",
+		PageSize: 500,
+		Password: "short",
+		Items:    []item{{Title: "Dune"}, {Title: "Emma"}, {}},
+		Note:     "too long",
+	})
+
+	translatedErrors := v.TranslateError(err)
+	assert.Len(t, translatedErrors, 5)
+	assert.Equal(t, ValidationError{Field: "id", Value: "abc", Message: "id must be a valid numeric value"}, translatedErrors[0])
+	assert.Equal(t, ValidationError{Field: "page_size", Value: 500, Message: "page_size must be 100 or less"}, translatedErrors[1])
+	// secrets are not sent back
+	assert.Equal(t, ValidationError{Field: "password", Message: "password must be at least 8 characters in length"}, translatedErrors[2])
+	assert.Equal(t, ValidationError{Field: "items[2].title", Value: "", Message: "title is a required field"}, translatedErrors[3])
+	// fields without a name tag keep their Go name
+	assert.Equal(t, "Note", translatedErrors[4].Field)
+}
+
+func TestTranslateToLocaleContext_BoundFrom(t *testing.T) {
+	v, _ := NewValidator()
+
+	type request struct {
+		Name string `json:"name" query:"q" validate:"min=3"`
+	}
+	err := v.ValidateStruct(request{Name: "ab"})
+
+	// without the sources, the first name by priority
+	errs := v.TranslateError(err)
+	require.Len(t, errs, 1)
+	assert.Equal(t, ValidationError{Field: "name", Value: "ab", Message: "name must be at least 3 characters in length"}, errs[0])
+
+	names := FieldNames(reflect.TypeOf(request{}), func(names map[string]string) (string, bool) {
+		return "query", true
+	})
+	errs = v.TranslateToLocaleContext(WithFieldNames(context.Background(), names), err, "id")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "q", errs[0].Field)
+	assert.Equal(t, "panjang minimal q adalah 3 karakter", errs[0].Message)
+
+	// the validator errors only carry the plain names
+	var validationErrs validator.ValidationErrors
+	require.ErrorAs(t, err, &validationErrs)
+	assert.Equal(t, "name", validationErrs[0].Field())
+	assert.NotContains(t, err.Error(), "\x1f")
+}
+
+func TestValidateModelContext_FieldParams(t *testing.T) {
+	v, _ := NewValidator()
+
+	type period struct {
+		From time.Time `json:"from_date"`
+		To   time.Time `json:"to_date" validate:"after_field=From"`
+	}
+	type request struct {
+		Periods []period `json:"periods" validate:"dive"`
+		Min     int      `json:"min_price"`
+		Max     int      `json:"max_price" validate:"gtfield=Min"`
+	}
+	now := time.Now()
+	err := ValidateModelContext(context.Background(), zap.NewNop(), v, &request{Periods: []period{{From: now, To: now.Add(-time.Hour)}}, Min: 10, Max: 5}, "en")
+
+	var modelErr *ModelValidationError
+	require.ErrorAs(t, err, &modelErr)
+	errs := modelErr.ResponseBody.Data.([]ValidationError)
+	require.Len(t, errs, 2)
+	assert.Equal(t, "periods[0].to_date", errs[0].Field)
+	assert.Equal(t, "to_date must be after from_date", errs[0].Message)
+	assert.Equal(t, "max_price", errs[1].Field)
+	assert.Equal(t, "max_price must be greater than min_price", errs[1].Message)
+}
@@ -0,0 +1,59 @@
+package versioning
+
+import (
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	ContextName = "Components.Versioning"
+
+	// LocalVersionKey holds the resolved version of the request.
+	LocalVersionKey = "api_version"
+)
+
+// Opts represents the options for configuring the version registry.
+type Opts struct {
+	// Logger is the logger.
+	Logger *zap.Logger
+	// Metrics counts the requests to deprecated versions. Optional.
+	Metrics *metrics.Service
+	// Vendor is the vendor of the versioned media type application/vnd.{vendor}.{version}+json.
+	Vendor string
+}
+
+// Version describes a version of a resource.
+type Version struct {
+	// Name is the path segment of the version, for example v1.
+	Name string
+
+	// Deprecated marks the version as deprecated. DeprecatedAt is the date of the deprecation,
+	// sent in the Deprecation header when set.
+	Deprecated   bool
+	DeprecatedAt time.Time
+	// Sunset is the date the version stops being served, sent in the Sunset header when set.
+	Sunset time.Time
+	// Link is the documentation of the deprecation, for example the migration guide.
+	Link string
+}
+
+// Registry holds the versioned resources of the application.
+type Registry struct {
+	logger     *zap.Logger
+	vendor     string
+	deprecated *prometheus.CounterVec
+}
+
+// Resource is a resource served in several versions under a common path prefix.
+type Resource struct {
+	registry *Registry
+	router   fiber.Router
+	prefix   string
+	handlers []fiber.Handler
+	versions map[string]Version
+	order    []string
+}
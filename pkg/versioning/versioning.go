@@ -0,0 +1,175 @@
+package versioning
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/logger"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func NewRegistry(opts *Opts) *Registry {
+	r := &Registry{
+		logger: logger.WithID(opts.Logger, ContextName, "Registry"),
+		vendor: strings.ToLower(opts.Vendor),
+	}
+	if opts.Metrics != nil {
+		r.deprecated = opts.Metrics.Counter("api_deprecated_requests_total", "Total number of requests to deprecated API versions.", "resource", "version")
+	}
+	return r
+}
+
+// Resource creates a versioned resource under the path prefix, for example /books. Versions are
+// served under {prefix}/{version}; requests without a version in the path are routed by the
+// Accept header, or to the latest version that is not deprecated.
+//
+// Parameters:
+//   - router: the router the versions are registered on
+//   - prefix: the path prefix of the resource
+//   - handlers: the middlewares of every version, for example authentication
+//
+// Returns:
+//   - *Resource: the resource to register versions on
+func (r *Registry) Resource(router fiber.Router, prefix string, handlers ...fiber.Handler) *Resource {
+	res := &Resource{
+		registry: r,
+		router:   router,
+		prefix:   strings.TrimSuffix(prefix, "/"),
+		handlers: handlers,
+		versions: map[string]Version{},
+	}
+
+	// resolve the version before the versioned routes are matched
+	router.Use(res.prefix, res.resolve)
+	return res
+}
+
+// Version registers the handlers of a version. The register function receives the router of
+// {prefix}/{version}.
+func (res *Resource) Version(v Version, register func(router fiber.Router)) *Resource {
+	res.versions[v.Name] = v
+	res.order = append(res.order, v.Name)
+
+	handlers := append([]fiber.Handler{res.versionMiddleware(v)}, res.handlers...)
+	register(res.router.Group(res.prefix+"/"+v.Name, handlers...))
+
+	res.registry.logger.Debug("API version registered",
+		zap.String("resource", res.prefix), zap.String("version", v.Name), zap.Bool("deprecated", v.Deprecated))
+	return res
+}
+
+// resolve rewrites unversioned paths to the requested or default version.
+func (res *Resource) resolve(c *fiber.Ctx) error {
+	base := c.Route().Path
+	rest := strings.TrimPrefix(c.Path(), base)
+	if rest != "" && !strings.HasPrefix(rest, "/") {
+		// another path sharing the prefix, for example /bookshelf for /books
+		return c.Next()
+	}
+
+	// the path already selects a version
+	segment := strings.SplitN(strings.TrimPrefix(rest, "/"), "/", 2)[0]
+	if _, ok := res.versions[segment]; ok {
+		return c.Next()
+	}
+
+	version, requested := res.registry.acceptVersion(c.Get(fiber.HeaderAccept))
+	if requested {
+		if _, ok := res.versions[version]; !ok {
+			return wrapper.Send(c, wrapper.ResponseFailed(http.StatusNotAcceptable, contract.StatusCodeUnsupportedVersion, contract.ErrorUnsupportedVersion, nil))
+		}
+	} else {
+		version = res.defaultVersion()
+	}
+	if version == "" {
+		return c.Next()
+	}
+
+	// later routes are matched against the rewritten path
+	c.Path(base + "/" + version + rest)
+	return c.Next()
+}
+
+// defaultVersion returns the latest version that is not deprecated, or the latest version.
+func (res *Resource) defaultVersion() string {
+	for i := len(res.order) - 1; i >= 0; i-- {
+		if !res.versions[res.order[i]].Deprecated {
+			return res.order[i]
+		}
+	}
+	if len(res.order) > 0 {
+		return res.order[len(res.order)-1]
+	}
+	return ""
+}
+
+func (res *Resource) versionMiddleware(v Version) fiber.Handler {
+	headers := [][2]string{}
+	if v.Deprecated {
+		deprecation := "true"
+		if !v.DeprecatedAt.IsZero() {
+			deprecation = "@" + strconv.FormatInt(v.DeprecatedAt.Unix(), 10)
+		}
+		headers = append(headers, [2]string{"Deprecation", deprecation})
+		if !v.Sunset.IsZero() {
+			headers = append(headers, [2]string{"Sunset", v.Sunset.UTC().Format(http.TimeFormat)})
+		}
+		if v.Link != "" {
+			headers = append(headers, [2]string{fiber.HeaderLink, "<" + v.Link + `>; rel="deprecation"; type="text/html"`})
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		c.Locals(LocalVersionKey, v.Name)
+		for _, header := range headers {
+			c.Set(header[0], header[1])
+		}
+		if v.Deprecated && res.registry.deprecated != nil {
+			res.registry.deprecated.WithLabelValues(res.prefix, v.Name).Inc()
+		}
+		return c.Next()
+	}
+}
+
+// acceptVersion returns the version requested by the Accept header, either as the vendor media
+// type application/vnd.{vendor}.v2+json or as a version parameter, application/json; version=2.
+func (r *Registry) acceptVersion(accept string) (string, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		if version, ok := params["version"]; ok && version != "" {
+			return normalizeVersion(version), true
+		}
+
+		vendorPrefix := "application/vnd." + r.vendor + "."
+		if r.vendor != "" && strings.HasPrefix(mediaType, vendorPrefix) {
+			version := strings.TrimSuffix(strings.TrimPrefix(mediaType, vendorPrefix), "+json")
+			if version != "" {
+				return normalizeVersion(version), true
+			}
+		}
+	}
+	return "", false
+}
+
+func normalizeVersion(version string) string {
+	version = strings.ToLower(version)
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return version
+}
+
+// FromContext returns the version resolved for the request.
+func FromContext(c *fiber.Ctx) string {
+	version, _ := c.Locals(LocalVersionKey).(string)
+	return version
+}
@@ -0,0 +1,96 @@
+package versioning_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Alwanly/go-codebase/pkg/metrics"
+	"github.com/Alwanly/go-codebase/pkg/versioning"
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func newVersionedApp(m *metrics.Service) *fiber.App {
+	app := fiber.New()
+	registry := versioning.NewRegistry(&versioning.Opts{Logger: zap.NewNop(), Metrics: m, Vendor: "codebase"})
+
+	books := registry.Resource(app, "/books")
+	books.Version(versioning.Version{
+		Name:         "v1",
+		Deprecated:   true,
+		DeprecatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Sunset:       time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC),
+		Link:         "https://docs.example.com/books/v2-migration",
+	}, func(r fiber.Router) {
+		r.Get("/:id", func(c *fiber.Ctx) error { return c.SendString("v1 " + c.Params("id")) })
+	})
+	books.Version(versioning.Version{Name: "v2"}, func(r fiber.Router) {
+		r.Get("/:id", func(c *fiber.Ctx) error { return c.SendString(versioning.FromContext(c) + " " + c.Params("id")) })
+	})
+	app.Get("/bookshelf", func(c *fiber.Ctx) error { return c.SendString("bookshelf") })
+	return app
+}
+
+func get(t *testing.T, app *fiber.App, path string, accept string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		req.Header.Set(fiber.HeaderAccept, accept)
+	}
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+func TestResource_Resolution(t *testing.T) {
+	app := newVersionedApp(nil)
+	tests := []struct {
+		name   string
+		path   string
+		accept string
+		body   string
+	}{
+		{"path v1", "/books/v1/1", "", "v1 1"},
+		{"path v2", "/books/v2/1", "", "v2 1"},
+		{"path wins over accept", "/books/v1/1", "application/vnd.codebase.v2+json", "v1 1"},
+		{"vendor media type", "/books/1", "application/vnd.codebase.v1+json", "v1 1"},
+		{"version parameter", "/books/1", "application/json; version=1", "v1 1"},
+		{"default is latest not deprecated", "/books/1", "application/json", "v2 1"},
+		{"unrelated prefix", "/bookshelf", "", "bookshelf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, body := get(t, app, tt.path, tt.accept)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+
+	resp, _ := get(t, app, "/books/1", "application/vnd.codebase.v9+json")
+	assert.Equal(t, http.StatusNotAcceptable, resp.StatusCode)
+}
+
+func TestResource_Deprecation(t *testing.T) {
+	m := metrics.NewMetrics(&metrics.Opts{Logger: zap.NewNop(), Namespace: "test"})
+	app := newVersionedApp(m)
+
+	resp, _ := get(t, app, "/books/v1/1", "")
+	assert.Equal(t, "@1767225600", resp.Header.Get("Deprecation"))
+	assert.Equal(t, "Thu, 31 Dec 2026 00:00:00 GMT", resp.Header.Get("Sunset"))
+	assert.Equal(t, `<https://docs.example.com/books/v2-migration>; rel="deprecation"; type="text/html"`, resp.Header.Get(fiber.HeaderLink))
+
+	resp, _ = get(t, app, "/books/v2/1", "")
+	assert.Empty(t, resp.Header.Get("Deprecation"))
+	assert.Empty(t, resp.Header.Get("Sunset"))
+
+	get(t, app, "/books/1", "application/vnd.codebase.v1+json")
+	deprecated := m.Counter("api_deprecated_requests_total", "Total number of requests to deprecated API versions.", "resource", "version")
+	assert.Equal(t, float64(2), testutil.ToFloat64(deprecated.WithLabelValues("/books", "v1")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(deprecated.WithLabelValues("/books", "v2")))
+}
@@ -0,0 +1,73 @@
+package wrapper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrorResponder is implemented by errors that already carry their response, such as
+// binding.ModelBindingError and validator.ModelValidationError.
+type ErrorResponder interface {
+	ErrorResponse() JSONResult
+}
+
+// ResponseFromError maps an error to its response. It is the central mapping used by the
+// Fiber error handler, so handlers and usecases can return errors instead of building responses.
+func ResponseFromError(err error) JSONResult {
+	var responder ErrorResponder
+	if errors.As(err, &responder) {
+		return responder.ErrorResponse()
+	}
+
+	// a deadline is the root cause even when a domain error wraps it
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ResponseFailed(http.StatusGatewayTimeout, contract.StatusCodeRequestTimeout, contract.ErrorRequestTimeout, nil)
+	}
+
+	// an unavailable dependency is the root cause even when a domain error wraps it
+	if appErr, ok := apperror.AsKind(err, apperror.KindUnavailable); ok {
+		return ResponseFailed(appErr.HTTPStatus(), appErr.Code, appErr.Message, appErr.Data)
+	}
+
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return ResponseFailed(appErr.HTTPStatus(), appErr.Code, appErr.Message, appErr.Data)
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return ResponseFailed(fiberErr.Code, statusCodeFromHTTP(fiberErr.Code), fiberErr.Message, nil)
+	}
+
+	return ResponseFailed(http.StatusInternalServerError, contract.StatusCodeInternalServerError, contract.ErrorInternalServer, nil)
+}
+
+// statusCodeFromHTTP returns the contract status code of errors raised by Fiber itself.
+func statusCodeFromHTTP(code int) contract.StatusCode {
+	switch code {
+	case http.StatusNotFound:
+		return contract.StatusCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return contract.StatusCodeMethodNotAllowed
+	case http.StatusUnauthorized:
+		return contract.StatusCodeUnauthorized
+	case http.StatusForbidden:
+		return contract.StatusCodeForbidden
+	case http.StatusConflict:
+		return contract.StatusCodeConflict
+	case http.StatusServiceUnavailable:
+		return contract.StatusCodeServiceUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return contract.StatusCodeRequestTimeout
+	}
+
+	if code >= http.StatusInternalServerError {
+		return contract.StatusCodeInternalServerError
+	}
+	return contract.StatusCodeRequestFailed
+}
@@ -0,0 +1,54 @@
+package wrapper_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/apperror"
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type responderError struct{}
+
+func (responderError) Error() string { return "responder" }
+
+func (responderError) ErrorResponse() wrapper.JSONResult {
+	return wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeBindingFailed, contract.ErrorValidatePayload, nil)
+}
+
+func TestResponseFromError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		code       int
+		statusCode contract.StatusCode
+	}{
+		{"not found", apperror.NotFound(contract.CreateStatusCode("0004"), "Book not found"), http.StatusNotFound, contract.CreateStatusCode("0004")},
+		{"conflict", apperror.Conflict(contract.StatusCodeConflict, contract.ErrorConflict), http.StatusConflict, contract.StatusCodeConflict},
+		{"forbidden", apperror.Forbidden(contract.StatusCodeForbidden, contract.ErrorInsufficientPrivilege), http.StatusForbidden, contract.StatusCodeForbidden},
+		{"validation", apperror.Validation(contract.StatusCodeValidationFailed, contract.ErrorValidatePayload), http.StatusBadRequest, contract.StatusCodeValidationFailed},
+		{"unavailable", apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable), http.StatusServiceUnavailable, contract.StatusCodeServiceUnavailable},
+		{"wrapped domain error", fmt.Errorf("usecase: %w", apperror.NotFound(contract.StatusCodeNotFound, contract.ErrorNotFound)), http.StatusNotFound, contract.StatusCodeNotFound},
+		{"wrapped unavailable", apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(apperror.Unavailable(contract.StatusCodeServiceUnavailable, contract.ErrorServiceUnavailable)), http.StatusServiceUnavailable, contract.StatusCodeServiceUnavailable},
+		{"responder", responderError{}, http.StatusBadRequest, contract.StatusCodeBindingFailed},
+		{"deadline", apperror.Internal(contract.StatusCodeInternalServerError, contract.ErrorFailedToFindRecord).Wrap(context.DeadlineExceeded), http.StatusGatewayTimeout, contract.StatusCodeRequestTimeout},
+		{"fiber not found", fiber.ErrNotFound, http.StatusNotFound, contract.StatusCodeNotFound},
+		{"fiber method not allowed", fiber.ErrMethodNotAllowed, http.StatusMethodNotAllowed, contract.StatusCodeMethodNotAllowed},
+		{"fiber bad request", fiber.ErrBadRequest, http.StatusBadRequest, contract.StatusCodeRequestFailed},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, contract.StatusCodeInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := wrapper.ResponseFromError(tt.err)
+			assert.Equal(t, tt.code, result.Code)
+			assert.Equal(t, tt.statusCode, result.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package wrapper
+
+import (
+	"net/http"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/i18n"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MIMEProblemJSON is the media type of RFC 7807 problem details documents.
+const MIMEProblemJSON = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem details document. Code, Errors and ReferenceID are
+// extension members carrying the contract status code, the binding or validation errors and the
+// reference ID of a crash report.
+type ProblemDetails struct {
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	Status      int                 `json:"status"`
+	Detail      string              `json:"detail,omitempty"`
+	Instance    string              `json:"instance,omitempty"`
+	Code        contract.StatusCode `json:"code"`
+	Errors      interface{}         `json:"errors,omitempty"`
+	ReferenceID string              `json:"referenceId,omitempty"`
+}
+
+// ReferenceData is the data of a failed result answering a crash, it carries the reference ID
+// of the crash report.
+type ReferenceData struct {
+	ReferenceID string `json:"referenceId"`
+}
+
+// ResponseProblem converts a failed result to a problem details document.
+//
+// Parameters:
+//   - result: the failed result
+//   - instance: URI reference of the occurrence, usually the request path
+//
+// Returns:
+//   - ProblemDetails: the problem details document
+func ResponseProblem(result JSONResult, instance string) ProblemDetails {
+	problem := ProblemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(result.Code),
+		Status:   result.Code,
+		Detail:   result.Message,
+		Instance: instance,
+		Code:     result.StatusCode,
+	}
+
+	// clients read errors as field errors, other data is not one
+	switch data := result.Data.(type) {
+	case ReferenceData:
+		problem.ReferenceID = data.ReferenceID
+	default:
+		if result.StatusCode == contract.StatusCodeBindingFailed || result.StatusCode == contract.StatusCodeValidationFailed {
+			problem.Errors = result.Data
+		}
+	}
+	return problem
+}
+
+// AcceptsProblem reports whether the client prefers application/problem+json over application/json.
+func AcceptsProblem(c *fiber.Ctx) bool {
+	return c.Accepts(fiber.MIMEApplicationJSON, MIMEProblemJSON) == MIMEProblemJSON
+}
+
+// Send writes the result with its HTTP status. Failed results have their message translated to the
+// locale of the request, and are written as problem details when the client asks for
+// application/problem+json, otherwise as the JSONResult envelope.
+func Send(c *fiber.Ctx, result JSONResult) error {
+	c.Status(result.Code)
+	if result.Code < http.StatusBadRequest {
+		return c.JSON(result)
+	}
+
+	// the message depends on the locale and the format on Accept
+	c.Vary(fiber.HeaderAcceptLanguage, fiber.HeaderAccept)
+	result.Message = i18n.Translate(i18n.Locale(c), result.Message)
+	if AcceptsProblem(c) {
+		return c.JSON(ResponseProblem(result, c.OriginalURL()), MIMEProblemJSON)
+	}
+	return c.JSON(result)
+}
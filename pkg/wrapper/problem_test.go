@@ -0,0 +1,108 @@
+package wrapper_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Alwanly/go-codebase/pkg/contract"
+	"github.com/Alwanly/go-codebase/pkg/validator"
+	"github.com/Alwanly/go-codebase/pkg/wrapper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProblemApp() *fiber.App {
+	app := fiber.New()
+	app.Post("/books", func(c *fiber.Ctx) error {
+		errs := []validator.ValidationError{{Field: "Title", Value: "", Message: "Title is a required field"}}
+		return wrapper.Send(c, wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeValidationFailed, contract.ErrorValidatePayload, errs))
+	})
+	app.Get("/books", func(c *fiber.Ctx) error {
+		return wrapper.Send(c, wrapper.ResponseSuccess(http.StatusOK, []string{}))
+	})
+	return app
+}
+
+func TestSend_ProblemDetails(t *testing.T) {
+	app := newProblemApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/books?draft=true", nil)
+	req.Header.Set("Accept", wrapper.MIMEProblemJSON)
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, wrapper.MIMEProblemJSON, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "Accept-Language, Accept", resp.Header.Get("Vary"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "Failed to validate payload",
+		"instance": "/books?draft=true",
+		"code": "000002",
+		"errors": [{"field": "Title", "value": "", "message": "Title is a required field"}]
+	}`, string(body))
+}
+
+func TestSend_DefaultEnvelope(t *testing.T) {
+	app := newProblemApp()
+
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, application/problem+json;q=0.5"} {
+		req := httptest.NewRequest(http.MethodPost, "/books", nil)
+		req.Header.Set("Accept", accept)
+		resp, _ := app.Test(req)
+		body, _ := io.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, accept)
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get("Content-Type"), accept)
+		assert.Equal(t, "Accept-Language, Accept", resp.Header.Get("Vary"), accept)
+		assert.Contains(t, string(body), `"statusCode":"000002"`, accept)
+	}
+
+	// successful results never use problem details
+	req := httptest.NewRequest(http.MethodGet, "/books", nil)
+	req.Header.Set("Accept", wrapper.MIMEProblemJSON)
+	resp, _ := app.Test(req)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get("Content-Type"))
+}
+
+func TestSend_TranslatesFailedMessages(t *testing.T) {
+	app := newProblemApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/books", nil)
+	req.Header.Set("Accept", wrapper.MIMEProblemJSON)
+	req.Header.Set("Accept-Language", "id-ID,en;q=0.8")
+	resp, _ := app.Test(req)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(t, string(body), `"detail":"Gagal memvalidasi data"`)
+	assert.Equal(t, "Accept-Language, Accept", resp.Header.Get("Vary"))
+
+	// successful results keep their message
+	req = httptest.NewRequest(http.MethodGet, "/books", nil)
+	req.Header.Set("Accept-Language", "id")
+	resp, _ = app.Test(req)
+	body, _ = io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), `"message":"Success"`)
+}
+
+func TestResponseProblem_ErrorsOnlyForFieldErrors(t *testing.T) {
+	problem := wrapper.ResponseProblem(wrapper.ResponseFailed(http.StatusInternalServerError, contract.StatusCodeInternalServerError,
+		contract.ErrorInternalServer, wrapper.ReferenceData{ReferenceID: "ref-1"}), "/books")
+	assert.Nil(t, problem.Errors)
+	assert.Equal(t, "ref-1", problem.ReferenceID)
+
+	problem = wrapper.ResponseProblem(wrapper.ResponseFailed(http.StatusConflict, contract.StatusCodeConflict,
+		"Book already exists", fiber.Map{"id": "book-1"}), "/books")
+	assert.Nil(t, problem.Errors)
+	assert.Empty(t, problem.ReferenceID)
+
+	errs := []validator.ValidationError{{Field: "title", Message: "title is a required field"}}
+	problem = wrapper.ResponseProblem(wrapper.ResponseFailed(http.StatusBadRequest, contract.StatusCodeBindingFailed,
+		contract.ErrorValidatePayload, errs), "/books")
+	assert.Equal(t, errs, problem.Errors)
+}